@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	"github.com/Quisharoo/gh-pr-comments/internal/tui"
+)
+
+// loadConfig merges the config layers for the current repository and applies
+// the settings that live in package state: bot patterns, colours, keys and the editor.
+func loadConfig(errOut io.Writer) (*ghprcomments.Config, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	repoRoot, _ := ghprcomments.FindRepoRoot(ctx)
+
+	cfg, err := ghprcomments.LoadConfig(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintf(errOut, "warning: %s\n", warning)
+	}
+	if err := ghprcomments.SetBotPatterns(cfg.BotInclude, cfg.BotExclude); err != nil {
+		return nil, fmt.Errorf("config bots: %w", err)
+	}
+	if err := ghprcomments.SetColourPalette(cfg.Colors); err != nil {
+		return nil, fmt.Errorf("config colors: %w", err)
+	}
+	if err := tui.ApplyKeyBindings(cfg.Keybindings); err != nil {
+		return nil, fmt.Errorf("config keybindings: %w", err)
+	}
+	tui.SetEditorCommand(cfg.Editor)
+	return cfg, nil
+}
+
+// flagsSet reports which flags were given explicitly on the command line.
+func flagsSet(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// runConfig prints the effective configuration and the layer each value came from.
+func runConfig(args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(errOut)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "Config files (later layers win; env and flags override both):")
+	for _, file := range cfg.Files {
+		status := "not found"
+		if file.Exists {
+			status = "loaded"
+		}
+		fmt.Fprintf(out, "  %s: %s (%s)\n", file.Layer, file.Path, status)
+	}
+	fmt.Fprintln(out)
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, key := range cfg.Keys() {
+		value := cfg.Value(key)
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", key, value, cfg.Source(key))
+	}
+	return tw.Flush()
+}
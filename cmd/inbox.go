@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	"github.com/Quisharoo/gh-pr-comments/internal/tui"
+)
+
+// runInbox collects feedback from other people across every open PR the
+// authenticated user has authored.
+func runInbox(args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("inbox", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+
+	var allRepos bool
+	var text bool
+	var stripHTML bool
+	var stripLevelFlag string
+	var unresolved bool
+	var noInteractive bool
+	var noCache bool
+
+	fs.BoolVar(&allRepos, "all-repos", false, "search every repository instead of the ones detected locally")
+	fs.BoolVar(&text, "text", false, "render the inbox as Markdown")
+	fs.BoolVar(&stripHTML, "strip-html", false, "strip HTML tags from comment bodies")
+	fs.StringVar(&stripLevelFlag, "strip-level", "plain", "body_text normalisation: raw, markdown or plain")
+	fs.BoolVar(&unresolved, "unresolved", false, "hide review comments on threads that have been resolved")
+	fs.BoolVar(&noInteractive, "no-interactive", false, "disable interactive TUI (for piping/scripting)")
+	fs.BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP cache")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(errOut)
+	if err != nil {
+		return err
+	}
+	if !flagsSet(fs)["strip-level"] {
+		stripLevelFlag = cfg.StripLevel
+	}
+	stripLevel, err := ghprcomments.ParseStripLevel(stripLevelFlag)
+	if err != nil {
+		return err
+	}
+	if text {
+		stripHTML = true
+	}
+	useInteractive := !noInteractive && !text && isTerminalWriter(out)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	fetcher, rateLimits, err := newTrackedFetcher(ctx, noCache, useInteractive, errOut)
+	if err != nil {
+		return err
+	}
+	if !useInteractive {
+		defer reportQuota(errOut, rateLimits)
+	}
+
+	login, err := fetcher.AuthenticatedLogin(ctx)
+	if err != nil {
+		return fmt.Errorf("identify user: %w", err)
+	}
+
+	prs, err := listUserPullRequests(ctx, fetcher, ghprcomments.PullRequestFilter{Author: login}, allRepos, cfg.DiscoveryDepth, errOut)
+	if err != nil {
+		return err
+	}
+
+	inbox, err := fetcher.FetchInbox(ctx, login, prs, ghprcomments.InboxOptions{
+		Normalization: ghprcomments.NormalizationOptions{
+			StripHTML:      stripHTML,
+			Level:          stripLevel,
+			UnresolvedOnly: unresolved,
+			IgnoredAuthors: cfg.IgnoredAuthors,
+		},
+		Concurrency: rateLimits.Concurrency(4),
+	})
+	if err != nil {
+		return fmt.Errorf("build inbox: %w", err)
+	}
+	for _, warning := range inbox.Warnings {
+		fmt.Fprintf(errOut, "warning: %s\n", warning)
+	}
+
+	if text {
+		_, err := io.WriteString(out, ghprcomments.RenderInboxMarkdown(inbox))
+		return err
+	}
+
+	payload, err := json.MarshalIndent(inbox, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON: %w", err)
+	}
+	if useInteractive {
+		if _, err := tui.RunUnifiedFlow(nil, payload); err != nil {
+			return fmt.Errorf("explore JSON: %w", err)
+		}
+		return nil
+	}
+	if _, err := out.Write(append(payload, '\n')); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
+	}
+	return nil
+}
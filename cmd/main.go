@@ -6,11 +6,14 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
@@ -28,97 +31,272 @@ func main() {
 
 func run(args []string, in io.Reader, out, errOut io.Writer) error {
 	args = normalizeArgs(args)
+	if len(args) > 0 {
+		switch args[0] {
+		case "apply-suggestions":
+			return runApplySuggestions(args[1:], out, errOut)
+		case "inbox":
+			return runInbox(args[1:], out, errOut)
+		case "reviewer":
+			return runReviewer(args[1:], out, errOut)
+		case "config":
+			return runConfig(args[1:], out, errOut)
+		case "resolve":
+			return runResolve(args[1:], out, errOut)
+		case "review":
+			return runReview(args[1:], in, out, errOut)
+		case "stats":
+			return runStats(args[1:], out, errOut)
+		}
+	}
+
 	fs := flag.NewFlagSet("gh-pr-comments", flag.ContinueOnError)
 	fs.SetOutput(errOut)
 
 	var prNumber int
 	var flat bool
+	var threads bool
+	var groupByFlag string
+	var unresolved bool
 	var text bool
+	var format string
+	var templateFlag string
 	var save bool
+	var checklist string
 	var stripHTML bool
+	var stripLevelFlag string
 	var noColour bool
 	var noColor bool
 	var saveDir string
 	var noInteractive bool
+	var watch bool
+	var watchInterval time.Duration
+	var noCache bool
+	var clearCache bool
+	var stateFlag string
+	var updatedSinceFlag string
+	var labels stringList
+	var excludeBots bool
+	var onlyBots bool
+	var collapseBots stringList
+	var dedupeBots bool
+	var timeline bool
+	var filter ghprcomments.PullRequestFilter
 
 	fs.IntVar(&prNumber, "p", 0, "pull request number")
 	fs.IntVar(&prNumber, "pr", 0, "pull request number")
 	fs.BoolVar(&flat, "flat", false, "emit a single JSON array of comments")
+	fs.BoolVar(&threads, "threads", false, "group comments into conversation threads with replies nested under their root")
+	fs.StringVar(&groupByFlag, "group-by", "author", "nest comments by author, file, type, thread or none (one chronological list)")
+	fs.BoolVar(&unresolved, "unresolved", false, "hide review comments on threads that have been resolved")
 	fs.BoolVar(&text, "text", false, "render comments as Markdown")
+	fs.StringVar(&templateFlag, "template", "", "render comments with a Go text/template file or built-in template ("+strings.Join(ghprcomments.TemplateNames(), ", ")+")")
+	fs.StringVar(&format, "format", "", "output format: "+strings.Join(ghprcomments.FormatterNames(), ", ")+" (default json)")
 	fs.BoolVar(&save, "save", false, "persist output (defaults to .pr-comments/; override via --save-dir or GH_PR_COMMENTS_SAVE_DIR)")
 	fs.BoolVar(&stripHTML, "strip-html", false, "strip HTML tags from comment bodies")
+	fs.StringVar(&stripLevelFlag, "strip-level", "plain", "body_text normalisation: raw, markdown or plain")
 	fs.BoolVar(&noColour, "no-colour", false, "disable coloured terminal output")
 	fs.BoolVar(&noColor, "no-color", false, "disable colored terminal output")
 	fs.StringVar(&saveDir, "save-dir", "", "override directory used by --save")
+	fs.StringVar(&checklist, "checklist", "", "write actionable feedback to this Markdown task list, keeping boxes ticked on earlier runs")
+	fs.BoolVar(&excludeBots, "exclude-bots", false, "drop comments written by bots")
+	fs.BoolVar(&onlyBots, "only-bots", false, "keep only comments written by bots")
+	fs.Var(&collapseBots, "collapse-bots", "fold each bot's comments into one entry: latest or summary, or login=mode for one bot (repeatable)")
+	fs.BoolVar(&dedupeBots, "dedupe-bots", false, "keep only the latest of near-identical bot comments, with an occurrence count")
+	fs.BoolVar(&timeline, "timeline", false, "interleave commits, force-pushes, review requests, label changes and state changes with comments")
 	fs.BoolVar(&noInteractive, "no-interactive", false, "disable interactive TUI (for piping/scripting)")
+	fs.BoolVar(&watch, "watch", false, "poll the pull request and stream new or edited comments (requires --pr)")
+	fs.DurationVar(&watchInterval, "interval", 30*time.Second, "polling interval used by --watch")
+	fs.BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP cache (also GH_PR_COMMENTS_NO_CACHE)")
+	fs.BoolVar(&clearCache, "clear-cache", false, "delete the on-disk HTTP cache and exit")
+	fs.StringVar(&stateFlag, "state", "open", "list pull requests that are open, closed, merged or all")
+	fs.StringVar(&filter.Author, "author", "", "list pull requests opened by this user (@me for yourself)")
+	fs.StringVar(&filter.ReviewRequested, "review-requested", "", "list pull requests awaiting review from this user or team (@me for yourself)")
+	fs.StringVar(&filter.Assignee, "assignee", "", "list pull requests assigned to this user (@me for yourself)")
+	fs.Var(&labels, "label", "list pull requests carrying this label (repeatable)")
+	fs.StringVar(&filter.Base, "base", "", "list pull requests targeting this base branch")
+	fs.StringVar(&updatedSinceFlag, "updated-since", "", "list pull requests updated since a date (2006-01-02) or age (72h, 14d, 2w)")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	if clearCache {
+		dir, err := resolveCacheDir()
+		if err != nil {
+			return fmt.Errorf("locate cache: %w", err)
+		}
+		if err := ghprcomments.ClearCache(dir); err != nil {
+			return fmt.Errorf("clear cache: %w", err)
+		}
+		fmt.Fprintf(out, "Cleared HTTP cache at %s\n", dir)
+		return nil
+	}
+
+	cfg, err := loadConfig(errOut)
+	if err != nil {
+		return err
+	}
+	explicit := flagsSet(fs)
+	if !explicit["format"] && !flat && !text && templateFlag == "" {
+		format = cfg.Format
+	}
+	if !explicit["strip-level"] {
+		stripLevelFlag = cfg.StripLevel
+	}
+	if !explicit["save-dir"] {
+		saveDir = cfg.SaveDir
+	}
+
 	if flat && text {
 		return errors.New("cannot use --flat together with --text")
 	}
-
-	if text {
-		stripHTML = true
+	if templateFlag != "" && (format != "" || flat || text) {
+		return errors.New("--template cannot be combined with --format, --flat or --text")
+	}
+	format, err = resolveFormat(format, flat, text)
+	if err != nil {
+		return err
+	}
+	var tmpl *template.Template
+	if templateFlag != "" {
+		if tmpl, err = ghprcomments.LoadTemplate(templateFlag); err != nil {
+			return err
+		}
+		format = "template"
+	}
+	flat = format == "flat"
+	text = format == "markdown"
+	jsonFormat := format == "json" || format == "flat"
+	if save && tmpl != nil {
+		return errors.New("--template cannot be combined with --save")
+	}
+	if watch {
+		switch {
+		case prNumber <= 0:
+			return errors.New("--watch requires --pr")
+		case save || text:
+			return errors.New("--watch cannot be combined with --save or --text")
+		case checklist != "":
+			return errors.New("--watch cannot be combined with --checklist")
+		case tmpl != nil:
+			return errors.New("--watch cannot be combined with --template")
+		case !jsonFormat:
+			return fmt.Errorf("--watch cannot be combined with --format %s", format)
+		case watchInterval <= 0:
+			return errors.New("--interval must be positive")
+		}
 	}
 
-	// Determine if we should use interactive mode
-	// Interactive is default unless:
-	// - --no-interactive is set
-	// - --save is set (saving is non-interactive)
-	// - --text is set (markdown output is non-interactive)
-	// - stdout is not a TTY (piping)
-	useInteractive := !noInteractive && !save && !text && isTerminalWriter(out)
+	stripLevel, err := ghprcomments.ParseStripLevel(stripLevelFlag)
+	if err != nil {
+		return err
+	}
 
-	if noColor {
-		noColour = true
+	if excludeBots && onlyBots {
+		return errors.New("cannot use --exclude-bots together with --only-bots")
 	}
-	if envNoColor := strings.TrimSpace(os.Getenv("NO_COLOR")); envNoColor != "" {
-		noColour = true
+	botCollapse, err := ghprcomments.ParseBotCollapse(collapseBots)
+	if err != nil {
+		return err
 	}
-
-	if saveDir == "" {
-		saveDir = strings.TrimSpace(os.Getenv("GH_PR_COMMENTS_SAVE_DIR"))
+	for login, mode := range cfg.BotCollapse {
+		if _, overridden := botCollapse[login]; !overridden {
+			botCollapse[login] = mode
+		}
 	}
 
-	colorEnabled := !noColour && isTerminalWriter(out)
+	groupBy, err := ghprcomments.ParseGroupBy(groupByFlag)
+	if err != nil {
+		return err
+	}
+	if threads {
+		if explicit["group-by"] && groupBy != ghprcomments.GroupByThread {
+			return fmt.Errorf("cannot use --group-by %s together with --threads", groupBy)
+		}
+		groupBy = ghprcomments.GroupByThread
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	// Markdown output reads badly with raw HTML in it.
+	if text {
+		stripHTML = true
+	}
 
-	host := os.Getenv("GH_HOST")
-	if host == "" {
-		host = "github.com"
+	normOpts := ghprcomments.NormalizationOptions{
+		StripHTML:      stripHTML,
+		Level:          stripLevel,
+		Threads:        groupBy == ghprcomments.GroupByThread,
+		UnresolvedOnly: unresolved,
+		IgnoredAuthors: cfg.IgnoredAuthors,
+		ExcludeBots:    excludeBots,
+		OnlyBots:       onlyBots,
+		BotCollapse:    botCollapse,
+		DedupeBots:     dedupeBots,
+		GroupBy:        groupBy,
 	}
 
-	token := os.Getenv("GH_TOKEN")
-	if token == "" {
-		token = os.Getenv("GITHUB_TOKEN")
+	if filter.State, err = ghprcomments.ParsePullRequestState(stateFlag); err != nil {
+		return err
+	}
+	if filter.UpdatedSince, err = ghprcomments.ParseUpdatedSince(updatedSinceFlag, time.Now()); err != nil {
+		return err
 	}
+	filter.Labels = labels
 
-	// If token not in environment, try to ask `gh` for the token (user already logged in
-	// with the GitHub CLI). This keeps UX smooth for users who authenticate via `gh`.
-	if token == "" {
-		if out, err := exec.CommandContext(ctx, "gh", "auth", "token").Output(); err == nil {
-			tok := strings.TrimSpace(string(out))
-			if tok != "" {
-				token = tok
-			}
-		}
+	// Determine if we should use interactive mode
+	// Interactive is default unless:
+	// - --no-interactive is set
+	// - --save or --checklist is set (writing files is non-interactive)
+	// - --text or a non-JSON --format is set (those outputs are non-interactive)
+	// - stdout is not a TTY (piping)
+	useInteractive := !noInteractive && !save && checklist == "" && jsonFormat && isTerminalWriter(out)
+
+	colorEnabled := isTerminalWriter(out)
+	switch {
+	case noColour || noColor || cfg.Color == "never":
+		colorEnabled = false
+	case cfg.Color == "always":
+		colorEnabled = true
 	}
 
-	if token == "" {
-		return errors.New("GH_TOKEN or GITHUB_TOKEN not set; run `gh auth login`")
+	var ctx context.Context
+	var cancel context.CancelFunc
+	rateLimits := ghprcomments.NewRateLimitTracker()
+	clientOpts := cachedClientOptions(noCache)
+	clientOpts.RateLimits = rateLimits
+	if !useInteractive {
+		// Backoff notices would corrupt the TUI; it shows quota in its status line instead.
+		clientOpts.RetryNotify = func(reason string, wait time.Duration) {
+			fmt.Fprintf(errOut, "warning: %s; retrying in %s\n", reason, wait.Round(time.Second))
+		}
+		defer reportQuota(errOut, rateLimits)
 	}
+	if watch {
+		// Watching runs until interrupted; each poll carries its own timeout.
+		ctx, cancel = signal.NotifyContext(context.Background(), os.Interrupt)
+		if clientOpts.CacheDir == "" {
+			clientOpts.Transport = ghprcomments.NewConditionalTransport(nil)
+		}
+	} else {
+		ctx, cancel = context.WithTimeout(context.Background(), 60*time.Second)
+	}
+	defer cancel()
 
-	client, err := ghprcomments.NewGitHubClient(ctx, token, host)
+	client, err := newGitHubClient(ctx, clientOpts)
 	if err != nil {
-		return fmt.Errorf("create GitHub client: %w", err)
+		return err
 	}
 
 	fetcher := ghprcomments.NewFetcher(client)
+	if timeline {
+		fetcher = fetcher.WithTimeline()
+	}
+	// Thread state costs a GraphQL request per pull request; only fetch it
+	// when filtering, grouping or the checklist use it, or the explorer can
+	// resolve threads.
+	if unresolved || normOpts.Threads || checklist != "" || useInteractive {
+		fetcher = fetcher.WithThreads()
+	}
 
 	var prSummary *ghprcomments.PullRequestSummary
 	var selectedRepo ghprcomments.Repository
@@ -144,7 +322,7 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 			if ctxToUse == nil {
 				ctxToUse = ctx
 			}
-			repos, reposErr = ghprcomments.DetectRepositories(ctxToUse)
+			repos, reposErr = ghprcomments.DetectRepositoriesWithDepth(ctxToUse, cfg.DiscoveryDepth)
 			if reposErr != nil {
 				return
 			}
@@ -166,44 +344,13 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 			return errors.New("no repositories found; run inside or alongside a git repository")
 		}
 
-		if len(repos) == 1 {
-			selectedRepo = repos[0]
-			prSummary, err = fetcher.GetPullRequestSummary(ctx, selectedRepo.Owner, selectedRepo.Name, prNumber)
-			if err != nil {
-				return fmt.Errorf("load pull request: %w", err)
-			}
-			prSummary.LocalPath = selectedRepo.Path
-		} else {
-			matches := make([]*ghprcomments.PullRequestSummary, 0)
-			var errs []string
-			for _, repo := range repos {
-				summary, berr := fetcher.GetPullRequestSummary(ctx, repo.Owner, repo.Name, prNumber)
-				if berr != nil {
-					var ghErr *github.ErrorResponse
-					if errors.As(berr, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == 404 {
-						continue
-					}
-					errs = append(errs, fmt.Sprintf("%s/%s: %v", repo.Owner, repo.Name, berr))
-					continue
-				}
-				summary.LocalPath = repo.Path
-				matches = append(matches, summary)
-			}
+		prSummary, selectedRepo, err = findPullRequest(ctx, fetcher, repos, prNumber)
+		if err != nil {
+			return err
+		}
 
-			if len(matches) == 0 {
-				if len(errs) > 0 {
-					return fmt.Errorf("load pull request #%d:\n%s", prNumber, strings.Join(errs, "\n"))
-				}
-				return fmt.Errorf("pull request #%d not found in discovered repositories", prNumber)
-			}
-			if len(matches) > 1 {
-				return fmt.Errorf("pull request #%d found in multiple repositories; re-run without --pr and select interactively", prNumber)
-			}
-			prSummary = matches[0]
-			selectedRepo = repoLookup[repoKey(prSummary.RepoOwner, prSummary.RepoName)]
-			if selectedRepo.Path == "" {
-				selectedRepo = ghprcomments.Repository{Owner: prSummary.RepoOwner, Name: prSummary.RepoName, Path: prSummary.LocalPath}
-			}
+		if watch {
+			return runWatch(ctx, fetcher, prSummary, normOpts, watchInterval, rateLimits, useInteractive, flat, saveDir, out, errOut)
 		}
 
 		// If interactive mode and PR was specified, fetch comments and launch JSON explorer directly
@@ -219,19 +366,20 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 			if err != nil {
 				return fmt.Errorf("fetch comments: %w", err)
 			}
-
-			normOpts := ghprcomments.NormalizationOptions{
-				StripHTML: stripHTML,
+			for _, warning := range payloads.Warnings() {
+				fmt.Fprintf(errOut, "warning: %s\n", warning)
 			}
 
 			output := ghprcomments.BuildOutput(prSummary, payloads, normOpts)
+			output = ghprcomments.WithDrafts(output, loadDrafts(prSummary, saveDir))
 			jsonData, err := ghprcomments.MarshalJSON(output, flat)
 			if err != nil {
 				return fmt.Errorf("marshal JSON: %w", err)
 			}
 
 			// Launch JSON explorer directly
-			_, err = tui.RunUnifiedFlow(nil, jsonData)
+			checkout := tui.Checkout{Dir: prSummary.LocalPath, HeadSHA: prSummary.HeadSHA}
+			err = tui.RunUnifiedFlowForPR(jsonData, checkout, ghprcomments.NewResponder(fetcher, owner, repo, prNumber))
 			if err != nil {
 				return fmt.Errorf("explore JSON: %w", err)
 			}
@@ -248,8 +396,13 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 				PRs:                nil, // Will be fetched inside TUI
 				Fetcher:            fetcher,
 				RepositoriesLoader: loadRepositories,
-				StripHTML:          stripHTML,
+				Filter:             filter,
+				Normalization:      normOpts,
 				Flat:               flat,
+				RateLimits:         rateLimits,
+				Drafts: func(pr *ghprcomments.PullRequestSummary) []ghprcomments.DraftComment {
+					return loadDrafts(pr, saveDir)
+				},
 			})
 			if err != nil {
 				return fmt.Errorf("interactive flow: %w", err)
@@ -275,7 +428,7 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 		all := make([]*ghprcomments.PullRequestSummary, 0)
 		var errs []string
 		for _, repo := range repos {
-			prs, berr := fetcher.ListPullRequestSummaries(ctx, repo.Owner, repo.Name)
+			prs, berr := fetcher.ListPullRequestSummariesWithFilter(ctx, repo.Owner, repo.Name, filter)
 			if berr != nil {
 				if errors.Is(berr, ghprcomments.ErrNoPullRequests) {
 					continue
@@ -298,7 +451,8 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 		var prunedFiles []string
 		var pruneAttempted bool
 		if len(all) == 0 {
-			if save && len(errs) == 0 {
+			// Pruning relies on the full open list, which a narrower filter does not provide.
+			if save && len(errs) == 0 && filter.IsDefault() {
 				pruneAttempted = true
 				prunedFiles = pruneSavedComments(ctx, fetcher, repos, saveDir, errOut)
 			}
@@ -314,6 +468,9 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 				}
 				return ghprcomments.ErrNoPullRequests
 			}
+			if !filter.IsDefault() {
+				return errors.New("no pull requests matching the filters found across discovered repositories")
+			}
 			return errors.New("no open pull requests found across discovered repositories")
 		}
 
@@ -327,6 +484,10 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 		if err != nil {
 			return fmt.Errorf("select pull request: %w", err)
 		}
+		// Filtered lists come from search; the branch names feed the save slug and metadata.
+		if err := fetcher.FillBranches(ctx, prSummary); err != nil {
+			return err
+		}
 		selectedRepo = repoLookup[repoKey(prSummary.RepoOwner, prSummary.RepoName)]
 		if selectedRepo.Path == "" {
 			selectedRepo = ghprcomments.Repository{Owner: prSummary.RepoOwner, Name: prSummary.RepoName, Path: prSummary.LocalPath}
@@ -349,13 +510,25 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 	if err != nil {
 		return fmt.Errorf("fetch comments: %w", err)
 	}
-
-	normOpts := ghprcomments.NormalizationOptions{
-		StripHTML: stripHTML,
+	for _, warning := range payloads.Warnings() {
+		fmt.Fprintf(errOut, "warning: %s\n", warning)
 	}
 
 	output := ghprcomments.BuildOutput(prSummary, payloads, normOpts)
 
+	if checklist != "" {
+		total, done, err := ghprcomments.UpdateChecklist(checklist, output)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "Checklist written to %s (%d item(s), %d done)\n", checklist, total, done); err != nil {
+			return fmt.Errorf("announce checklist path: %w", err)
+		}
+		if !save {
+			return nil
+		}
+	}
+
 	if save {
 		state := strings.ToLower(strings.TrimSpace(prSummary.State))
 		if state != "open" {
@@ -373,15 +546,11 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 				return fmt.Errorf("find repo root: %w", err)
 			}
 		}
-		payload, err := ghprcomments.MarshalJSON(output, flat)
-		if err != nil {
-			return fmt.Errorf("marshal JSON for save: %w", err)
-		}
-		savePath, err := ghprcomments.SaveOutput(repoRoot, prSummary, payload, saveDir)
+		savePath, summary, err := ghprcomments.SyncSavedOutput(repoRoot, prSummary, payloads, normOpts, flat, saveDir)
 		if err != nil {
 			return fmt.Errorf("save output: %w", err)
 		}
-		if _, err := fmt.Fprintf(out, "Comments saved to %s\n", savePath); err != nil {
+		if _, err := fmt.Fprintf(out, "Comments saved to %s (%s)\n", savePath, summary); err != nil {
 			return fmt.Errorf("announce save path: %w", err)
 		}
 
@@ -399,10 +568,17 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 		return nil
 	}
 
-	if text {
-		markup := ghprcomments.RenderMarkdown(output)
-		if _, err := fmt.Fprintln(out, markup); err != nil {
-			return fmt.Errorf("write markdown: %w", err)
+	if tmpl != nil {
+		if err := ghprcomments.RenderTemplate(out, tmpl, output); err != nil {
+			return err
+		}
+	} else if !jsonFormat {
+		formatter, err := ghprcomments.LookupFormatter(format)
+		if err != nil {
+			return err
+		}
+		if err := formatter.Format(out, output); err != nil {
+			return fmt.Errorf("write %s: %w", format, err)
 		}
 	} else {
 		payload, err := ghprcomments.MarshalJSON(output, flat)
@@ -410,15 +586,6 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 			return fmt.Errorf("marshal JSON: %w", err)
 		}
 
-		// Launch interactive JSON explorer by default when interactive mode is enabled
-		if useInteractive {
-			if err := tui.ExploreJSON(payload); err != nil {
-				return fmt.Errorf("explore JSON: %w", err)
-			}
-			return nil
-		}
-
-		// Non-interactive: output to stdout
 		display := payload
 		if colorEnabled {
 			display = ghprcomments.ColouriseJSONComments(colorEnabled, payload)
@@ -436,6 +603,220 @@ func run(args []string, in io.Reader, out, errOut io.Writer) error {
 	return nil
 }
 
+// resolveFormat reconciles --format with the older --flat and --text switches
+// and checks the result names a registered formatter.
+func resolveFormat(format string, flat, text bool) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	implied, legacy := "", ""
+	switch {
+	case flat:
+		implied, legacy = "flat", "--flat"
+	case text:
+		implied, legacy = "markdown", "--text"
+	}
+	if format == "" {
+		format = implied
+		if format == "" {
+			format = "json"
+		}
+	} else if implied != "" && implied != format {
+		return "", fmt.Errorf("cannot use --format %s together with %s", format, legacy)
+	}
+	if _, err := ghprcomments.LookupFormatter(format); err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
+// reportQuota ends a non-interactive run with the remaining quota on errOut,
+// as a warning when it runs low. Runs that made no API request print nothing.
+func reportQuota(errOut io.Writer, rateLimits *ghprcomments.RateLimitTracker) {
+	switch status := rateLimits.Status(); {
+	case status.Low():
+		fmt.Fprintf(errOut, "warning: running low on %s\n", status)
+	case status.Known:
+		fmt.Fprintln(errOut, status)
+	}
+}
+
+// newTrackedFetcher builds a fetcher whose client records the remaining quota.
+// Retry notices go to errOut unless a TUI owns the terminal.
+func newTrackedFetcher(ctx context.Context, noCache, interactive bool, errOut io.Writer) (*ghprcomments.Fetcher, *ghprcomments.RateLimitTracker, error) {
+	rateLimits := ghprcomments.NewRateLimitTracker()
+	clientOpts := cachedClientOptions(noCache)
+	clientOpts.RateLimits = rateLimits
+	if !interactive {
+		clientOpts.RetryNotify = func(reason string, wait time.Duration) {
+			fmt.Fprintf(errOut, "warning: %s; retrying in %s\n", reason, wait.Round(time.Second))
+		}
+	}
+	client, err := newGitHubClient(ctx, clientOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ghprcomments.NewFetcher(client), rateLimits, nil
+}
+
+// listUserPullRequests lists the PRs matching filter, either in the locally
+// detected repositories or, with allRepos, anywhere via search.
+func listUserPullRequests(ctx context.Context, fetcher *ghprcomments.Fetcher, filter ghprcomments.PullRequestFilter, allRepos bool, discoveryDepth int, errOut io.Writer) ([]*ghprcomments.PullRequestSummary, error) {
+	if allRepos {
+		prs, err := fetcher.SearchPullRequestSummaries(ctx, filter)
+		if err != nil && !errors.Is(err, ghprcomments.ErrNoPullRequests) {
+			return nil, fmt.Errorf("search pull requests: %w", err)
+		}
+		return prs, nil
+	}
+
+	repos, err := ghprcomments.DetectRepositoriesWithDepth(ctx, discoveryDepth)
+	if err != nil {
+		return nil, fmt.Errorf("detect repositories: %w", err)
+	}
+	if len(repos) == 0 {
+		return nil, errors.New("no repositories found; run inside or alongside a git repository, or use --all-repos")
+	}
+
+	var all []*ghprcomments.PullRequestSummary
+	for _, repo := range repos {
+		prs, err := fetcher.ListPullRequestSummariesWithFilter(ctx, repo.Owner, repo.Name, filter)
+		if err != nil {
+			var ghErr *github.ErrorResponse
+			switch {
+			case errors.Is(err, ghprcomments.ErrNoPullRequests):
+			case errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusNotFound:
+			default:
+				fmt.Fprintf(errOut, "warning: %s/%s: %v\n", repo.Owner, repo.Name, err)
+			}
+			continue
+		}
+		for _, pr := range prs {
+			pr.LocalPath = repo.Path
+		}
+		all = append(all, prs...)
+	}
+	return all, nil
+}
+
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	if value = strings.TrimSpace(value); value != "" {
+		*l = append(*l, value)
+	}
+	return nil
+}
+
+// cachedClientOptions enables the on-disk HTTP cache unless it has been
+// disabled. A missing cache directory silently falls back to uncached requests.
+func cachedClientOptions(noCache bool) ghprcomments.ClientOptions {
+	if noCache || strings.TrimSpace(os.Getenv("GH_PR_COMMENTS_NO_CACHE")) != "" {
+		return ghprcomments.ClientOptions{}
+	}
+	dir, err := resolveCacheDir()
+	if err != nil {
+		return ghprcomments.ClientOptions{}
+	}
+	return ghprcomments.ClientOptions{CacheDir: dir}
+}
+
+// resolveCacheDir honours GH_PR_COMMENTS_CACHE_DIR before the user cache directory.
+func resolveCacheDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("GH_PR_COMMENTS_CACHE_DIR")); dir != "" {
+		return dir, nil
+	}
+	return ghprcomments.DefaultCacheDir()
+}
+
+// githubTransport, when set, carries every API request; tests point it at a local server.
+var githubTransport http.RoundTripper
+
+// newGitHubClient authenticates against GH_HOST using GH_TOKEN, GITHUB_TOKEN or `gh auth token`.
+func newGitHubClient(ctx context.Context, opts ghprcomments.ClientOptions) (*github.Client, error) {
+	host := os.Getenv("GH_HOST")
+	if host == "" {
+		host = "github.com"
+	}
+
+	token := os.Getenv("GH_TOKEN")
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+
+	// If token not in environment, try to ask `gh` for the token (user already logged in
+	// with the GitHub CLI). This keeps UX smooth for users who authenticate via `gh`.
+	if token == "" {
+		if out, err := exec.CommandContext(ctx, "gh", "auth", "token").Output(); err == nil {
+			tok := strings.TrimSpace(string(out))
+			if tok != "" {
+				token = tok
+			}
+		}
+	}
+
+	if token == "" {
+		return nil, errors.New("GH_TOKEN or GITHUB_TOKEN not set; run `gh auth login`")
+	}
+
+	if opts.Transport == nil {
+		opts.Transport = githubTransport
+	}
+	client, err := ghprcomments.NewGitHubClientWithOptions(ctx, token, host, opts)
+	if err != nil {
+		return nil, fmt.Errorf("create GitHub client: %w", err)
+	}
+	return client, nil
+}
+
+// findPullRequest locates prNumber among the discovered repositories, failing when it is
+// missing or ambiguous.
+func findPullRequest(ctx context.Context, fetcher *ghprcomments.Fetcher, repos []ghprcomments.Repository, prNumber int) (*ghprcomments.PullRequestSummary, ghprcomments.Repository, error) {
+	if len(repos) == 1 {
+		repo := repos[0]
+		summary, err := fetcher.GetPullRequestSummary(ctx, repo.Owner, repo.Name, prNumber)
+		if err != nil {
+			return nil, repo, fmt.Errorf("load pull request: %w", err)
+		}
+		summary.LocalPath = repo.Path
+		return summary, repo, nil
+	}
+
+	type match struct {
+		summary *ghprcomments.PullRequestSummary
+		repo    ghprcomments.Repository
+	}
+	var matches []match
+	var errs []string
+	for _, repo := range repos {
+		summary, berr := fetcher.GetPullRequestSummary(ctx, repo.Owner, repo.Name, prNumber)
+		if berr != nil {
+			var ghErr *github.ErrorResponse
+			if errors.As(berr, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == 404 {
+				continue
+			}
+			errs = append(errs, fmt.Sprintf("%s/%s: %v", repo.Owner, repo.Name, berr))
+			continue
+		}
+		summary.LocalPath = repo.Path
+		matches = append(matches, match{summary: summary, repo: repo})
+	}
+
+	if len(matches) == 0 {
+		if len(errs) > 0 {
+			return nil, ghprcomments.Repository{}, fmt.Errorf("load pull request #%d:\n%s", prNumber, strings.Join(errs, "\n"))
+		}
+		return nil, ghprcomments.Repository{}, fmt.Errorf("pull request #%d not found in discovered repositories", prNumber)
+	}
+	if len(matches) > 1 {
+		return nil, ghprcomments.Repository{}, fmt.Errorf("pull request #%d found in multiple repositories; re-run without --pr and select interactively", prNumber)
+	}
+	return matches[0].summary, matches[0].repo, nil
+}
+
 func normalizeArgs(args []string) []string {
 	cleaned := args
 	for len(cleaned) > 0 {
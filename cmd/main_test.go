@@ -1,7 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -48,3 +56,261 @@ func TestNormalizeArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestRunWatchFlagValidation(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "requires pr", args: []string{"--watch"}, want: "--watch requires --pr"},
+		{name: "rejects save", args: []string{"--watch", "--pr", "1", "--save"}, want: "--watch cannot be combined with --save or --text"},
+		{name: "rejects text", args: []string{"--watch", "--pr", "1", "--text"}, want: "--watch cannot be combined with --save or --text"},
+		{name: "rejects checklist", args: []string{"--watch", "--pr", "1", "--checklist", "TODO.md"}, want: "--watch cannot be combined with --checklist"},
+		{name: "rejects zero interval", args: []string{"--watch", "--pr", "1", "--interval", "0s"}, want: "--interval must be positive"},
+		{name: "rejects template", args: []string{"--watch", "--pr", "1", "--template", "digest"}, want: "--watch cannot be combined with --template"},
+		{name: "rejects csv", args: []string{"--watch", "--pr", "1", "--format", "csv"}, want: "--watch cannot be combined with --format csv"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := run(tc.args, nil, io.Discard, io.Discard)
+			if err == nil || err.Error() != tc.want {
+				t.Fatalf("run(%v) error = %v, want %q", tc.args, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunGroupByValidation(t *testing.T) {
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--group-by", "reviewer"}, want: `unknown group-by "reviewer" (want author, file, type, thread or none)`},
+		{args: []string{"--threads", "--group-by", "file"}, want: "cannot use --group-by file together with --threads"},
+	}
+
+	for _, tc := range testCases {
+		err := run(tc.args, nil, io.Discard, io.Discard)
+		if err == nil || err.Error() != tc.want {
+			t.Fatalf("run(%v) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestRunResolveValidation(t *testing.T) {
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"resolve", "12"}, want: "resolve requires --pr"},
+		{args: []string{"resolve", "--pr", "1"}, want: "resolve needs a comment ID, permalink or path:line, or --author, --bots or --outdated"},
+		{args: []string{"resolve", "--pr", "1", "a.go:x"}, want: `invalid line in "a.go:x"`},
+	}
+
+	for _, tc := range testCases {
+		err := run(tc.args, nil, io.Discard, io.Discard)
+		if err == nil || err.Error() != tc.want {
+			t.Fatalf("run(%v) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestRunReviewValidation(t *testing.T) {
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"review"}, want: "review requires a subcommand: add, list, remove or submit"},
+		{args: []string{"review", "post"}, want: `unknown review subcommand "post" (want add, list, remove or submit)`},
+		{args: []string{"review", "add", "--pr", "1", "--path", "a.go"}, want: "review add requires --path and --line"},
+		{args: []string{"review", "add", "--pr", "1", "--path", "a.go", "--line", "3", "--side", "up"}, want: `unknown side "up" (want LEFT or RIGHT)`},
+		{args: []string{"review", "list"}, want: "review requires --pr"},
+		{args: []string{"review", "remove", "--pr", "1"}, want: "review remove needs draft IDs or --all"},
+		{args: []string{"review", "remove", "--pr", "1", "x"}, want: `invalid draft ID "x"`},
+		{args: []string{"review", "submit", "--pr", "1", "--event", "lgtm"}, want: `unknown review event "lgtm" (want approve, request-changes or comment)`},
+	}
+
+	for _, tc := range testCases {
+		err := run(tc.args, nil, io.Discard, io.Discard)
+		if err == nil || err.Error() != tc.want {
+			t.Fatalf("run(%v) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestRunStatsValidation(t *testing.T) {
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"stats", "--state", "stale"}, want: `invalid state "stale" (expected open, closed, merged or all)`},
+		{args: []string{"stats", "--updated-since", "soon"}, want: "updated-since"},
+	}
+
+	for _, tc := range testCases {
+		err := run(tc.args, nil, io.Discard, io.Discard)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("run(%v) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	testCases := []struct {
+		format  string
+		flat    bool
+		text    bool
+		want    string
+		wantErr string
+	}{
+		{want: "json"},
+		{flat: true, want: "flat"},
+		{text: true, want: "markdown"},
+		{format: "HTML", want: "html"},
+		{format: "flat", flat: true, want: "flat"},
+		{format: "csv", flat: true, wantErr: "cannot use --format csv together with --flat"},
+		{format: "json", text: true, wantErr: "cannot use --format json together with --text"},
+		{format: "xml", wantErr: `unknown format "xml"`},
+	}
+
+	for _, tc := range testCases {
+		got, err := resolveFormat(tc.format, tc.flat, tc.text)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("resolveFormat(%q, %v, %v) error = %v, want %q", tc.format, tc.flat, tc.text, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Fatalf("resolveFormat(%q, %v, %v) = %q, %v; want %q", tc.format, tc.flat, tc.text, got, err, tc.want)
+		}
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("format: ndjson\nignored_authors: [codecov]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GH_PR_COMMENTS_CONFIG", path)
+	t.Setenv("GH_PR_COMMENTS_SAVE_DIR", "notes")
+
+	var out strings.Builder
+	if err := run([]string{"config"}, nil, &out, io.Discard); err != nil {
+		t.Fatalf("run config: %v", err)
+	}
+	for _, want := range []string{
+		"user config: " + path + " (loaded)",
+		"format           ndjson",
+		"user config",
+		"save_dir         notes",
+		"env GH_PR_COMMENTS_SAVE_DIR",
+		"ignored_authors  codecov",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("config output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunClearCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "http")
+	if err := os.MkdirAll(filepath.Join(dir, "ab"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GH_PR_COMMENTS_CACHE_DIR", dir)
+
+	var out strings.Builder
+	if err := run([]string{"--clear-cache"}, nil, &out, io.Discard); err != nil {
+		t.Fatalf("run --clear-cache failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected cache directory to be removed, stat err = %v", err)
+	}
+	if !strings.Contains(out.String(), dir) {
+		t.Fatalf("expected output to mention %s, got %q", dir, out.String())
+	}
+}
+
+func TestCachedClientOptions(t *testing.T) {
+	t.Setenv("GH_PR_COMMENTS_CACHE_DIR", "/tmp/cache")
+	t.Setenv("GH_PR_COMMENTS_NO_CACHE", "")
+
+	if got := cachedClientOptions(false).CacheDir; got != "/tmp/cache" {
+		t.Fatalf("expected cache dir from environment, got %q", got)
+	}
+	if got := cachedClientOptions(true).CacheDir; got != "" {
+		t.Fatalf("expected --no-cache to disable caching, got %q", got)
+	}
+
+	t.Setenv("GH_PR_COMMENTS_NO_CACHE", "1")
+	if got := cachedClientOptions(false).CacheDir; got != "" {
+		t.Fatalf("expected GH_PR_COMMENTS_NO_CACHE to disable caching, got %q", got)
+	}
+}
+
+// fakeGitHub serves one pull request whose only comment carries HTML, and
+// points the CLI's client at it for the duration of the test.
+func fakeGitHub(t *testing.T, body string) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		switch r.URL.Path {
+		case "/api/v3/repos/o/r/pulls/1":
+			fmt.Fprint(w, `{"number": 1, "title": "Fix", "state": "open", "user": {"login": "owner"}, "head": {"ref": "fix", "sha": "abc"}, "base": {"ref": "main", "repo": {"name": "r", "owner": {"login": "o"}}}}`)
+		case "/api/v3/repos/o/r/issues/1/comments":
+			data, _ := json.Marshal([]map[string]any{{"id": 7, "body": body, "user": map[string]any{"login": "alice"}, "created_at": "2024-05-01T10:00:00Z"}})
+			w.Write(data)
+		case "/api/v3/repos/o/r/pulls/1/comments", "/api/v3/repos/o/r/pulls/1/reviews":
+			fmt.Fprint(w, `[]`)
+		case "/api/graphql":
+			fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {"reviewThreads": {"pageInfo": {"hasNextPage": false}, "nodes": []}}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	previous := githubTransport
+	githubTransport = server.Client().Transport
+	t.Cleanup(func() { githubTransport = previous })
+
+	t.Setenv("GH_HOST", strings.TrimPrefix(server.URL, "https://"))
+	t.Setenv("GH_TOKEN", "test-token")
+	t.Setenv("GH_REPO", "o/r")
+	t.Setenv("GH_PR_COMMENTS_NO_CACHE", "1")
+	t.Setenv("GH_PR_COMMENTS_CONFIG", filepath.Join(t.TempDir(), "none.yml"))
+}
+
+func TestRunTextStripsHTML(t *testing.T) {
+	fakeGitHub(t, `Please <b>rename</b> this <img src="x.png">`)
+
+	var out strings.Builder
+	if err := run([]string{"--pr", "1", "--text"}, nil, &out, io.Discard); err != nil {
+		t.Fatalf("run --text: %v", err)
+	}
+	if !strings.Contains(out.String(), "rename") {
+		t.Fatalf("expected the comment in the output:\n%s", out.String())
+	}
+	for _, tag := range []string{"<b>", "</b>", "<img"} {
+		if strings.Contains(out.String(), tag) {
+			t.Fatalf("--text output still contains %s:\n%s", tag, out.String())
+		}
+	}
+}
+
+func TestRunReportsQuota(t *testing.T) {
+	fakeGitHub(t, "looks good")
+
+	var errOut strings.Builder
+	if err := run([]string{"--pr", "1", "--no-interactive"}, nil, io.Discard, &errOut); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := errOut.String(); got != "API quota 4321/5000\n" {
+		t.Fatalf("expected a one-line quota summary, got %q", got)
+	}
+}
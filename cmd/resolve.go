@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+)
+
+// runResolve resolves or unresolves review threads picked by comment ID,
+// location, author, bot authorship or outdatedness.
+func runResolve(args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+
+	var prNumber int
+	var unresolve bool
+	var dryRun bool
+	var noCache bool
+	var filter ghprcomments.ThreadFilter
+
+	fs.IntVar(&prNumber, "p", 0, "pull request number")
+	fs.IntVar(&prNumber, "pr", 0, "pull request number")
+	fs.BoolVar(&unresolve, "unresolve", false, "reopen the matching threads instead of resolving them")
+	fs.StringVar(&filter.Author, "author", "", "only threads started by this user")
+	fs.BoolVar(&filter.Bots, "bots", false, "only threads started by bots")
+	fs.BoolVar(&filter.Outdated, "outdated", false, "only threads on code that has since changed")
+	fs.BoolVar(&dryRun, "dry-run", false, "list the matching threads without changing them")
+	fs.BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP cache")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if prNumber <= 0 {
+		return errors.New("resolve requires --pr")
+	}
+	for _, target := range fs.Args() {
+		if err := ghprcomments.ParseThreadTarget(target, &filter); err != nil {
+			return err
+		}
+	}
+	if filter.IsEmpty() {
+		return errors.New("resolve needs a comment ID, permalink or path:line, or --author, --bots or --outdated")
+	}
+
+	// Config supplies the bot patterns --bots relies on.
+	if _, err := loadConfig(errOut); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	client, err := newGitHubClient(ctx, cachedClientOptions(noCache))
+	if err != nil {
+		return err
+	}
+	fetcher := ghprcomments.NewFetcher(client).WithThreads()
+
+	repos, err := ghprcomments.DetectRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("detect repositories: %w", err)
+	}
+	pr, _, err := findPullRequest(ctx, fetcher, repos, prNumber)
+	if err != nil {
+		return err
+	}
+
+	payloads, err := fetcher.FetchComments(ctx, pr.RepoOwner, pr.RepoName, pr.Number)
+	if err != nil {
+		return fmt.Errorf("fetch comments: %w", err)
+	}
+	// Threads are resolved by their GraphQL ID, so there is nothing to do without them.
+	if warnings := payloads.Warnings(); len(warnings) > 0 {
+		return errors.New(strings.Join(warnings, "; "))
+	}
+	threads := ghprcomments.SelectThreads(ghprcomments.BuildOutput(pr, payloads, ghprcomments.NormalizationOptions{}), filter)
+	if len(threads) == 0 {
+		_, err := fmt.Fprintf(out, "No matching review threads on #%d\n", pr.Number)
+		return err
+	}
+
+	verb, done := "resolve", "Resolved"
+	if unresolve {
+		verb, done = "unresolve", "Unresolved"
+	}
+	changed, unchanged, failed := 0, 0, 0
+	for _, thread := range threads {
+		if thread.Resolved != unresolve {
+			unchanged++
+			continue
+		}
+		if dryRun {
+			fmt.Fprintf(out, "would %s %s (%s, %d comment(s))\n", verb, thread.Location(), thread.Root.Author, thread.Comments)
+			changed++
+			continue
+		}
+		if err := fetcher.SetThreadResolved(ctx, thread.ID, !unresolve); err != nil {
+			fmt.Fprintf(errOut, "warning: %s %s: %v\n", verb, thread.Location(), err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(out, "%sd %s (%s, %d comment(s))\n", verb, thread.Location(), thread.Root.Author, thread.Comments)
+		changed++
+	}
+
+	summary := fmt.Sprintf("%s %d thread(s) on #%d", done, changed, pr.Number)
+	if dryRun {
+		summary = fmt.Sprintf("Would %s %d thread(s) on #%d", verb, changed, pr.Number)
+	}
+	if unchanged > 0 {
+		summary += fmt.Sprintf(" (%d already %sd)", unchanged, verb)
+	}
+	fmt.Fprintln(out, summary)
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d thread(s)", verb, failed)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+)
+
+// runReview manages locally drafted review comments and submits them as one review.
+func runReview(args []string, in io.Reader, out, errOut io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("review requires a subcommand: add, list, remove or submit")
+	}
+	switch args[0] {
+	case "add":
+		return runReviewAdd(args[1:], in, out, errOut)
+	case "list":
+		return runReviewList(args[1:], out, errOut)
+	case "remove":
+		return runReviewRemove(args[1:], out, errOut)
+	case "submit":
+		return runReviewSubmit(args[1:], out, errOut)
+	default:
+		return fmt.Errorf("unknown review subcommand %q (want add, list, remove or submit)", args[0])
+	}
+}
+
+// reviewFlags holds the flags every review subcommand shares.
+type reviewFlags struct {
+	prNumber int
+	saveDir  string
+}
+
+func newReviewFlagSet(name string, errOut io.Writer) (*flag.FlagSet, *reviewFlags) {
+	fs := flag.NewFlagSet("review "+name, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	var shared reviewFlags
+	fs.IntVar(&shared.prNumber, "p", 0, "pull request number")
+	fs.IntVar(&shared.prNumber, "pr", 0, "pull request number")
+	fs.StringVar(&shared.saveDir, "save-dir", "", "override directory holding drafts (defaults to the --save directory)")
+	return fs, &shared
+}
+
+// openDraftStore finds the current repository without calling the API, so
+// drafts can be written offline.
+func openDraftStore(shared *reviewFlags, errOut io.Writer) (*ghprcomments.DraftStore, ghprcomments.Repository, error) {
+	if shared.prNumber <= 0 {
+		return nil, ghprcomments.Repository{}, errors.New("review requires --pr")
+	}
+	cfg, err := loadConfig(errOut)
+	if err != nil {
+		return nil, ghprcomments.Repository{}, err
+	}
+	saveDir := shared.saveDir
+	if saveDir == "" {
+		saveDir = cfg.SaveDir
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	repos, err := ghprcomments.DetectRepositories(ctx)
+	if err != nil {
+		return nil, ghprcomments.Repository{}, fmt.Errorf("detect repositories: %w", err)
+	}
+	repo, err := currentRepository(repos)
+	if err != nil {
+		return nil, ghprcomments.Repository{}, err
+	}
+	if repo.Path == "" {
+		if repo.Path, err = ghprcomments.FindRepoRoot(ctx); err != nil {
+			return nil, ghprcomments.Repository{}, fmt.Errorf("find repo root: %w", err)
+		}
+	}
+	return ghprcomments.OpenDraftStore(repo.Path, saveDir, repo.Owner, repo.Name, shared.prNumber), repo, nil
+}
+
+// currentRepository picks the detected repository containing the working
+// directory, or the only one detected.
+func currentRepository(repos []ghprcomments.Repository) (ghprcomments.Repository, error) {
+	if len(repos) == 1 {
+		return repos[0], nil
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		for _, repo := range repos {
+			if rel, err := filepath.Rel(repo.Path, cwd); err == nil && !strings.HasPrefix(rel, "..") {
+				return repo, nil
+			}
+		}
+	}
+	return ghprcomments.Repository{}, errors.New("drafts need a single repository; run inside it or set GH_REPO")
+}
+
+func runReviewAdd(args []string, in io.Reader, out, errOut io.Writer) error {
+	fs, shared := newReviewFlagSet("add", errOut)
+	var draft ghprcomments.DraftComment
+	fs.StringVar(&draft.Path, "path", "", "file the comment is on, relative to the repository root")
+	fs.IntVar(&draft.Line, "line", 0, "line the comment is on (the last line for a range)")
+	fs.IntVar(&draft.StartLine, "start-line", 0, "first line of a multi-line comment")
+	fs.StringVar(&draft.Side, "side", "RIGHT", "diff side: RIGHT for new code, LEFT for removed code")
+	fs.StringVar(&draft.Body, "body", "", "comment text; read from stdin when omitted or -")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if draft.Path == "" || draft.Line <= 0 {
+		return errors.New("review add requires --path and --line")
+	}
+	if _, err := ghprcomments.ParseDraftSide(draft.Side); err != nil {
+		return err
+	}
+	store, _, err := openDraftStore(shared, errOut)
+	if err != nil {
+		return err
+	}
+	if draft.Body == "" || draft.Body == "-" {
+		data, err := io.ReadAll(in)
+		if err != nil {
+			return fmt.Errorf("read comment body: %w", err)
+		}
+		draft.Body = string(data)
+	}
+
+	added, err := store.Add(draft)
+	if err != nil {
+		return err
+	}
+	review, err := store.Load()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Drafted %s on #%d (%d pending)\n", ghprcomments.FormatDraft(added), shared.prNumber, len(review.Comments))
+	return err
+}
+
+func runReviewList(args []string, out, errOut io.Writer) error {
+	fs, shared := newReviewFlagSet("list", errOut)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	store, _, err := openDraftStore(shared, errOut)
+	if err != nil {
+		return err
+	}
+	review, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if len(review.Comments) == 0 {
+		_, err := fmt.Fprintf(out, "No drafts on #%d\n", shared.prNumber)
+		return err
+	}
+	for _, draft := range review.Comments {
+		first, _, _ := strings.Cut(draft.Body, "\n")
+		fmt.Fprintf(out, "%s  %s\n", ghprcomments.FormatDraft(draft), first)
+	}
+	return nil
+}
+
+func runReviewRemove(args []string, out, errOut io.Writer) error {
+	fs, shared := newReviewFlagSet("remove", errOut)
+	var all bool
+	fs.BoolVar(&all, "all", false, "discard every draft on the pull request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !all && fs.NArg() == 0 {
+		return errors.New("review remove needs draft IDs or --all")
+	}
+	ids := make([]int, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid draft ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	store, _, err := openDraftStore(shared, errOut)
+	if err != nil {
+		return err
+	}
+
+	if all {
+		if err := store.Clear(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(out, "Discarded all drafts on #%d\n", shared.prNumber)
+		return err
+	}
+	for _, id := range ids {
+		if err := store.Remove(id); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Removed draft #%d\n", id)
+	}
+	return nil
+}
+
+func runReviewSubmit(args []string, out, errOut io.Writer) error {
+	fs, shared := newReviewFlagSet("submit", errOut)
+	var eventFlag string
+	var body string
+	var noCache bool
+	fs.StringVar(&eventFlag, "event", "comment", "review verdict: approve, request-changes or comment")
+	fs.StringVar(&body, "body", "", "summary text for the review")
+	fs.BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP cache")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	event, err := ghprcomments.ParseReviewEvent(eventFlag)
+	if err != nil {
+		return err
+	}
+	store, repo, err := openDraftStore(shared, errOut)
+	if err != nil {
+		return err
+	}
+	review, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if err := ghprcomments.CheckReview(event, body, review.Comments); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	client, err := newGitHubClient(ctx, cachedClientOptions(noCache))
+	if err != nil {
+		return err
+	}
+	fetcher := ghprcomments.NewFetcher(client)
+
+	submitted, err := fetcher.SubmitReview(ctx, repo.Owner, repo.Name, shared.prNumber, event, body, review.Comments)
+	if err != nil {
+		return err
+	}
+	if err := store.Clear(); err != nil {
+		fmt.Fprintf(errOut, "warning: review submitted but drafts were kept: %v\n", err)
+	}
+	fmt.Fprintf(out, "Submitted %s review with %d comment(s) on %s/%s#%d\n", strings.ToLower(string(event)), len(review.Comments), repo.Owner, repo.Name, shared.prNumber)
+	if url := submitted.GetHTMLURL(); url != "" {
+		fmt.Fprintln(out, url)
+	}
+	return nil
+}
+
+// loadDrafts returns the drafts pending on pr so the explorer can show them.
+// Drafts are a convenience there, so a missing or unreadable store shows none.
+func loadDrafts(pr *ghprcomments.PullRequestSummary, saveDir string) []ghprcomments.DraftComment {
+	repoRoot := strings.TrimSpace(pr.LocalPath)
+	if repoRoot == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		root, err := ghprcomments.FindRepoRoot(ctx)
+		if err != nil {
+			return nil
+		}
+		repoRoot = root
+	}
+	review, err := ghprcomments.OpenDraftStore(repoRoot, saveDir, pr.RepoOwner, pr.RepoName, pr.Number).Load()
+	if err != nil {
+		return nil
+	}
+	return review.Comments
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	"github.com/Quisharoo/gh-pr-comments/internal/tui"
+)
+
+// runReviewer lists the review threads the authenticated user started on
+// other people's open PRs and whether each one needs another look.
+func runReviewer(args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("reviewer", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+
+	var allRepos bool
+	var openOnly bool
+	var text bool
+	var stripHTML bool
+	var stripLevelFlag string
+	var noInteractive bool
+	var noCache bool
+
+	fs.BoolVar(&allRepos, "all-repos", false, "search every repository instead of the ones detected locally")
+	fs.BoolVar(&openOnly, "open-only", false, "hide threads that have been resolved")
+	fs.BoolVar(&text, "text", false, "render the report as Markdown")
+	fs.BoolVar(&stripHTML, "strip-html", false, "strip HTML tags from comment bodies")
+	fs.StringVar(&stripLevelFlag, "strip-level", "plain", "body_text normalisation: raw, markdown or plain")
+	fs.BoolVar(&noInteractive, "no-interactive", false, "disable interactive TUI (for piping/scripting)")
+	fs.BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP cache")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(errOut)
+	if err != nil {
+		return err
+	}
+	if !flagsSet(fs)["strip-level"] {
+		stripLevelFlag = cfg.StripLevel
+	}
+	stripLevel, err := ghprcomments.ParseStripLevel(stripLevelFlag)
+	if err != nil {
+		return err
+	}
+	if text {
+		stripHTML = true
+	}
+	useInteractive := !noInteractive && !text && isTerminalWriter(out)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	fetcher, rateLimits, err := newTrackedFetcher(ctx, noCache, useInteractive, errOut)
+	if err != nil {
+		return err
+	}
+	if !useInteractive {
+		defer reportQuota(errOut, rateLimits)
+	}
+
+	login, err := fetcher.AuthenticatedLogin(ctx)
+	if err != nil {
+		return fmt.Errorf("identify user: %w", err)
+	}
+
+	reviewed, err := listUserPullRequests(ctx, fetcher, ghprcomments.PullRequestFilter{ReviewedBy: login}, allRepos, cfg.DiscoveryDepth, errOut)
+	if err != nil {
+		return err
+	}
+	// reviewed-by also matches your own PRs when you reply to reviews on them.
+	prs := reviewed[:0]
+	for _, pr := range reviewed {
+		if !strings.EqualFold(pr.Author, login) {
+			prs = append(prs, pr)
+		}
+	}
+
+	report, err := fetcher.FetchReviewerReport(ctx, login, prs, ghprcomments.ReviewerOptions{
+		Normalization: ghprcomments.NormalizationOptions{
+			StripHTML:      stripHTML,
+			Level:          stripLevel,
+			IgnoredAuthors: cfg.IgnoredAuthors,
+		},
+		Concurrency: rateLimits.Concurrency(4),
+		OpenOnly:    openOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("build reviewer report: %w", err)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(errOut, "warning: %s\n", warning)
+	}
+
+	if text {
+		_, err := io.WriteString(out, ghprcomments.RenderReviewerMarkdown(report))
+		return err
+	}
+
+	payload, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON: %w", err)
+	}
+	if useInteractive {
+		if _, err := tui.RunUnifiedFlow(nil, payload); err != nil {
+			return fmt.Errorf("explore JSON: %w", err)
+		}
+		return nil
+	}
+	if _, err := out.Write(append(payload, '\n')); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
+	}
+	return nil
+}
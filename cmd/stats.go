@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+)
+
+// runStats reports review statistics for one pull request or every pull
+// request matching the filters.
+func runStats(args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+
+	var prNumber int
+	var jsonOutput bool
+	var allRepos bool
+	var noCache bool
+	var stateFlag string
+	var updatedSinceFlag string
+	var labels stringList
+	var filter ghprcomments.PullRequestFilter
+
+	fs.IntVar(&prNumber, "p", 0, "pull request number")
+	fs.IntVar(&prNumber, "pr", 0, "pull request number")
+	fs.BoolVar(&jsonOutput, "json", false, "write the statistics as JSON instead of a table")
+	fs.BoolVar(&allRepos, "all-repos", false, "search every repository instead of the ones detected locally")
+	fs.BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP cache")
+	fs.StringVar(&stateFlag, "state", "open", "include pull requests that are open, closed, merged or all")
+	fs.StringVar(&filter.Author, "author", "", "include pull requests opened by this user (@me for yourself)")
+	fs.Var(&labels, "label", "include pull requests carrying this label (repeatable)")
+	fs.StringVar(&filter.Base, "base", "", "include pull requests targeting this base branch")
+	fs.StringVar(&updatedSinceFlag, "updated-since", "", "include pull requests updated since a date (2006-01-02) or age (72h, 14d, 2w)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var err error
+	if filter.State, err = ghprcomments.ParsePullRequestState(stateFlag); err != nil {
+		return err
+	}
+	if filter.UpdatedSince, err = ghprcomments.ParseUpdatedSince(updatedSinceFlag, time.Now()); err != nil {
+		return err
+	}
+	filter.Labels = labels
+
+	cfg, err := loadConfig(errOut)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	fetcher, rateLimits, err := newTrackedFetcher(ctx, noCache, false, errOut)
+	if err != nil {
+		return err
+	}
+	defer reportQuota(errOut, rateLimits)
+
+	var prs []*ghprcomments.PullRequestSummary
+	if prNumber > 0 {
+		repos, err := ghprcomments.DetectRepositoriesWithDepth(ctx, cfg.DiscoveryDepth)
+		if err != nil {
+			return fmt.Errorf("detect repositories: %w", err)
+		}
+		pr, _, err := findPullRequest(ctx, fetcher, repos, prNumber)
+		if err != nil {
+			return err
+		}
+		prs = append(prs, pr)
+	} else {
+		if prs, err = listUserPullRequests(ctx, fetcher, filter, allRepos, cfg.DiscoveryDepth, errOut); err != nil {
+			return err
+		}
+		if len(prs) == 0 {
+			_, err := fmt.Fprintln(out, "No pull requests match")
+			return err
+		}
+	}
+
+	report, err := fetcher.FetchStats(ctx, prs, ghprcomments.StatsOptions{
+		Normalization: ghprcomments.NormalizationOptions{IgnoredAuthors: cfg.IgnoredAuthors},
+		Concurrency:   rateLimits.Concurrency(4),
+	})
+	if err != nil {
+		return fmt.Errorf("build stats: %w", err)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(errOut, "warning: %s\n", warning)
+	}
+
+	if !jsonOutput {
+		return ghprcomments.RenderStatsTable(out, report)
+	}
+	payload, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON: %w", err)
+	}
+	if _, err := out.Write(append(payload, '\n')); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+)
+
+// runApplySuggestions writes reviewers' ```suggestion blocks into the local checkout.
+func runApplySuggestions(args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("apply-suggestions", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+
+	var prNumber int
+	var dryRun bool
+	var includeResolved bool
+	var noCache bool
+
+	fs.IntVar(&prNumber, "p", 0, "pull request number")
+	fs.IntVar(&prNumber, "pr", 0, "pull request number")
+	fs.BoolVar(&dryRun, "dry-run", false, "print a unified diff instead of modifying files")
+	fs.BoolVar(&includeResolved, "include-resolved", false, "also apply suggestions on resolved threads")
+	fs.BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP cache")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if prNumber <= 0 {
+		return errors.New("apply-suggestions requires --pr")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	client, err := newGitHubClient(ctx, cachedClientOptions(noCache))
+	if err != nil {
+		return err
+	}
+	fetcher := ghprcomments.NewFetcher(client)
+	if !includeResolved {
+		fetcher = fetcher.WithThreads()
+	}
+
+	repos, err := ghprcomments.DetectRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("detect repositories: %w", err)
+	}
+	pr, repo, err := findPullRequest(ctx, fetcher, repos, prNumber)
+	if err != nil {
+		return err
+	}
+
+	payloads, err := fetcher.FetchComments(ctx, pr.RepoOwner, pr.RepoName, pr.Number)
+	if err != nil {
+		return fmt.Errorf("fetch comments: %w", err)
+	}
+	for _, warning := range payloads.Warnings() {
+		fmt.Fprintf(errOut, "warning: %s\n", warning)
+	}
+	output := ghprcomments.BuildOutput(pr, payloads, ghprcomments.NormalizationOptions{UnresolvedOnly: !includeResolved})
+	suggestions := ghprcomments.CollectSuggestions(output)
+	if len(suggestions) == 0 {
+		_, err := fmt.Fprintf(out, "No suggestions to apply on #%d\n", pr.Number)
+		return err
+	}
+
+	root := strings.TrimSpace(repo.Path)
+	if root == "" {
+		root, err = ghprcomments.FindRepoRoot(ctx)
+		if err != nil {
+			return fmt.Errorf("find repo root: %w", err)
+		}
+	}
+
+	// Suggestion lines are numbered against the PR head; applying them to
+	// another commit would patch the wrong code.
+	head, err := ghprcomments.CheckoutHead(ctx, root)
+	if err != nil {
+		return err
+	}
+	if pr.HeadSHA != "" && head != pr.HeadSHA {
+		mismatch := fmt.Sprintf("local HEAD %s differs from the head of #%d (%s)", ghprcomments.ShortSHA(head), pr.Number, ghprcomments.ShortSHA(pr.HeadSHA))
+		if !dryRun {
+			return fmt.Errorf("%s; check out the pull request (gh pr checkout %d) and pull before applying", mismatch, pr.Number)
+		}
+		fmt.Fprintf(errOut, "warning: %s; the diff may not apply\n", mismatch)
+	}
+
+	plan, err := ghprcomments.PlanSuggestions(root, pr.HeadSHA, suggestions)
+	if err != nil {
+		return err
+	}
+	for _, skip := range plan.Skipped {
+		fmt.Fprintf(errOut, "warning: skipped suggestion by %s on %s:%d; %s\n", skip.Suggestion.Author, skip.Suggestion.Path, skip.Suggestion.Line, skip.Reason)
+	}
+
+	if dryRun {
+		_, err := io.WriteString(out, plan.UnifiedDiff())
+		return err
+	}
+
+	if err := plan.Apply(); err != nil {
+		return fmt.Errorf("apply suggestions: %w", err)
+	}
+	applied := 0
+	for _, file := range plan.Files {
+		applied += len(file.Applied)
+		fmt.Fprintf(out, "patched %s (%d suggestion(s))\n", file.Path, len(file.Applied))
+	}
+	_, err = fmt.Fprintf(out, "Applied %d of %d suggestion(s) to %d file(s)\n", applied, len(suggestions), len(plan.Files))
+	return err
+}
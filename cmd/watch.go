@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	"github.com/Quisharoo/gh-pr-comments/internal/tui"
+)
+
+// runWatch polls the pull request until interrupted, either streaming NDJSON
+// events to out or refreshing the JSON explorer in place. The explorer also
+// shows the drafts pending in saveDir, read afresh on each refresh.
+func runWatch(ctx context.Context, fetcher *ghprcomments.Fetcher, pr *ghprcomments.PullRequestSummary, opts ghprcomments.NormalizationOptions, interval time.Duration, rateLimits *ghprcomments.RateLimitTracker, interactive, flat bool, saveDir string, out, errOut io.Writer) error {
+	watcher := ghprcomments.NewWatcher(fetcher, pr, opts)
+
+	pollCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	_, output, err := watcher.Poll(pollCtx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("fetch comments: %w", err)
+	}
+
+	if interactive {
+		jsonData, err := ghprcomments.MarshalJSON(ghprcomments.WithDrafts(output, loadDrafts(pr, saveDir)), flat)
+		if err != nil {
+			return fmt.Errorf("marshal JSON: %w", err)
+		}
+		err = tui.RunUnifiedFlowWithWatch(jsonData, tui.WatchConfig{
+			Ctx:        ctx,
+			Interval:   interval,
+			RateLimits: rateLimits,
+			Checkout:   tui.Checkout{Dir: pr.LocalPath, HeadSHA: pr.HeadSHA},
+			Actions:    ghprcomments.NewResponder(fetcher, pr.RepoOwner, pr.RepoName, pr.Number),
+			Poll: func(c context.Context) ([]byte, string, error) {
+				events, latest, err := watcher.Poll(c)
+				if err != nil || len(events) == 0 {
+					return nil, "", err
+				}
+				data, err := ghprcomments.MarshalJSON(ghprcomments.WithDrafts(latest, loadDrafts(pr, saveDir)), flat)
+				return data, ghprcomments.SummarizeWatchEvents(events), err
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("explore JSON: %w", err)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(errOut, "watching %s#%d (%d comments); polling every %s\n", output.PR.Repo, pr.Number, output.CommentCount, interval)
+
+	watcher.Notify = func(err error, wait time.Duration) {
+		fmt.Fprintf(errOut, "warning: poll failed: %v; retrying in %s\n", err, wait.Round(time.Second))
+	}
+	enc := json.NewEncoder(out)
+	err = watcher.Run(ctx, interval, func(events []ghprcomments.WatchEvent, _ ghprcomments.Output) error {
+		for _, event := range events {
+			if err := enc.Encode(event); err != nil {
+				return fmt.Errorf("write event: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ require (
 	golang.org/x/oauth2 v0.21.0
 	golang.org/x/sync v0.17.0
 	golang.org/x/term v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
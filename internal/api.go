@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/google/go-github/v61/github"
@@ -16,11 +17,37 @@ var ErrNoPullRequests = errors.New("no pull requests found")
 
 // Fetcher bundles GitHub operations used by the CLI.
 type Fetcher struct {
-	client *github.Client
+	client   *github.Client
+	timeline bool
+	threads  bool
+}
+
+// ClientOptions tunes the HTTP stack beneath the GitHub client.
+type ClientOptions struct {
+	// Transport replaces the base transport the authenticated client sends requests through.
+	Transport http.RoundTripper
+	// CacheDir, when set, stores responses on disk and revalidates them with ETags.
+	CacheDir string
+	// RateLimits, when set, records the quota reported on every response.
+	RateLimits *RateLimitTracker
+	// RetryNotify, when set, is told about each rate-limit or server-error backoff.
+	RetryNotify func(reason string, wait time.Duration)
 }
 
 // NewGitHubClient constructs an authenticated GitHub REST client.
 func NewGitHubClient(ctx context.Context, token, host string) (*github.Client, error) {
+	return NewGitHubClientWithOptions(ctx, token, host, ClientOptions{})
+}
+
+// NewGitHubClientWithOptions constructs an authenticated GitHub REST client on a custom HTTP stack.
+func NewGitHubClientWithOptions(ctx context.Context, token, host string, opts ClientOptions) (*github.Client, error) {
+	transport := opts.Transport
+	if opts.CacheDir != "" {
+		transport = NewDiskCacheTransport(transport, opts.CacheDir)
+	}
+	retry := NewRetryTransport(transport, opts.RateLimits)
+	retry.Notify = opts.RetryNotify
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: retry})
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 	client := oauth2.NewClient(ctx, ts)
 
@@ -38,7 +65,9 @@ func NewFetcher(client *github.Client) *Fetcher {
 	return &Fetcher{client: client}
 }
 
-// PullRequestSummary carries the metadata we display and persist.
+// PullRequestSummary carries the metadata we display and persist. Summaries
+// built from search results have no HeadRef, HeadSHA or BaseRef until
+// Fetcher.FillBranches loads them.
 type PullRequestSummary struct {
 	Number    int
 	Title     string
@@ -47,10 +76,12 @@ type PullRequestSummary struct {
 	Created   time.Time
 	Updated   time.Time
 	HeadRef   string
+	HeadSHA   string
 	BaseRef   string
 	RepoName  string
 	RepoOwner string
 	URL       string
+	Labels    []string
 	LocalPath string `json:"-"`
 }
 
@@ -59,6 +90,15 @@ type commentPayload struct {
 	issueComments  []*github.IssueComment
 	reviewComments []*github.PullRequestComment
 	reviews        []*github.PullRequestReview
+	threads        []ReviewThreadState
+	timeline       []*github.Timeline
+	warnings       []string
+}
+
+// Warnings lists what the fetch had to leave out without failing, such as
+// thread resolution state when the GraphQL API is unavailable.
+func (p commentPayload) Warnings() []string {
+	return p.warnings
 }
 
 // FetchComments retrieves every comment category for the pull request.
@@ -67,6 +107,9 @@ func (f *Fetcher) FetchComments(ctx context.Context, owner, repo string, number
 		issues         []*github.IssueComment
 		reviewComments []*github.PullRequestComment
 		reviews        []*github.PullRequestReview
+		threads        []ReviewThreadState
+		timeline       []*github.Timeline
+		warnings       []string
 	)
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -98,6 +141,35 @@ func (f *Fetcher) FetchComments(ctx context.Context, owner, repo string, number
 		return nil
 	})
 
+	// Thread state only adds resolved/outdated flags, so a GraphQL failure
+	// (a token without GraphQL access, an older Enterprise host) leaves the
+	// comments without them rather than failing the fetch.
+	if f.threads {
+		g.Go(func() error {
+			data, err := f.FetchReviewThreads(ctx, owner, repo, number)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				warnings = append(warnings, fmt.Sprintf("review threads unavailable, resolution state omitted: %v", err))
+				return nil
+			}
+			threads = data
+			return nil
+		})
+	}
+
+	if f.timeline {
+		g.Go(func() error {
+			data, err := f.listTimeline(ctx, owner, repo, number)
+			if err != nil {
+				return fmt.Errorf("timeline: %w", err)
+			}
+			timeline = data
+			return nil
+		})
+	}
+
 	if err := g.Wait(); err != nil {
 		return commentPayload{}, err
 	}
@@ -106,6 +178,9 @@ func (f *Fetcher) FetchComments(ctx context.Context, owner, repo string, number
 		issueComments:  issues,
 		reviewComments: reviewComments,
 		reviews:        reviews,
+		threads:        threads,
+		timeline:       timeline,
+		warnings:       warnings,
 	}, nil
 }
 
@@ -125,45 +200,35 @@ func (f *Fetcher) GetPullRequestSummary(ctx context.Context, owner, repo string,
 	return summary, nil
 }
 
-// ListPullRequestSummaries returns a set of pull requests for interactive selection.
-func (f *Fetcher) ListPullRequestSummaries(ctx context.Context, owner, repo string) ([]*PullRequestSummary, error) {
-	opts := &github.PullRequestListOptions{
-		State:     "open",
-		Sort:      "updated",
-		Direction: "desc",
-		ListOptions: github.ListOptions{
-			PerPage: 50,
-		},
+// FillBranches loads the head and base of pr in place when it came from a
+// search, which leaves them empty. Summaries that already carry a head commit
+// are left alone, so callers can run it without checking first.
+func (f *Fetcher) FillBranches(ctx context.Context, pr *PullRequestSummary) error {
+	if pr.HeadSHA != "" {
+		return nil
 	}
-
-	var summaries []*PullRequestSummary
-
-	for {
-		prs, resp, err := f.client.PullRequests.List(ctx, owner, repo, opts)
-		if err != nil {
-			return nil, err
-		}
-		for _, pr := range prs {
-			summary := summarizePullRequest(pr)
-			if summary.RepoOwner == "" {
-				summary.RepoOwner = owner
-			}
-			if summary.RepoName == "" {
-				summary.RepoName = repo
-			}
-			summaries = append(summaries, summary)
-		}
-		if resp.NextPage == 0 || len(summaries) >= 200 {
-			break
-		}
-		opts.Page = resp.NextPage
+	detailed, _, err := f.client.PullRequests.Get(ctx, pr.RepoOwner, pr.RepoName, pr.Number)
+	if err != nil {
+		return fmt.Errorf("load pull request: %w", err)
 	}
+	pr.HeadRef = detailed.GetHead().GetRef()
+	pr.HeadSHA = detailed.GetHead().GetSHA()
+	pr.BaseRef = detailed.GetBase().GetRef()
+	return nil
+}
 
-	if len(summaries) == 0 {
-		return nil, ErrNoPullRequests
+// AuthenticatedLogin returns the login of the user the client's token belongs to.
+func (f *Fetcher) AuthenticatedLogin(ctx context.Context) (string, error) {
+	user, _, err := f.client.Users.Get(ctx, "")
+	if err != nil {
+		return "", err
 	}
+	return user.GetLogin(), nil
+}
 
-	return summaries, nil
+// ListPullRequestSummaries returns the open pull requests for interactive selection.
+func (f *Fetcher) ListPullRequestSummaries(ctx context.Context, owner, repo string) ([]*PullRequestSummary, error) {
+	return f.ListPullRequestSummariesWithFilter(ctx, owner, repo, PullRequestFilter{})
 }
 
 func (f *Fetcher) listIssueComments(ctx context.Context, owner, repo string, number int) ([]*github.IssueComment, error) {
@@ -232,8 +297,10 @@ func summarizePullRequest(pr *github.PullRequest) *PullRequestSummary {
 	}
 
 	headRef := ""
+	headSHA := ""
 	if pr.Head != nil {
 		headRef = pr.Head.GetRef()
+		headSHA = pr.Head.GetSHA()
 	}
 
 	baseRef := ""
@@ -250,17 +317,24 @@ func summarizePullRequest(pr *github.PullRequest) *PullRequestSummary {
 		created = pr.CreatedAt.Time
 	}
 
+	state := pr.GetState()
+	if pr.MergedAt != nil {
+		state = "merged"
+	}
+
 	return &PullRequestSummary{
 		Number:    pr.GetNumber(),
 		Title:     pr.GetTitle(),
 		Author:    author,
-		State:     pr.GetState(),
+		State:     state,
 		Created:   created,
 		Updated:   updated,
 		HeadRef:   headRef,
+		HeadSHA:   headSHA,
 		BaseRef:   baseRef,
 		RepoOwner: repoOwner,
 		RepoName:  repoName,
 		URL:       pr.GetHTMLURL(),
+		Labels:    labelNames(pr.Labels),
 	}
 }
@@ -80,6 +80,10 @@ func TestFetchComments_Success(t *testing.T) {
 			}
 			json.NewEncoder(w).Encode(reviews)
 
+		case r.URL.Path == "/graphql":
+			// Review thread resolution state
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[{"id":"T_1","isResolved":true,"isOutdated":false,"comments":{"nodes":[{"databaseId":200}]}}]}}}}}`))
+
 		default:
 			t.Errorf("unexpected request to %s", r.URL.Path)
 			http.Error(w, "not found", http.StatusNotFound)
@@ -89,7 +93,7 @@ func TestFetchComments_Success(t *testing.T) {
 	server, client := mockGitHubServer(t, handler)
 	defer server.Close()
 
-	fetcher := NewFetcher(client)
+	fetcher := NewFetcher(client).WithThreads()
 	payload, err := fetcher.FetchComments(ctx, "owner", "repo", 1)
 
 	if err != nil {
@@ -105,6 +109,9 @@ func TestFetchComments_Success(t *testing.T) {
 	if len(payload.reviews) != 1 {
 		t.Errorf("expected 1 review, got %d", len(payload.reviews))
 	}
+	if len(payload.threads) != 1 || !payload.threads[0].IsResolved {
+		t.Errorf("expected 1 resolved review thread, got %+v", payload.threads)
+	}
 }
 
 func TestFetchComments_Error(t *testing.T) {
@@ -200,6 +207,41 @@ func TestGetPullRequestSummary(t *testing.T) {
 	}
 }
 
+func TestFillBranches(t *testing.T) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&github.PullRequest{
+			Number: github.Int(42),
+			Head:   &github.PullRequestBranch{Ref: github.String("feature-x"), SHA: github.String("abc123")},
+			Base:   &github.PullRequestBranch{Ref: github.String("main")},
+		})
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+	fetcher := NewFetcher(client)
+
+	// A search hit keeps everything it had and gains its branches.
+	pr := &PullRequestSummary{Number: 42, RepoOwner: "owner", RepoName: "repo", Title: "From search", LocalPath: "/src/repo"}
+	if err := fetcher.FillBranches(context.Background(), pr); err != nil {
+		t.Fatalf("FillBranches failed: %v", err)
+	}
+	if pr.HeadRef != "feature-x" || pr.HeadSHA != "abc123" || pr.BaseRef != "main" {
+		t.Fatalf("expected branches to be filled, got %+v", pr)
+	}
+	if pr.Title != "From search" || pr.LocalPath != "/src/repo" {
+		t.Fatalf("expected the rest of the summary to be kept, got %+v", pr)
+	}
+
+	if err := fetcher.FillBranches(context.Background(), pr); err != nil {
+		t.Fatalf("second FillBranches failed: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected a filled summary not to be fetched again, got %d requests", requests)
+	}
+}
+
 func TestListPullRequestSummaries(t *testing.T) {
 	ctx := context.Background()
 
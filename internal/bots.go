@@ -0,0 +1,146 @@
+package ghprcomments
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Bot collapse modes.
+const (
+	// BotCollapseNone keeps every bot comment.
+	BotCollapseNone = "none"
+	// BotCollapseLatest keeps only the bot's newest comment.
+	BotCollapseLatest = "latest"
+	// BotCollapseSummary replaces the bot's comments with one summary entry.
+	BotCollapseSummary = "summary"
+)
+
+// BotCollapseAll is the BotCollapse key that applies to every bot without its own mode.
+const BotCollapseAll = "*"
+
+// ParseBotCollapse parses --collapse-bots values: either a bare mode for every
+// bot ("summary") or login=mode for one bot ("dependabot[bot]=latest").
+func ParseBotCollapse(values []string) (map[string]string, error) {
+	modes := make(map[string]string, len(values))
+	for _, value := range values {
+		login, mode, ok := strings.Cut(value, "=")
+		if !ok {
+			login, mode = BotCollapseAll, value
+		}
+		login = strings.ToLower(strings.TrimSpace(login))
+		mode = strings.ToLower(strings.TrimSpace(mode))
+		if login == "" {
+			return nil, fmt.Errorf("collapse-bots %q: missing bot login", value)
+		}
+		if err := validateBotCollapseMode(mode); err != nil {
+			return nil, fmt.Errorf("collapse-bots %q: %w", value, err)
+		}
+		modes[login] = mode
+	}
+	return modes, nil
+}
+
+func validateBotCollapseMode(mode string) error {
+	switch mode {
+	case BotCollapseNone, BotCollapseLatest, BotCollapseSummary:
+		return nil
+	default:
+		return fmt.Errorf("unknown collapse mode %q (want none, latest or summary)", mode)
+	}
+}
+
+func botCollapseMode(modes map[string]string, author string) string {
+	if mode, ok := modes[strings.ToLower(author)]; ok {
+		return mode
+	}
+	if mode, ok := modes[BotCollapseAll]; ok {
+		return mode
+	}
+	return BotCollapseNone
+}
+
+// filterBotComments applies ExcludeBots, OnlyBots and BotCollapse.
+func filterBotComments(all []Comment, opts NormalizationOptions) []Comment {
+	kept := all[:0]
+	for _, c := range all {
+		if (opts.ExcludeBots && c.IsBot) || (opts.OnlyBots && !c.IsBot) {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if len(opts.BotCollapse) == 0 {
+		return kept
+	}
+	return collapseBotComments(kept, opts.BotCollapse)
+}
+
+// collapseBotComments folds each bot's standalone comments into one entry.
+// Comments that are part of a reply chain stay put so threads keep their shape.
+func collapseBotComments(all []Comment, modes map[string]string) []Comment {
+	hasReplies := make(map[int64]bool)
+	for _, c := range all {
+		if c.InReplyTo != 0 {
+			hasReplies[c.InReplyTo] = true
+		}
+	}
+
+	groups := make(map[string][]int)
+	for i, c := range all {
+		if !c.IsBot || c.InReplyTo != 0 || hasReplies[c.ID] || IsTimelineEvent(c.Type) {
+			continue
+		}
+		if botCollapseMode(modes, c.Author) == BotCollapseNone {
+			continue
+		}
+		groups[c.Author] = append(groups[c.Author], i)
+	}
+
+	drop := make(map[int]bool)
+	replace := make(map[int]Comment)
+	for author, indexes := range groups {
+		if len(indexes) < 2 {
+			continue
+		}
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return all[indexes[a]].CreatedAt.Before(all[indexes[b]].CreatedAt)
+		})
+		first, latest := all[indexes[0]], all[indexes[len(indexes)-1]]
+		merged := latest
+		if botCollapseMode(modes, author) == BotCollapseSummary {
+			merged = summarizeBotComments(first, latest, len(indexes))
+		}
+		merged.CollapsedCount = len(indexes)
+		for _, i := range indexes {
+			drop[i] = true
+		}
+		replace[indexes[len(indexes)-1]] = merged
+	}
+
+	collapsed := make([]Comment, 0, len(all))
+	for i, c := range all {
+		if merged, ok := replace[i]; ok {
+			collapsed = append(collapsed, merged)
+			continue
+		}
+		if !drop[i] {
+			collapsed = append(collapsed, c)
+		}
+	}
+	return collapsed
+}
+
+func summarizeBotComments(first, latest Comment, count int) Comment {
+	const layout = "2006-01-02 15:04"
+	headline := fmt.Sprintf("%d comments from %s between %s and %s. Latest:", count, latest.Author, first.CreatedAt.UTC().Format(layout), latest.CreatedAt.UTC().Format(layout))
+	return Comment{
+		Type:         "bot_summary",
+		ID:           latest.ID,
+		Author:       latest.Author,
+		IsBot:        true,
+		CreatedAt:    latest.CreatedAt,
+		BodyText:     headline + "\n\n" + latest.BodyText,
+		BodyMarkdown: headline + "\n\n" + blockQuote(latest.BodyMarkdown),
+		Permalink:    latest.Permalink,
+	}
+}
@@ -0,0 +1,98 @@
+package ghprcomments
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBotCollapse(t *testing.T) {
+	modes, err := ParseBotCollapse([]string{"summary", "Dependabot[bot]=latest", "ci-bot = none"})
+	if err != nil {
+		t.Fatalf("ParseBotCollapse: %v", err)
+	}
+	want := map[string]string{"*": "summary", "dependabot[bot]": "latest", "ci-bot": "none"}
+	for login, mode := range want {
+		if modes[login] != mode {
+			t.Fatalf("mode for %q = %q, want %q (all: %v)", login, modes[login], mode, modes)
+		}
+	}
+
+	for _, bad := range []string{"squash", "=latest", "ci-bot=everything"} {
+		if _, err := ParseBotCollapse([]string{bad}); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func botTestComments() []Comment {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []Comment{
+		{Type: "issue", ID: 1, Author: "alice", CreatedAt: base, BodyText: "please fix"},
+		{Type: "issue", ID: 2, Author: "ci-bot", IsBot: true, CreatedAt: base.Add(time.Minute), BodyText: "build failed", BodyMarkdown: "build failed"},
+		{Type: "issue", ID: 3, Author: "ci-bot", IsBot: true, CreatedAt: base.Add(2 * time.Minute), BodyText: "build failed again", BodyMarkdown: "build failed again"},
+		{Type: "issue", ID: 4, Author: "ci-bot", IsBot: true, CreatedAt: base.Add(3 * time.Minute), BodyText: "build passed", BodyMarkdown: "build passed"},
+		{Type: "review_comment", ID: 5, Author: "ci-bot", IsBot: true, CreatedAt: base.Add(4 * time.Minute), BodyText: "lint: unused var"},
+		{Type: "review_comment", ID: 6, InReplyTo: 5, Author: "alice", CreatedAt: base.Add(5 * time.Minute), BodyText: "fixed"},
+		{Type: "issue", ID: 7, Author: "dependabot[bot]", IsBot: true, CreatedAt: base, BodyText: "bump"},
+	}
+}
+
+func TestFilterBotCommentsExcludeAndOnly(t *testing.T) {
+	excluded := filterBotComments(botTestComments(), NormalizationOptions{ExcludeBots: true})
+	for _, c := range excluded {
+		if c.IsBot {
+			t.Fatalf("expected no bot comments, got %+v", c)
+		}
+	}
+	if len(excluded) != 2 {
+		t.Fatalf("expected 2 human comments, got %d", len(excluded))
+	}
+
+	only := filterBotComments(botTestComments(), NormalizationOptions{OnlyBots: true})
+	if len(only) != 5 {
+		t.Fatalf("expected 5 bot comments, got %d", len(only))
+	}
+}
+
+func TestFilterBotCommentsCollapse(t *testing.T) {
+	latest := filterBotComments(botTestComments(), NormalizationOptions{BotCollapse: map[string]string{"ci-bot": BotCollapseLatest}})
+	if len(latest) != 5 {
+		t.Fatalf("expected 5 comments after collapsing, got %d", len(latest))
+	}
+	var folded *Comment
+	for i := range latest {
+		if latest[i].ID == 4 {
+			folded = &latest[i]
+		}
+		if latest[i].ID == 2 || latest[i].ID == 3 {
+			t.Fatalf("older bot comment %d should have been folded", latest[i].ID)
+		}
+	}
+	if folded == nil || folded.CollapsedCount != 3 || folded.BodyText != "build passed" {
+		t.Fatalf("unexpected folded comment %+v", folded)
+	}
+
+	summary := filterBotComments(botTestComments(), NormalizationOptions{BotCollapse: map[string]string{BotCollapseAll: BotCollapseSummary}})
+	var summaries int
+	for _, c := range summary {
+		if c.Type != "bot_summary" {
+			continue
+		}
+		summaries++
+		if c.Author != "ci-bot" || c.CollapsedCount != 3 {
+			t.Fatalf("unexpected summary %+v", c)
+		}
+		if !strings.HasPrefix(c.BodyText, "3 comments from ci-bot between 2024-05-01 10:01 and 2024-05-01 10:03") || !strings.HasSuffix(c.BodyText, "build passed") {
+			t.Fatalf("unexpected summary body %q", c.BodyText)
+		}
+	}
+	if summaries != 1 {
+		t.Fatalf("expected one summary (dependabot has a single comment), got %d", summaries)
+	}
+	for _, c := range summary {
+		if c.ID == 5 && c.Type != "review_comment" {
+			t.Fatal("bot comment with replies should not be collapsed")
+		}
+	}
+}
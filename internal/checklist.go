@@ -0,0 +1,176 @@
+package ghprcomments
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// checklistItemMax caps how much of a comment an item quotes.
+const checklistItemMax = 120
+
+// checklistItemRegex matches a rendered item and captures its box and key.
+var checklistItemRegex = regexp.MustCompile(`^\s*[-*] \[([ xX])\] .*<!-- ([a-z_]+:\d+) -->\s*$`)
+
+// ChecklistItem is one actionable comment in a review checklist.
+type ChecklistItem struct {
+	Comment Comment
+	Done    bool
+}
+
+// key identifies the item across runs; review comment and issue comment IDs
+// come from different sequences, so the type is part of it.
+func (item ChecklistItem) key() string {
+	return fmt.Sprintf("%s:%d", item.Comment.Type, item.Comment.ID)
+}
+
+// ChecklistItems picks the comments in out that ask something of the pull
+// request author: no bots, no approvals, no empty review events, nothing the
+// author wrote. ticked holds the keys of earlier items and whether they were
+// done; those stay in the list, and resolved threads tick themselves.
+func ChecklistItems(out Output, ticked map[string]bool) []ChecklistItem {
+	var items []ChecklistItem
+	for _, c := range outputComments(out) {
+		if !actionable(c, out.PR.Author) {
+			continue
+		}
+		item := ChecklistItem{Comment: c}
+		done, listed := ticked[item.key()]
+		resolved := c.Resolved != nil && *c.Resolved
+		if resolved && !listed {
+			continue
+		}
+		item.Done = done || resolved
+		items = append(items, item)
+	}
+
+	// File feedback first, in file order, then the conversation in the order it happened.
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Comment, items[j].Comment
+		if (a.Path == "") != (b.Path == "") {
+			return a.Path != ""
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if commentLine(a) != commentLine(b) {
+			return commentLine(a) < commentLine(b)
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return items
+}
+
+func actionable(c Comment, prAuthor string) bool {
+	switch {
+	case c.ID == 0, c.IsBot, c.Draft, IsTimelineEvent(c.Type):
+		return false
+	case prAuthor != "" && strings.EqualFold(c.Author, prAuthor):
+		return false
+	case c.Type == "review_event":
+		return !strings.EqualFold(c.State, "APPROVED") && strings.TrimSpace(c.BodyText) != ""
+	default:
+		return true
+	}
+}
+
+// RenderChecklist writes items as a Markdown task list. Each item ends in a
+// hidden comment key so a later run can carry its box over.
+func RenderChecklist(out Output, items []ChecklistItem) string {
+	var b strings.Builder
+	title := out.PR.Title
+	if title == "" {
+		title = fmt.Sprintf("PR #%d", out.PR.Number)
+	}
+	fmt.Fprintf(&b, "# Review checklist: %s\n\n", title)
+	if out.PR.URL != "" {
+		fmt.Fprintf(&b, "%s#%d · %s\n\n", safeMarkdownValue(out.PR.Repo), out.PR.Number, out.PR.URL)
+	}
+	if len(items) == 0 {
+		b.WriteString("Nothing to do.\n")
+		return b.String()
+	}
+
+	for _, item := range items {
+		c := item.Comment
+		box := " "
+		if item.Done {
+			box = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] ", box)
+		if c.Path != "" {
+			anchor := c.Path
+			if line := commentLine(c); line > 0 {
+				anchor = fmt.Sprintf("%s:%d", c.Path, line)
+			}
+			fmt.Fprintf(&b, "`%s` ", anchor)
+		}
+		fmt.Fprintf(&b, "@%s: %s", safeMarkdownValue(c.Author), checklistSummary(c.BodyText))
+		if c.Permalink != "" {
+			fmt.Fprintf(&b, " ([link](%s))", c.Permalink)
+		}
+		fmt.Fprintf(&b, " <!-- %s -->\n", item.key())
+	}
+	return b.String()
+}
+
+// checklistSummary is the first line of body, shortened to fit one item.
+func checklistSummary(body string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+	first = strings.TrimSpace(first)
+	if first == "" {
+		return "(empty)"
+	}
+	if utf8.RuneCountInString(first) > checklistItemMax {
+		first = string([]rune(first)[:checklistItemMax-1]) + "…"
+	}
+	return first
+}
+
+// ParseChecklistTicks reads the item keys of an earlier checklist and
+// whether each was ticked.
+func ParseChecklistTicks(data []byte) map[string]bool {
+	ticked := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if m := checklistItemRegex.FindStringSubmatch(scanner.Text()); m != nil {
+			ticked[m[2]] = m[1] != " "
+		}
+	}
+	return ticked
+}
+
+// UpdateChecklist writes the checklist for out to path, keeping the ticks of
+// any checklist already there. It reports the item and done counts.
+func UpdateChecklist(path string, out Output) (total, done int, err error) {
+	previous, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, 0, fmt.Errorf("read checklist: %w", err)
+	}
+	items := ChecklistItems(out, ParseChecklistTicks(previous))
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return 0, 0, fmt.Errorf("create checklist directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(RenderChecklist(out, items)), 0o644); err != nil {
+		return 0, 0, fmt.Errorf("write checklist: %w", err)
+	}
+	return len(items), done, nil
+}
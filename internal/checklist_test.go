@@ -0,0 +1,88 @@
+package ghprcomments
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func checklistOutput() Output {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	yes, no := true, false
+	line := func(n int) *int { return &n }
+	all := []Comment{
+		{Type: "review_comment", ID: 11, Author: "alice", Path: "b.go", Line: line(4), Resolved: &no, BodyText: "rename this", Permalink: "https://github.com/o/r/pull/1#discussion_r11", CreatedAt: base},
+		{Type: "review_comment", ID: 12, Author: "alice", Path: "a.go", Line: line(9), Resolved: &yes, BodyText: "already fixed", CreatedAt: base.Add(time.Minute)},
+		{Type: "review_comment", ID: 13, Author: "owner", Path: "a.go", Line: line(9), BodyText: "done", CreatedAt: base.Add(2 * time.Minute)},
+		{Type: "issue", ID: 11, Author: "bob", BodyText: "please add tests\nand docs", CreatedAt: base.Add(3 * time.Minute)},
+		{Type: "issue", ID: 14, Author: "ci[bot]", IsBot: true, BodyText: "coverage 80%", CreatedAt: base},
+		{Type: "review_event", ID: 15, Author: "carol", State: "APPROVED", BodyText: "LGTM", CreatedAt: base},
+		{Type: "review_event", ID: 16, Author: "alice", State: "CHANGES_REQUESTED", CreatedAt: base},
+		{Type: "event_committed", ID: 17, Author: "owner", CreatedAt: base},
+	}
+	return groupOutput(Output{PR: PullRequestMetadata{Repo: "o/r", Number: 1, Title: "Fix it", Author: "owner"}}, all, GroupByAuthor)
+}
+
+func TestChecklistItems(t *testing.T) {
+	keys := func(items []ChecklistItem) string {
+		var parts []string
+		for _, item := range items {
+			key := item.key()
+			if item.Done {
+				key += "✓"
+			}
+			parts = append(parts, key)
+		}
+		return strings.Join(parts, ",")
+	}
+
+	if got := keys(ChecklistItems(checklistOutput(), nil)); got != "review_comment:11,issue:11" {
+		t.Fatalf("fresh checklist = %q", got)
+	}
+	ticked := map[string]bool{"issue:11": true, "review_comment:12": false}
+	if got := keys(ChecklistItems(checklistOutput(), ticked)); got != "review_comment:12✓,review_comment:11,issue:11✓" {
+		t.Fatalf("rerun = %q", got)
+	}
+}
+
+func TestUpdateChecklistKeepsTicks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs", "TODO.md")
+	total, done, err := UpdateChecklist(path, checklistOutput())
+	if err != nil || total != 2 || done != 0 {
+		t.Fatalf("first run = %d, %d, %v", total, done, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	wantItem := "- [ ] `b.go:4` @alice: rename this ([link](https://github.com/o/r/pull/1#discussion_r11)) <!-- review_comment:11 -->"
+	if !strings.HasPrefix(text, "# Review checklist: Fix it\n") || !strings.Contains(text, wantItem) || !strings.Contains(text, "@bob: please add tests <!-- issue:11 -->") {
+		t.Fatalf("unexpected checklist:\n%s", text)
+	}
+
+	ticked := strings.Replace(text, "- [ ] `b.go:4`", "- [x] `b.go:4`", 1)
+	if err := os.WriteFile(path, []byte(ticked), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	total, done, err = UpdateChecklist(path, checklistOutput())
+	if err != nil || total != 2 || done != 1 {
+		t.Fatalf("second run = %d, %d, %v", total, done, err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "- [x] `b.go:4`") || !strings.Contains(string(data), "- [ ] @bob") {
+		t.Fatalf("ticks were not kept:\n%s", data)
+	}
+}
+
+func TestChecklistSummary(t *testing.T) {
+	if got := checklistSummary("  \n"); got != "(empty)" {
+		t.Fatalf("empty body = %q", got)
+	}
+	long := strings.Repeat("é", checklistItemMax+5)
+	if got := checklistSummary(long); len([]rune(got)) != checklistItemMax || !strings.HasSuffix(got, "…") {
+		t.Fatalf("long body not shortened: %d runes", len([]rune(got)))
+	}
+}
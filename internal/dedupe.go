@@ -0,0 +1,137 @@
+package ghprcomments
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	dedupeTimestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}([t ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(z|[+-]\d{2}:?\d{2})?)?|\d{1,2}:\d{2}(:\d{2})?`)
+	dedupeHashPattern      = regexp.MustCompile(`\b[0-9a-f]*\d[0-9a-f]*[a-f][0-9a-f]*\b|\b[0-9a-f]*[a-f][0-9a-f]*\d[0-9a-f]*\b`)
+	dedupeNumberPattern    = regexp.MustCompile(`[+-]?\d+(\.\d+)?`)
+)
+
+// DedupeBotComments keeps only the latest of each set of near-identical bot
+// comments. Bodies are compared after masking numbers, hashes and timestamps,
+// so a coverage report re-posted on every push collapses into one entry that
+// records how often it appeared and when it was first seen. Comments that are
+// part of a reply chain are left alone.
+func DedupeBotComments(out Output) Output {
+	if outputGroupBy(out) != GroupByThread {
+		all := outputComments(out)
+		kept := dedupeComments(all)
+		out.CommentCount -= len(all) - len(kept)
+		return regroupOutput(out, kept)
+	}
+
+	// Only reply-less threads are candidates; their roots are deduplicated together.
+	var standalone []Comment
+	for _, thread := range out.Threads {
+		if len(thread.Replies) == 0 {
+			standalone = append(standalone, thread.Root)
+		}
+	}
+	kept := make(map[int64]Comment)
+	for _, c := range dedupeComments(standalone) {
+		kept[c.ID] = c
+	}
+
+	threads := make([]Thread, 0, len(out.Threads))
+	for _, thread := range out.Threads {
+		if len(thread.Replies) == 0 {
+			root, ok := kept[thread.Root.ID]
+			if !ok {
+				out.CommentCount--
+				continue
+			}
+			thread.Root = root
+		}
+		threads = append(threads, thread)
+	}
+	out.Threads = threads
+	return out
+}
+
+// dedupeComments drops all but the newest comment for each bot fingerprint,
+// keeping the survivors in their original order.
+func dedupeComments(comments []Comment) []Comment {
+	hasReplies := make(map[int64]bool)
+	for _, c := range comments {
+		if c.InReplyTo != 0 {
+			hasReplies[c.InReplyTo] = true
+		}
+	}
+
+	type occurrence struct {
+		latest    int
+		count     int
+		firstSeen time.Time
+	}
+	seen := make(map[string]*occurrence)
+	keys := make([]string, len(comments))
+	for i, c := range comments {
+		if !c.IsBot || c.InReplyTo != 0 || hasReplies[c.ID] || IsTimelineEvent(c.Type) {
+			continue
+		}
+		key := dedupeFingerprint(c)
+		keys[i] = key
+		occ, ok := seen[key]
+		if !ok {
+			seen[key] = &occurrence{latest: i, count: 1, firstSeen: c.CreatedAt}
+			continue
+		}
+		occ.count++
+		if c.CreatedAt.After(comments[occ.latest].CreatedAt) {
+			occ.latest = i
+		}
+		if c.CreatedAt.Before(occ.firstSeen) {
+			occ.firstSeen = c.CreatedAt
+		}
+	}
+
+	kept := make([]Comment, 0, len(comments))
+	for i, c := range comments {
+		if keys[i] == "" {
+			kept = append(kept, c)
+			continue
+		}
+		occ := seen[keys[i]]
+		if occ.latest != i {
+			continue
+		}
+		if occ.count > 1 {
+			firstSeen := occ.firstSeen
+			c.Occurrences = occ.count
+			c.FirstSeen = &firstSeen
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+// dedupeFingerprint identifies a comment by author, type, file and body, with
+// volatile tokens masked out.
+func dedupeFingerprint(c Comment) string {
+	body := c.BodyMarkdown
+	if body == "" {
+		body = c.BodyText
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		strings.ToLower(c.Author),
+		c.Type,
+		c.Path,
+		normalizeDedupeBody(body),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeDedupeBody(body string) string {
+	body = strings.ToLower(body)
+	body = dedupeTimestampPattern.ReplaceAllString(body, "<time>")
+	body = dedupeHashPattern.ReplaceAllString(body, "<hash>")
+	body = dedupeNumberPattern.ReplaceAllString(body, "<n>")
+	return strings.Join(strings.Fields(body), " ")
+}
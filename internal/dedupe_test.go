@@ -0,0 +1,79 @@
+package ghprcomments
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeDedupeBody(t *testing.T) {
+	a := normalizeDedupeBody("Coverage 81.5% (+0.2) at commit 3f9a2c1d on 2024-05-01T10:00:00Z")
+	b := normalizeDedupeBody("Coverage  79.9% (-1.4) at commit 0be71e44 on 2024-05-03T18:42:07Z")
+	if a != b {
+		t.Fatalf("expected volatile tokens to be masked:\n%q\n%q", a, b)
+	}
+	if normalizeDedupeBody("build failed") == normalizeDedupeBody("build passed") {
+		t.Fatal("different wording should not collide")
+	}
+}
+
+func TestDedupeBotCommentsGrouped(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	out := Output{
+		CommentCount: 5,
+		Comments: []AuthorComments{
+			{Author: "codecov[bot]", Comments: []Comment{
+				{Type: "issue", ID: 3, Author: "codecov[bot]", IsBot: true, CreatedAt: base.Add(2 * time.Hour), BodyMarkdown: "Coverage 79.1% for abc1234"},
+				{Type: "issue", ID: 2, Author: "codecov[bot]", IsBot: true, CreatedAt: base.Add(time.Hour), BodyMarkdown: "Coverage 80.4% for 9f8e7d6"},
+				{Type: "issue", ID: 1, Author: "codecov[bot]", IsBot: true, CreatedAt: base, BodyMarkdown: "Coverage 80.0% for 1a2b3c4"},
+			}},
+			{Author: "alice", Comments: []Comment{
+				{Type: "issue", ID: 5, Author: "alice", CreatedAt: base.Add(time.Minute), BodyMarkdown: "ping"},
+				{Type: "issue", ID: 4, Author: "alice", CreatedAt: base, BodyMarkdown: "ping"},
+			}},
+		},
+	}
+
+	deduped := DedupeBotComments(out)
+	if deduped.CommentCount != 3 {
+		t.Fatalf("CommentCount = %d, want 3", deduped.CommentCount)
+	}
+	bot := deduped.Comments[0].Comments
+	if len(bot) != 1 || bot[0].ID != 3 {
+		t.Fatalf("expected only the latest bot comment, got %+v", bot)
+	}
+	if bot[0].Occurrences != 3 || bot[0].FirstSeen == nil || !bot[0].FirstSeen.Equal(base) {
+		t.Fatalf("unexpected occurrence data %d %v", bot[0].Occurrences, bot[0].FirstSeen)
+	}
+	if len(deduped.Comments[1].Comments) != 2 {
+		t.Fatal("human comments must not be deduplicated")
+	}
+	if out.Comments[0].Comments[0].Occurrences != 0 || len(out.Comments[0].Comments) != 3 {
+		t.Fatal("input output should not be modified")
+	}
+}
+
+func TestDedupeBotCommentsThreads(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	lint := func(id int64, at time.Time) Comment {
+		return Comment{Type: "review_comment", ID: id, Author: "linter", IsBot: true, CreatedAt: at, Path: "main.go", BodyText: "unused variable x"}
+	}
+	out := Output{
+		CommentCount: 4,
+		Threads: []Thread{
+			{ID: 1, Root: lint(1, base)},
+			{ID: 2, Root: lint(2, base.Add(time.Hour))},
+			{ID: 3, Root: lint(3, base.Add(2*time.Hour)), Replies: []Comment{{Type: "review_comment", ID: 4, InReplyTo: 3, Author: "alice", BodyText: "on purpose"}}},
+		},
+	}
+
+	deduped := DedupeBotComments(out)
+	if len(deduped.Threads) != 2 || deduped.CommentCount != 3 {
+		t.Fatalf("expected 2 threads and 3 comments, got %d and %d", len(deduped.Threads), deduped.CommentCount)
+	}
+	if deduped.Threads[0].Root.ID != 2 || deduped.Threads[0].Root.Occurrences != 2 {
+		t.Fatalf("unexpected surviving root %+v", deduped.Threads[0].Root)
+	}
+	if deduped.Threads[1].Root.ID != 3 || deduped.Threads[1].Root.Occurrences != 0 {
+		t.Fatal("thread with replies should be kept untouched")
+	}
+}
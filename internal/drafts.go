@@ -0,0 +1,269 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// DraftComment is a review comment written locally and not yet posted.
+type DraftComment struct {
+	ID        int       `json:"id"` // local sequence number, stable until submitted
+	Path      string    `json:"path"`
+	Line      int       `json:"line"`
+	StartLine int       `json:"start_line,omitempty"`
+	Side      string    `json:"side"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DraftReview is the set of drafts pending on one pull request.
+type DraftReview struct {
+	Repo     string         `json:"repo"`
+	Number   int            `json:"number"`
+	Comments []DraftComment `json:"comments"`
+}
+
+// DraftStore keeps a pull request's drafts in a JSON file under the save
+// directory's drafts/ folder.
+type DraftStore struct {
+	path   string
+	repo   string
+	number int
+}
+
+// OpenDraftStore locates the draft file for owner/repo#number. Nothing is
+// created until a draft is added.
+func OpenDraftStore(repoRoot, saveDir, owner, repo string, number int) *DraftStore {
+	dir := repoSaveDirectory(repoRoot, resolveSaveDir(repoRoot, saveDir), owner, repo)
+	return &DraftStore{
+		path:   filepath.Join(dir, "drafts", fmt.Sprintf("pr-%d.json", number)),
+		repo:   strings.Trim(owner+"/"+repo, "/"),
+		number: number,
+	}
+}
+
+// Path is the draft file's location.
+func (s *DraftStore) Path() string {
+	return s.path
+}
+
+// Load reads the pending drafts; a missing file means there are none.
+func (s *DraftStore) Load() (DraftReview, error) {
+	review := DraftReview{Repo: s.repo, Number: s.number}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return review, nil
+	}
+	if err != nil {
+		return review, fmt.Errorf("read drafts: %w", err)
+	}
+	if err := json.Unmarshal(data, &review); err != nil {
+		return review, fmt.Errorf("parse drafts %s: %w", s.path, err)
+	}
+	return review, nil
+}
+
+// Add validates and stores a new draft, returning it with its ID assigned.
+func (s *DraftStore) Add(draft DraftComment) (DraftComment, error) {
+	draft.Path = filepath.ToSlash(strings.TrimSpace(draft.Path))
+	draft.Body = strings.TrimSpace(draft.Body)
+	side, err := ParseDraftSide(draft.Side)
+	if err != nil {
+		return DraftComment{}, err
+	}
+	draft.Side = side
+	switch {
+	case draft.Path == "":
+		return DraftComment{}, errors.New("draft needs a path")
+	case draft.Line <= 0:
+		return DraftComment{}, errors.New("draft needs a positive line")
+	case draft.StartLine < 0 || draft.StartLine > draft.Line:
+		return DraftComment{}, fmt.Errorf("start line %d must not be after line %d", draft.StartLine, draft.Line)
+	case draft.Body == "":
+		return DraftComment{}, errors.New("draft body is empty")
+	}
+	if draft.StartLine == draft.Line {
+		draft.StartLine = 0
+	}
+
+	review, err := s.Load()
+	if err != nil {
+		return DraftComment{}, err
+	}
+	for _, existing := range review.Comments {
+		draft.ID = max(draft.ID, existing.ID)
+	}
+	draft.ID++
+	if draft.CreatedAt.IsZero() {
+		draft.CreatedAt = time.Now().UTC()
+	}
+	review.Comments = append(review.Comments, draft)
+	return draft, s.save(review)
+}
+
+// Remove deletes the draft with the given ID.
+func (s *DraftStore) Remove(id int) error {
+	review, err := s.Load()
+	if err != nil {
+		return err
+	}
+	for i, draft := range review.Comments {
+		if draft.ID == id {
+			review.Comments = append(review.Comments[:i], review.Comments[i+1:]...)
+			if len(review.Comments) == 0 {
+				return s.Clear()
+			}
+			return s.save(review)
+		}
+	}
+	return fmt.Errorf("no draft %d on #%d", id, s.number)
+}
+
+// Clear discards every draft.
+func (s *DraftStore) Clear() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("clear drafts: %w", err)
+	}
+	return nil
+}
+
+func (s *DraftStore) save(review DraftReview) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create drafts directory: %w", err)
+	}
+	data, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write drafts: %w", err)
+	}
+	return nil
+}
+
+// ParseDraftSide validates a diff side; empty means RIGHT, the new code.
+func ParseDraftSide(value string) (string, error) {
+	switch side := strings.ToUpper(strings.TrimSpace(value)); side {
+	case "":
+		return "RIGHT", nil
+	case "LEFT", "RIGHT":
+		return side, nil
+	default:
+		return "", fmt.Errorf("unknown side %q (want LEFT or RIGHT)", value)
+	}
+}
+
+// ReviewEvent is the verdict a submitted review carries.
+type ReviewEvent string
+
+const (
+	ReviewApprove        ReviewEvent = "APPROVE"
+	ReviewRequestChanges ReviewEvent = "REQUEST_CHANGES"
+	ReviewComment        ReviewEvent = "COMMENT"
+)
+
+// ParseReviewEvent accepts approve, request-changes or comment in any case,
+// with dashes or underscores; empty means comment.
+func ParseReviewEvent(value string) (ReviewEvent, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(value), "-", "_"))
+	switch event := ReviewEvent(normalized); event {
+	case "":
+		return ReviewComment, nil
+	case ReviewApprove, ReviewRequestChanges, ReviewComment:
+		return event, nil
+	default:
+		return "", fmt.Errorf("unknown review event %q (want approve, request-changes or comment)", value)
+	}
+}
+
+// CheckReview rejects a review GitHub would refuse: requesting changes or
+// commenting needs a body or at least one draft. Only approvals may be empty.
+func CheckReview(event ReviewEvent, body string, drafts []DraftComment) error {
+	if len(drafts) == 0 && strings.TrimSpace(body) == "" && event != ReviewApprove {
+		return errors.New("nothing to submit: no drafts and no review body")
+	}
+	return nil
+}
+
+// SubmitReview posts drafts as a single review carrying event and an optional
+// summary body. It checks the review with CheckReview before calling GitHub.
+func (f *Fetcher) SubmitReview(ctx context.Context, owner, repo string, number int, event ReviewEvent, body string, drafts []DraftComment) (*github.PullRequestReview, error) {
+	if err := CheckReview(event, body, drafts); err != nil {
+		return nil, err
+	}
+	body = strings.TrimSpace(body)
+
+	request := &github.PullRequestReviewRequest{Event: github.String(string(event))}
+	if body != "" {
+		request.Body = github.String(body)
+	}
+	for _, draft := range drafts {
+		comment := &github.DraftReviewComment{
+			Path: github.String(draft.Path),
+			Line: github.Int(draft.Line),
+			Side: github.String(draft.Side),
+			Body: github.String(draft.Body),
+		}
+		if draft.StartLine > 0 {
+			comment.StartLine = github.Int(draft.StartLine)
+			comment.StartSide = github.String(draft.Side)
+		}
+		request.Comments = append(request.Comments, comment)
+	}
+
+	review, _, err := f.client.PullRequests.CreateReview(ctx, owner, repo, number, request)
+	if err != nil {
+		return nil, fmt.Errorf("submit review: %w", err)
+	}
+	return review, nil
+}
+
+// WithDrafts adds drafts to out as "draft" entries marked draft: true, keeping
+// out's grouping, so they can be read alongside the posted comments.
+func WithDrafts(out Output, drafts []DraftComment) Output {
+	if len(drafts) == 0 {
+		return out
+	}
+	all := outputComments(out)
+	for _, draft := range drafts {
+		line := draft.Line
+		c := Comment{
+			Type:         "draft",
+			Author:       "(draft)",
+			CreatedAt:    draft.CreatedAt,
+			Path:         draft.Path,
+			Line:         &line,
+			Side:         draft.Side,
+			BodyText:     flattenCommentBody(draft.Body),
+			BodyMarkdown: draft.Body,
+			Draft:        true,
+			DraftID:      draft.ID,
+		}
+		if draft.StartLine > 0 {
+			start := draft.StartLine
+			c.StartLine = &start
+		}
+		all = append(all, c)
+	}
+	out = regroupOutput(out, all)
+	out.CommentCount += len(drafts)
+	return out
+}
+
+// FormatDraft renders a draft as "#ID path:line (side)" for listings.
+func FormatDraft(draft DraftComment) string {
+	location := draft.Path + ":" + strconv.Itoa(draft.Line)
+	if draft.StartLine > 0 {
+		location = fmt.Sprintf("%s:%d-%d", draft.Path, draft.StartLine, draft.Line)
+	}
+	return fmt.Sprintf("#%d %s (%s)", draft.ID, location, draft.Side)
+}
@@ -0,0 +1,191 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDraftStore(t *testing.T) {
+	root := t.TempDir()
+	store := OpenDraftStore(root, "", "octo", "widgets", 7)
+	if want := filepath.Join(root, defaultSaveDir, "drafts", "pr-7.json"); store.Path() != want {
+		t.Fatalf("path = %q, want %q", store.Path(), want)
+	}
+
+	review, err := store.Load()
+	if err != nil || len(review.Comments) != 0 {
+		t.Fatalf("expected no drafts before the first add, got %+v, %v", review, err)
+	}
+
+	first, err := store.Add(DraftComment{Path: "./a.go", Line: 3, Body: " tidy this \n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != 1 || first.Side != "RIGHT" || first.Body != "tidy this" || first.CreatedAt.IsZero() {
+		t.Fatalf("unexpected draft %+v", first)
+	}
+	second, err := store.Add(DraftComment{Path: "b.go", Line: 9, StartLine: 5, Side: "left", Body: "why?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != 2 || second.Side != "LEFT" || FormatDraft(second) != "#2 b.go:5-9 (LEFT)" {
+		t.Fatalf("unexpected draft %+v", second)
+	}
+
+	if err := store.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	third, err := store.Add(DraftComment{Path: "c.go", Line: 1, Body: "nit"})
+	if err != nil || third.ID != 3 {
+		t.Fatalf("IDs must not be reused, got %+v, %v", third, err)
+	}
+	review, err = store.Load()
+	if err != nil || review.Repo != "octo/widgets" || len(review.Comments) != 2 {
+		t.Fatalf("unexpected review %+v, %v", review, err)
+	}
+	if err := store.Remove(1); err == nil {
+		t.Fatal("expected removing a missing draft to fail")
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(store.Path()); !os.IsNotExist(err) {
+		t.Fatalf("expected the draft file to be gone, got %v", err)
+	}
+}
+
+func TestDraftStoreRejectsInvalidDrafts(t *testing.T) {
+	store := OpenDraftStore(t.TempDir(), "", "octo", "widgets", 1)
+	for _, draft := range []DraftComment{
+		{Line: 1, Body: "x"},
+		{Path: "a.go", Body: "x"},
+		{Path: "a.go", Line: 2, StartLine: 3, Body: "x"},
+		{Path: "a.go", Line: 2, Body: "  "},
+		{Path: "a.go", Line: 2, Side: "middle", Body: "x"},
+	} {
+		if _, err := store.Add(draft); err == nil {
+			t.Errorf("expected %+v to be rejected", draft)
+		}
+	}
+}
+
+func TestParseReviewEvent(t *testing.T) {
+	for input, want := range map[string]ReviewEvent{
+		"":                "COMMENT",
+		"approve":         "APPROVE",
+		"request-changes": "REQUEST_CHANGES",
+		"REQUEST_CHANGES": "REQUEST_CHANGES",
+		" Comment ":       "COMMENT",
+	} {
+		if got, err := ParseReviewEvent(input); err != nil || got != want {
+			t.Errorf("ParseReviewEvent(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseReviewEvent("lgtm"); err == nil {
+		t.Fatal("expected an unknown event to be rejected")
+	}
+}
+
+func TestSubmitReview(t *testing.T) {
+	var got map[string]any
+	server, client := mockGitHubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/octo/widgets/pulls/7/reviews" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "html_url": "https://github.com/octo/widgets/pull/7#pullrequestreview-1"}`))
+	})
+	defer server.Close()
+
+	fetcher := NewFetcher(client)
+	drafts := []DraftComment{
+		{ID: 1, Path: "a.go", Line: 3, Side: "RIGHT", Body: "tidy"},
+		{ID: 2, Path: "b.go", Line: 9, StartLine: 5, Side: "LEFT", Body: "why?"},
+	}
+	review, err := fetcher.SubmitReview(context.Background(), "octo", "widgets", 7, ReviewRequestChanges, "see inline", drafts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(review.GetHTMLURL(), "pullrequestreview-1") {
+		t.Fatalf("unexpected review %+v", review)
+	}
+	if got["event"] != "REQUEST_CHANGES" || got["body"] != "see inline" {
+		t.Fatalf("unexpected review request %v", got)
+	}
+	comments, _ := got["comments"].([]any)
+	if len(comments) != 2 {
+		t.Fatalf("expected both drafts in one review, got %v", got["comments"])
+	}
+	ranged := comments[1].(map[string]any)
+	if ranged["start_line"] != float64(5) || ranged["line"] != float64(9) || ranged["side"] != "LEFT" || ranged["start_side"] != "LEFT" {
+		t.Fatalf("unexpected ranged comment %v", ranged)
+	}
+
+}
+
+func TestCheckReview(t *testing.T) {
+	var calls int
+	server, client := mockGitHubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+	defer server.Close()
+	fetcher := NewFetcher(client)
+	draft := []DraftComment{{ID: 1, Path: "a.go", Line: 3, Side: "RIGHT", Body: "tidy"}}
+
+	for _, tc := range []struct {
+		event  ReviewEvent
+		body   string
+		drafts []DraftComment
+		ok     bool
+	}{
+		{ReviewComment, "", nil, false},
+		{ReviewRequestChanges, "  ", nil, false},
+		{ReviewApprove, "", nil, true},
+		{ReviewRequestChanges, "", draft, true},
+		{ReviewComment, "looks close", nil, true},
+	} {
+		if err := CheckReview(tc.event, tc.body, tc.drafts); (err == nil) != tc.ok {
+			t.Errorf("CheckReview(%s, %q, %d drafts) = %v, want ok=%t", tc.event, tc.body, len(tc.drafts), err, tc.ok)
+		}
+		if tc.ok {
+			continue
+		}
+		if _, err := fetcher.SubmitReview(context.Background(), "octo", "widgets", 7, tc.event, tc.body, tc.drafts); err == nil || !strings.Contains(err.Error(), "nothing to submit") {
+			t.Errorf("SubmitReview(%s, %q) error = %v", tc.event, tc.body, err)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("expected empty reviews to be rejected before calling GitHub, got %d request(s)", calls)
+	}
+}
+
+func TestWithDrafts(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	posted := []Comment{{Type: "issue", ID: 1, Author: "alice", CreatedAt: base}}
+	drafts := []DraftComment{{ID: 4, Path: "a.go", Line: 3, Side: "RIGHT", Body: "tidy **this**", CreatedAt: base.Add(time.Hour)}}
+
+	out := WithDrafts(groupOutput(Output{CommentCount: 1}, posted, GroupByFile), drafts)
+	if out.CommentCount != 2 || len(out.Files) != 2 {
+		t.Fatalf("unexpected output %+v", out)
+	}
+	var draft *Comment
+	for _, c := range outputComments(out) {
+		if c.Draft {
+			draft = &c
+		}
+	}
+	if draft == nil || draft.DraftID != 4 || draft.Type != "draft" || *draft.Line != 3 || draft.BodyMarkdown != "tidy **this**" {
+		t.Fatalf("unexpected draft entry %+v", draft)
+	}
+}
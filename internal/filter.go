@@ -0,0 +1,283 @@
+package ghprcomments
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// maxListedPullRequests caps how many pull requests are offered per repository.
+const maxListedPullRequests = 200
+
+var relativeAgeRegex = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// PullRequestFilter narrows the pull requests listed for selection. The zero
+// value lists open pull requests, most recently updated first.
+type PullRequestFilter struct {
+	State           string // open (default), closed, merged or all
+	Author          string
+	ReviewRequested string
+	ReviewedBy      string
+	Assignee        string
+	Labels          []string
+	Base            string
+	UpdatedSince    time.Time
+}
+
+// ParsePullRequestState validates a user-supplied state. An empty value selects open.
+func ParsePullRequestState(value string) (string, error) {
+	state := strings.ToLower(strings.TrimSpace(value))
+	switch state {
+	case "":
+		return "open", nil
+	case "open", "closed", "merged", "all":
+		return state, nil
+	default:
+		return "", fmt.Errorf("invalid state %q (expected open, closed, merged or all)", value)
+	}
+}
+
+// ParseUpdatedSince accepts a date (2006-01-02), an RFC 3339 timestamp, or an
+// age such as 72h, 14d or 2w measured back from now.
+func ParseUpdatedSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if match := relativeAgeRegex.FindStringSubmatch(value); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		days := n
+		if match[2] == "w" {
+			days = n * 7
+		}
+		return now.AddDate(0, 0, -days), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if ts, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return ts, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid updated-since %q (expected a date, RFC 3339 timestamp or age like 14d)", value)
+}
+
+// IsDefault reports whether the filter selects every open pull request.
+func (f PullRequestFilter) IsDefault() bool {
+	return (f.State == "" || f.State == "open") && f.Base == "" && !f.needsSearch()
+}
+
+// needsSearch reports whether the filter goes beyond what the pull request
+// list endpoint supports.
+func (f PullRequestFilter) needsSearch() bool {
+	return f.State == "merged" || f.Author != "" || f.ReviewRequested != "" ||
+		f.ReviewedBy != "" || f.Assignee != "" || len(f.Labels) > 0 || !f.UpdatedSince.IsZero()
+}
+
+// searchQuery renders the filter as a GitHub issue search query, scoped to one
+// repository unless owner is empty.
+func (f PullRequestFilter) searchQuery(owner, repo string) string {
+	terms := []string{"is:pr"}
+	if owner != "" {
+		terms = []string{fmt.Sprintf("repo:%s/%s", owner, repo), "is:pr"}
+	}
+	switch f.State {
+	case "", "open":
+		terms = append(terms, "is:open")
+	case "closed":
+		terms = append(terms, "is:closed")
+	case "merged":
+		terms = append(terms, "is:merged")
+	}
+	if f.Author != "" {
+		terms = append(terms, "author:"+f.Author)
+	}
+	if f.ReviewRequested != "" {
+		terms = append(terms, "review-requested:"+f.ReviewRequested)
+	}
+	if f.ReviewedBy != "" {
+		terms = append(terms, "reviewed-by:"+f.ReviewedBy)
+	}
+	if f.Assignee != "" {
+		terms = append(terms, "assignee:"+f.Assignee)
+	}
+	for _, label := range f.Labels {
+		terms = append(terms, "label:"+strconv.Quote(label))
+	}
+	if f.Base != "" {
+		terms = append(terms, "base:"+f.Base)
+	}
+	if !f.UpdatedSince.IsZero() {
+		terms = append(terms, "updated:>="+f.UpdatedSince.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(terms, " ")
+}
+
+// ListPullRequestSummariesWithFilter returns the pull requests matching filter,
+// most recently updated first. Simple state and base filters use the pull
+// request list endpoint; anything else goes through the Search API.
+func (f *Fetcher) ListPullRequestSummariesWithFilter(ctx context.Context, owner, repo string, filter PullRequestFilter) ([]*PullRequestSummary, error) {
+	var summaries []*PullRequestSummary
+	var err error
+	if filter.needsSearch() {
+		summaries, err = f.searchPullRequests(ctx, owner, repo, filter)
+	} else {
+		summaries, err = f.listPullRequests(ctx, owner, repo, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return nil, ErrNoPullRequests
+	}
+	return summaries, nil
+}
+
+// SearchPullRequestSummaries runs filter as a search across every repository
+// the token can see, most recently updated first.
+func (f *Fetcher) SearchPullRequestSummaries(ctx context.Context, filter PullRequestFilter) ([]*PullRequestSummary, error) {
+	summaries, err := f.searchPullRequests(ctx, "", "", filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return nil, ErrNoPullRequests
+	}
+	return summaries, nil
+}
+
+func (f *Fetcher) listPullRequests(ctx context.Context, owner, repo string, filter PullRequestFilter) ([]*PullRequestSummary, error) {
+	state := filter.State
+	if state == "" {
+		state = "open"
+	}
+	opts := &github.PullRequestListOptions{
+		State:     state,
+		Base:      filter.Base,
+		Sort:      "updated",
+		Direction: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: 50,
+		},
+	}
+
+	var summaries []*PullRequestSummary
+
+	for {
+		prs, resp, err := f.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			summary := summarizePullRequest(pr)
+			if summary.RepoOwner == "" {
+				summary.RepoOwner = owner
+			}
+			if summary.RepoName == "" {
+				summary.RepoName = repo
+			}
+			summaries = append(summaries, summary)
+		}
+		if resp.NextPage == 0 || len(summaries) >= maxListedPullRequests {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return summaries, nil
+}
+
+func (f *Fetcher) searchPullRequests(ctx context.Context, owner, repo string, filter PullRequestFilter) ([]*PullRequestSummary, error) {
+	opts := &github.SearchOptions{
+		Sort:        "updated",
+		Order:       "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	query := filter.searchQuery(owner, repo)
+
+	var summaries []*PullRequestSummary
+
+	for {
+		result, resp, err := f.client.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("search pull requests: %w", err)
+		}
+		for _, issue := range result.Issues {
+			if !issue.IsPullRequest() {
+				continue
+			}
+			summary := summarizeIssue(issue)
+			if owner != "" {
+				summary.RepoOwner = owner
+				summary.RepoName = repo
+			}
+			summaries = append(summaries, summary)
+		}
+		if resp.NextPage == 0 || len(summaries) >= maxListedPullRequests {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return summaries, nil
+}
+
+// summarizeIssue converts a search hit into a summary. Search results do not
+// include branches, so HeadRef, HeadSHA and BaseRef stay empty until
+// Fetcher.FillBranches is called.
+func summarizeIssue(issue *github.Issue) *PullRequestSummary {
+	state := issue.GetState()
+	if links := issue.GetPullRequestLinks(); links != nil && links.MergedAt != nil {
+		state = "merged"
+	}
+
+	owner, repo := repositoryFromURL(issue.GetRepositoryURL())
+	summary := &PullRequestSummary{
+		RepoOwner: owner,
+		RepoName:  repo,
+		Number:    issue.GetNumber(),
+		Title:     issue.GetTitle(),
+		Author:    issue.GetUser().GetLogin(),
+		State:     state,
+		URL:       issue.GetHTMLURL(),
+		Labels:    labelNames(issue.Labels),
+	}
+	if issue.CreatedAt != nil {
+		summary.Created = issue.CreatedAt.Time
+	}
+	if issue.UpdatedAt != nil {
+		summary.Updated = issue.UpdatedAt.Time
+	}
+	return summary
+}
+
+// repositoryFromURL extracts owner and name from an API repository URL such
+// as https://api.github.com/repos/owner/repo.
+func repositoryFromURL(raw string) (string, string) {
+	_, path, found := strings.Cut(raw, "/repos/")
+	if !found {
+		return "", ""
+	}
+	owner, repo, _ := strings.Cut(strings.Trim(path, "/"), "/")
+	return owner, repo
+}
+
+func labelNames(labels []*github.Label) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if name := label.GetName(); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,178 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestParsePullRequestState(t *testing.T) {
+	for input, want := range map[string]string{"": "open", "Merged": "merged", " all ": "all", "closed": "closed"} {
+		got, err := ParsePullRequestState(input)
+		if err != nil || got != want {
+			t.Errorf("ParsePullRequestState(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParsePullRequestState("draft"); err == nil {
+		t.Error("expected error for unknown state")
+	}
+}
+
+func TestParseUpdatedSince(t *testing.T) {
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		input string
+		want  time.Time
+	}{
+		{input: "", want: time.Time{}},
+		{input: "14d", want: now.AddDate(0, 0, -14)},
+		{input: "2w", want: now.AddDate(0, 0, -14)},
+		{input: "36h", want: now.Add(-36 * time.Hour)},
+		{input: "2024-05-01T08:00:00Z", want: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+		{input: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tc := range testCases {
+		got, err := ParseUpdatedSince(tc.input, now)
+		if err != nil {
+			t.Errorf("ParseUpdatedSince(%q) failed: %v", tc.input, err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("ParseUpdatedSince(%q) = %s, want %s", tc.input, got, tc.want)
+		}
+	}
+	if _, err := ParseUpdatedSince("last tuesday", now); err == nil {
+		t.Error("expected error for unparseable value")
+	}
+}
+
+func TestPullRequestFilterSearchQuery(t *testing.T) {
+	filter := PullRequestFilter{
+		State:           "merged",
+		Author:          "octocat",
+		ReviewRequested: "@me",
+		Labels:          []string{"bug", "needs review"},
+		Base:            "main",
+		UpdatedSince:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	want := `repo:owner/repo is:pr is:merged author:octocat review-requested:@me label:"bug" label:"needs review" base:main updated:>=2024-01-02T00:00:00Z`
+	if got := filter.searchQuery("owner", "repo"); got != want {
+		t.Fatalf("unexpected query:\n got: %s\nwant: %s", got, want)
+	}
+	if !filter.needsSearch() || filter.IsDefault() {
+		t.Error("expected filter to require search")
+	}
+	if (PullRequestFilter{State: "closed", Base: "main"}).needsSearch() {
+		t.Error("state and base alone should use the list endpoint")
+	}
+	if !(PullRequestFilter{State: "open"}).IsDefault() {
+		t.Error("open state alone should be the default filter")
+	}
+}
+
+func TestListPullRequestSummariesWithFilter_List(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/pulls" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("state"); got != "closed" {
+			t.Errorf("expected state=closed, got %q", got)
+		}
+		if got := r.URL.Query().Get("base"); got != "release" {
+			t.Errorf("expected base=release, got %q", got)
+		}
+		merged := github.Timestamp{Time: time.Now()}
+		json.NewEncoder(w).Encode([]*github.PullRequest{
+			{Number: github.Int(4), State: github.String("closed"), MergedAt: &merged, Labels: []*github.Label{{Name: github.String("ship")}}},
+			{Number: github.Int(5), State: github.String("closed")},
+		})
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	prs, err := NewFetcher(client).ListPullRequestSummariesWithFilter(context.Background(), "owner", "repo", PullRequestFilter{State: "closed", Base: "release"})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(prs) != 2 || prs[0].State != "merged" || prs[1].State != "closed" {
+		t.Fatalf("unexpected summaries %+v", prs)
+	}
+	if len(prs[0].Labels) != 1 || prs[0].Labels[0] != "ship" {
+		t.Errorf("expected labels to be carried over, got %v", prs[0].Labels)
+	}
+}
+
+func TestListPullRequestSummariesWithFilter_Search(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/issues" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if got, want := r.URL.Query().Get("q"), `repo:owner/repo is:pr is:open label:"bug"`; got != want {
+			t.Errorf("unexpected query %q, want %q", got, want)
+		}
+		if got := r.URL.Query().Get("sort"); got != "updated" {
+			t.Errorf("expected sort=updated, got %q", got)
+		}
+		w.Write([]byte(`{"total_count":2,"items":[
+			{"number":7,"title":"Fix bug","state":"open","html_url":"https://github.com/owner/repo/pull/7","user":{"login":"alice"},"labels":[{"name":"bug"}],"pull_request":{"url":"x"}},
+			{"number":8,"title":"An issue","state":"open","user":{"login":"bob"}}
+		]}`))
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	prs, err := NewFetcher(client).ListPullRequestSummariesWithFilter(context.Background(), "owner", "repo", PullRequestFilter{Labels: []string{"bug"}})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(prs) != 1 {
+		t.Fatalf("expected plain issues to be skipped, got %+v", prs)
+	}
+	pr := prs[0]
+	if pr.Number != 7 || pr.Author != "alice" || pr.RepoOwner != "owner" || pr.RepoName != "repo" || pr.Labels[0] != "bug" {
+		t.Fatalf("unexpected summary %+v", pr)
+	}
+}
+
+func TestListPullRequestSummariesWithFilter_Empty(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total_count":0,"items":[]}`))
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	_, err := NewFetcher(client).ListPullRequestSummariesWithFilter(context.Background(), "owner", "repo", PullRequestFilter{Author: "nobody"})
+	if !errors.Is(err, ErrNoPullRequests) {
+		t.Fatalf("expected ErrNoPullRequests, got %v", err)
+	}
+}
+
+func TestSearchPullRequestSummaries(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("q"), "is:pr is:open author:me"; got != want {
+			t.Errorf("unexpected query %q, want %q", got, want)
+		}
+		w.Write([]byte(`{"total_count":1,"items":[
+			{"number":3,"title":"Elsewhere","state":"open","repository_url":"https://api.github.com/repos/acme/widgets","pull_request":{"url":"x"}}
+		]}`))
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	prs, err := NewFetcher(client).SearchPullRequestSummaries(context.Background(), PullRequestFilter{Author: "me"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(prs) != 1 || prs[0].RepoOwner != "acme" || prs[0].RepoName != "widgets" {
+		t.Fatalf("expected repository to be derived from repository_url, got %+v", prs)
+	}
+}
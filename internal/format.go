@@ -0,0 +1,178 @@
+package ghprcomments
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Formatter renders an Output in a particular format.
+type Formatter interface {
+	Format(w io.Writer, out Output) error
+}
+
+// FormatterFunc adapts a plain function to the Formatter interface.
+type FormatterFunc func(w io.Writer, out Output) error
+
+// Format implements Formatter.
+func (f FormatterFunc) Format(w io.Writer, out Output) error {
+	return f(w, out)
+}
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = make(map[string]Formatter)
+)
+
+func init() {
+	RegisterFormatter("json", FormatterFunc(formatJSON))
+	RegisterFormatter("flat", FormatterFunc(formatFlatJSON))
+	RegisterFormatter("ndjson", FormatterFunc(formatNDJSON))
+	RegisterFormatter("csv", FormatterFunc(formatCSV))
+	RegisterFormatter("markdown", FormatterFunc(formatMarkdown))
+	RegisterFormatter("html", FormatterFunc(formatHTML))
+}
+
+// RegisterFormatter makes a formatter available under name for --format.
+// It panics if name is empty, f is nil, or name is already registered, so
+// mistakes surface at start-up.
+func RegisterFormatter(name string, f Formatter) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		panic("ghprcomments: RegisterFormatter with empty name")
+	}
+	if f == nil {
+		panic("ghprcomments: RegisterFormatter formatter is nil for " + name)
+	}
+
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	if _, dup := formatters[name]; dup {
+		panic("ghprcomments: RegisterFormatter called twice for " + name)
+	}
+	formatters[name] = f
+}
+
+// LookupFormatter returns the formatter registered under name.
+func LookupFormatter(name string) (Formatter, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	formattersMu.RLock()
+	f, ok := formatters[key]
+	formattersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(FormatterNames(), ", "))
+	}
+	return f, nil
+}
+
+// FormatterNames lists the registered format names in alphabetical order.
+func FormatterNames() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func formatJSON(w io.Writer, out Output) error {
+	return writeJSONPayload(w, out, false)
+}
+
+func formatFlatJSON(w io.Writer, out Output) error {
+	return writeJSONPayload(w, out, true)
+}
+
+func writeJSONPayload(w io.Writer, out Output, flat bool) error {
+	payload, err := MarshalJSON(out, flat)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(payload, '\n'))
+	return err
+}
+
+// formatNDJSON writes one comment per line, oldest first, so consumers can
+// process the stream incrementally.
+func formatNDJSON(w io.Writer, out Output) error {
+	comments := outputComments(out)
+	enc := json.NewEncoder(w)
+	for i := len(comments) - 1; i >= 0; i-- {
+		if err := enc.Encode(comments[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var csvHeader = []string{
+	"repo", "pr", "type", "author", "created_at", "path", "line", "state",
+	"resolved", "outdated", "body_text", "permalink",
+}
+
+func formatCSV(w io.Writer, out Output) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, c := range outputComments(out) {
+		line := ""
+		if c.Line != nil {
+			line = strconv.Itoa(*c.Line)
+		}
+		created := ""
+		if !c.CreatedAt.IsZero() {
+			created = c.CreatedAt.Format(time.RFC3339)
+		}
+		record := []string{
+			out.PR.Repo,
+			strconv.Itoa(out.PR.Number),
+			c.Type,
+			csvText(c.Author),
+			created,
+			csvText(c.Path),
+			line,
+			c.State,
+			optionalBool(c.Resolved),
+			optionalBool(c.Outdated),
+			csvText(c.BodyText),
+			c.Permalink,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText neutralises cells a spreadsheet would run as a formula. Comment
+// bodies, logins and paths come from other people, so a leading =, +, -, @,
+// tab or carriage return is escaped with a quote.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatMarkdown(w io.Writer, out Output) error {
+	_, err := fmt.Fprintln(w, RenderMarkdown(out))
+	return err
+}
+
+func optionalBool(v *bool) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatBool(*v)
+}
+
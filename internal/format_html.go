@@ -0,0 +1,171 @@
+package ghprcomments
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// htmlReport is the view model behind the static HTML report.
+type htmlReport struct {
+	PR           PullRequestMetadata
+	CommentCount int
+	Generated    string
+	Authors      []htmlSection
+	Files        []htmlSection
+}
+
+type htmlSection struct {
+	Title    string
+	Anchor   string
+	Comments []htmlComment
+}
+
+type htmlComment struct {
+	Heading   string
+	Anchor    string
+	Location  string
+	Timestamp string
+	Body      string
+	Permalink string
+	DiffHunk  string
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{if .PR.Title}}{{.PR.Title}}{{else}}PR #{{.PR.Number}}{{end}} — review comments</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;max-width:960px;margin:2rem auto;padding:0 1rem;color:#1f2328;line-height:1.5}
+a{color:#0969da}
+nav ul{columns:2;padding-left:1.2rem}
+section{margin-top:2rem}
+article{border:1px solid #d0d7de;border-radius:6px;padding:.75rem 1rem;margin:1rem 0}
+article h4{margin:0 0 .25rem}
+.meta{color:#59636e;font-size:.875rem}
+.body{white-space:pre-wrap;margin:.5rem 0 0}
+pre.diff{background:#f6f8fa;padding:.5rem;overflow-x:auto;font-size:.8rem}
+</style>
+</head>
+<body>
+<header>
+<h1>{{if .PR.Title}}{{.PR.Title}}{{else}}PR #{{.PR.Number}}{{end}}</h1>
+<p class="meta">{{.PR.Repo}} #{{.PR.Number}}{{if .PR.URL}} · <a href="{{.PR.URL}}">{{.PR.URL}}</a>{{end}}{{if .PR.HeadRef}} · {{.PR.HeadRef}} → {{.PR.BaseRef}}{{end}} · {{.CommentCount}} comments · generated {{.Generated}}</p>
+</header>
+<nav>
+<h2>Authors</h2>
+<ul>{{range .Authors}}<li><a href="#{{.Anchor}}">{{.Title}}</a> ({{len .Comments}})</li>{{end}}</ul>
+{{if .Files}}<h2>Files</h2>
+<ul>{{range .Files}}<li><a href="#{{.Anchor}}">{{.Title}}</a> ({{len .Comments}})</li>{{end}}</ul>{{end}}
+</nav>
+{{range .Authors}}<section id="{{.Anchor}}">
+<h2>{{.Title}}</h2>
+{{range .Comments}}{{template "comment" .}}{{end}}</section>
+{{end}}{{range .Files}}<section id="{{.Anchor}}">
+<h2>{{.Title}}</h2>
+{{range .Comments}}{{template "comment" .}}{{end}}</section>
+{{end}}</body>
+</html>
+{{define "comment"}}<article>
+<h4>{{.Heading}}</h4>
+<p class="meta">{{.Timestamp}}{{if .Location}} · {{.Location}}{{end}}{{if .Permalink}} · <a href="{{.Permalink}}">view on GitHub</a>{{end}}</p>
+{{if .DiffHunk}}<pre class="diff">{{.DiffHunk}}</pre>
+{{end}}<div class="body">{{.Body}}</div>
+</article>
+{{end}}`))
+
+// formatHTML writes a self-contained report with a section per author and per
+// file, each reachable through a stable anchor.
+func formatHTML(w io.Writer, out Output) error {
+	comments := outputComments(out)
+	report := htmlReport{
+		PR:           out.PR,
+		CommentCount: out.CommentCount,
+		Generated:    time.Now().UTC().Format(time.RFC3339),
+	}
+
+	byAuthor := make(map[string][]htmlComment)
+	byFile := make(map[string][]htmlComment)
+	for _, c := range comments {
+		byAuthor[c.Author] = append(byAuthor[c.Author], newHTMLComment(c, formatCommentType(c.Type)))
+		if c.Path != "" {
+			byFile[c.Path] = append(byFile[c.Path], newHTMLComment(c, formatCommentType(c.Type)+" by "+c.Author))
+		}
+	}
+
+	report.Authors = htmlSections(byAuthor, "author-")
+	report.Files = htmlSections(byFile, "file-")
+	return htmlReportTemplate.Execute(w, report)
+}
+
+func newHTMLComment(c Comment, heading string) htmlComment {
+	hc := htmlComment{
+		Heading:   heading,
+		Body:      c.BodyMarkdown,
+		Permalink: c.Permalink,
+		DiffHunk:  c.DiffHunk,
+		Timestamp: "(unknown time)",
+	}
+	if hc.Body == "" {
+		hc.Body = c.BodyText
+	}
+	if !c.CreatedAt.IsZero() {
+		hc.Timestamp = c.CreatedAt.Format(time.RFC3339)
+	}
+	if c.Path != "" {
+		hc.Location = c.Path
+		if c.Line != nil {
+			hc.Location = fmt.Sprintf("%s:%d", c.Path, *c.Line)
+		}
+	}
+	return hc
+}
+
+func htmlSections(groups map[string][]htmlComment, prefix string) []htmlSection {
+	titles := make([]string, 0, len(groups))
+	for title := range groups {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+
+	used := make(map[string]int, len(titles))
+	sections := make([]htmlSection, 0, len(titles))
+	for _, title := range titles {
+		anchor := prefix + htmlAnchor(title)
+		// Distinct titles can slugify alike (a/b.go and a-b.go); keep anchors unique.
+		if n := used[anchor]; n > 0 {
+			used[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, n+1)
+		} else {
+			used[anchor] = 1
+		}
+		sections = append(sections, htmlSection{Title: title, Anchor: anchor, Comments: groups[title]})
+	}
+	return sections
+}
+
+func htmlAnchor(value string) string {
+	var b strings.Builder
+	prevHyphen := false
+	for _, r := range strings.ToLower(value) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			prevHyphen = false
+		default:
+			if !prevHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+				prevHyphen = true
+			}
+		}
+	}
+	anchor := strings.Trim(b.String(), "-")
+	if anchor == "" {
+		return "section"
+	}
+	return anchor
+}
@@ -0,0 +1,177 @@
+package ghprcomments
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleFormatOutput() Output {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	line := 12
+	resolved := true
+	return Output{
+		PR:           PullRequestMetadata{Repo: "owner/repo", Number: 7, Title: "Add <widgets>"},
+		CommentCount: 3,
+		Comments: []AuthorComments{
+			{Author: "alice", Comments: []Comment{
+				{Type: "review_comment", Author: "alice", CreatedAt: base.Add(2 * time.Hour), Path: "pkg/a.go", Line: &line, Resolved: &resolved, BodyText: "nit, rename", BodyMarkdown: "nit, rename"},
+				{Type: "issue", Author: "alice", CreatedAt: base, BodyText: "looks good", BodyMarkdown: "looks <b>good</b>"},
+			}},
+			{Author: "bob", Comments: []Comment{
+				{Type: "review_comment", Author: "bob", CreatedAt: base.Add(time.Hour), Path: "pkg/a.go", BodyText: "why?", BodyMarkdown: "why?"},
+			}},
+		},
+	}
+}
+
+func TestLookupFormatter(t *testing.T) {
+	for _, name := range []string{"json", "flat", "ndjson", "csv", "markdown", "html", " CSV "} {
+		if _, err := LookupFormatter(name); err != nil {
+			t.Fatalf("LookupFormatter(%q) error = %v", name, err)
+		}
+	}
+	_, err := LookupFormatter("yaml")
+	if err == nil || !strings.Contains(err.Error(), "available: csv, flat, html, json, markdown, ndjson") {
+		t.Fatalf("LookupFormatter(yaml) error = %v", err)
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	RegisterFormatter("test-count", FormatterFunc(func(w io.Writer, out Output) error {
+		_, err := io.WriteString(w, "count")
+		return err
+	}))
+	t.Cleanup(func() {
+		formattersMu.Lock()
+		delete(formatters, "test-count")
+		formattersMu.Unlock()
+	})
+
+	f, err := LookupFormatter("test-count")
+	if err != nil {
+		t.Fatalf("lookup registered formatter: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, Output{}); err != nil || buf.String() != "count" {
+		t.Fatalf("Format() = %q, %v", buf.String(), err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected duplicate registration to panic")
+		}
+	}()
+	RegisterFormatter("json", FormatterFunc(formatJSON))
+}
+
+func TestFormatNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := formatNDJSON(&buf, sampleFormatOutput()); err != nil {
+		t.Fatalf("formatNDJSON: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	want := []string{"looks good", "why?", "nit, rename"}
+	for i, raw := range lines {
+		var c Comment
+		if err := json.Unmarshal([]byte(raw), &c); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		if c.BodyText != want[i] {
+			t.Fatalf("line %d body = %q, want %q", i, c.BodyText, want[i])
+		}
+	}
+}
+
+func TestFormatCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := formatCSV(&buf, sampleFormatOutput()); err != nil {
+		t.Fatalf("formatCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected header plus 3 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		t.Fatalf("unexpected header %v", records[0])
+	}
+	first := records[1]
+	if first[0] != "owner/repo" || first[1] != "7" || first[5] != "pkg/a.go" || first[6] != "12" || first[8] != "true" || first[10] != "nit, rename" {
+		t.Fatalf("unexpected first row %v", first)
+	}
+}
+
+func TestFormatCSVEscapesFormulas(t *testing.T) {
+	out := Output{PR: PullRequestMetadata{Repo: "owner/repo", Number: 7}, Comments: groupByAuthor([]Comment{
+		{Type: "issue", Author: "@mallory", Path: "-x.go", BodyText: `=HYPERLINK("http://evil","click")`},
+		{Type: "issue", Author: "alice", BodyText: "+1 looks good - ship it"},
+		{Type: "issue", Author: "bob", BodyText: "plain"},
+	})}
+
+	var buf bytes.Buffer
+	if err := formatCSV(&buf, out); err != nil {
+		t.Fatalf("formatCSV: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+
+	rows := make(map[string][]string)
+	for _, record := range records[1:] {
+		rows[record[3]] = record
+	}
+	if row := rows["'@mallory"]; row == nil || row[5] != "'-x.go" || row[10] != `'=HYPERLINK("http://evil","click")` {
+		t.Fatalf("expected author, path and body to be escaped, got %v", records)
+	}
+	if row := rows["alice"]; row == nil || row[10] != "'+1 looks good - ship it" {
+		t.Fatalf("expected a leading + to be escaped, got %v", rows["alice"])
+	}
+	if row := rows["bob"]; row == nil || row[10] != "plain" {
+		t.Fatalf("expected plain text untouched, got %v", rows["bob"])
+	}
+}
+
+func TestFormatHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := formatHTML(&buf, sampleFormatOutput()); err != nil {
+		t.Fatalf("formatHTML: %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		`<title>Add &lt;widgets&gt; — review comments</title>`,
+		`<a href="#author-alice">alice</a>`,
+		`<section id="author-bob">`,
+		`<a href="#file-pkg-a-go">pkg/a.go</a> (2)`,
+		`<section id="file-pkg-a-go">`,
+		`looks &lt;b&gt;good&lt;/b&gt;`,
+		`pkg/a.go:12`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("HTML report missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<b>good</b>") {
+		t.Fatal("comment body was not escaped")
+	}
+}
+
+func TestHTMLSectionsUniqueAnchors(t *testing.T) {
+	sections := htmlSections(map[string][]htmlComment{"a/b.go": nil, "a-b.go": nil}, "file-")
+	if sections[0].Anchor == sections[1].Anchor {
+		t.Fatalf("expected distinct anchors, got %q twice", sections[0].Anchor)
+	}
+}
@@ -0,0 +1,141 @@
+package ghprcomments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ReviewThreadState records the resolution state GitHub tracks for a review thread.
+// The REST API does not expose it, so it is fetched through GraphQL.
+type ReviewThreadState struct {
+	NodeID     string
+	IsResolved bool
+	IsOutdated bool
+	// CommentIDs holds the first 100 comments; replies beyond that are
+	// matched through their in_reply_to_id.
+	CommentIDs []int64
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+type graphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+// graphQL posts a query to the GraphQL endpoint that pairs with the REST client's base URL
+// and decodes the data member into out.
+func (f *Fetcher) graphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	endpoint := "graphql"
+	if strings.HasSuffix(f.client.BaseURL.Path, "/api/v3/") {
+		endpoint = "../graphql"
+	}
+
+	req, err := f.client.NewRequest(http.MethodPost, endpoint, graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	resp := graphQLResponse{Data: out}
+	if _, err := f.client.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+const reviewThreadsQuery = `query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          id
+          isResolved
+          isOutdated
+          comments(first: 100) { nodes { databaseId } }
+        }
+      }
+    }
+  }
+}`
+
+type reviewThreadsData struct {
+	Repository *struct {
+		PullRequest *struct {
+			ReviewThreads struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []struct {
+					ID         string `json:"id"`
+					IsResolved bool   `json:"isResolved"`
+					IsOutdated bool   `json:"isOutdated"`
+					Comments   struct {
+						Nodes []struct {
+							DatabaseID int64 `json:"databaseId"`
+						} `json:"nodes"`
+					} `json:"comments"`
+				} `json:"nodes"`
+			} `json:"reviewThreads"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+// WithThreads returns a Fetcher whose FetchComments also retrieves review
+// thread state over GraphQL, giving review comments their thread_id and
+// resolved and outdated flags. Callers that filter, group or resolve by
+// thread need it; the others save the extra request.
+func (f *Fetcher) WithThreads() *Fetcher {
+	clone := *f
+	clone.threads = true
+	return &clone
+}
+
+// FetchReviewThreads retrieves resolution state for every review thread on the pull request.
+func (f *Fetcher) FetchReviewThreads(ctx context.Context, owner, repo string, number int) ([]ReviewThreadState, error) {
+	vars := map[string]any{"owner": owner, "name": repo, "number": number}
+	var threads []ReviewThreadState
+	for {
+		var data reviewThreadsData
+		if err := f.graphQL(ctx, reviewThreadsQuery, vars, &data); err != nil {
+			return nil, err
+		}
+		if data.Repository == nil || data.Repository.PullRequest == nil {
+			return nil, errors.New("graphql: pull request not found")
+		}
+		page := data.Repository.PullRequest.ReviewThreads
+		for _, node := range page.Nodes {
+			state := ReviewThreadState{
+				NodeID:     node.ID,
+				IsResolved: node.IsResolved,
+				IsOutdated: node.IsOutdated,
+			}
+			for _, c := range node.Comments.Nodes {
+				state.CommentIDs = append(state.CommentIDs, c.DatabaseID)
+			}
+			threads = append(threads, state)
+		}
+		if !page.PageInfo.HasNextPage {
+			return threads, nil
+		}
+		vars["cursor"] = page.PageInfo.EndCursor
+	}
+}
@@ -0,0 +1,188 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestFetchReviewThreadsPaginates(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if req.Variables["cursor"] == nil {
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[{"id":"T_1","isResolved":true,"isOutdated":false,"comments":{"nodes":[{"databaseId":1},{"databaseId":2}]}}]}}}}}`))
+			return
+		}
+		if req.Variables["cursor"] != "c1" {
+			t.Errorf("expected cursor c1, got %v", req.Variables["cursor"])
+		}
+		w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[{"id":"T_2","isResolved":false,"isOutdated":true,"comments":{"nodes":[{"databaseId":3}]}}]}}}}}`))
+	}
+
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	threads, err := NewFetcher(client).FetchReviewThreads(ctx, "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("FetchReviewThreads failed: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 GraphQL calls, got %d", calls)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("expected 2 threads, got %d", len(threads))
+	}
+	if threads[0].NodeID != "T_1" || !threads[0].IsResolved || len(threads[0].CommentIDs) != 2 {
+		t.Errorf("unexpected first thread: %+v", threads[0])
+	}
+	if threads[1].NodeID != "T_2" || threads[1].IsResolved || !threads[1].IsOutdated {
+		t.Errorf("unexpected second thread: %+v", threads[1])
+	}
+}
+
+func TestFetchReviewThreadsSurfacesGraphQLErrors(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":null,"errors":[{"message":"Could not resolve to a Repository"}]}`))
+	}
+
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	if _, err := NewFetcher(client).FetchReviewThreads(context.Background(), "owner", "repo", 1); err == nil {
+		t.Fatal("expected GraphQL error to be returned")
+	}
+}
+
+func TestBuildOutputUnresolvedOnly(t *testing.T) {
+	createdAt := &github.Timestamp{Time: time.Date(2025, time.November, 3, 9, 0, 0, 0, time.UTC)}
+	payload := commentPayload{
+		issueComments: []*github.IssueComment{
+			{ID: github.Int64(1), Body: github.String("general"), CreatedAt: createdAt},
+		},
+		reviewComments: []*github.PullRequestComment{
+			{ID: github.Int64(10), Body: github.String("fixed"), Path: github.String("a.go"), CreatedAt: createdAt},
+			{ID: github.Int64(20), Body: github.String("still open"), Path: github.String("b.go"), CreatedAt: createdAt},
+		},
+		threads: []ReviewThreadState{
+			{NodeID: "T_10", IsResolved: true, CommentIDs: []int64{10}},
+			{NodeID: "T_20", IsOutdated: true, CommentIDs: []int64{20}},
+		},
+	}
+	pr := &PullRequestSummary{Number: 1}
+
+	all := BuildOutput(pr, payload, NormalizationOptions{})
+	flat := flattenCommentGroups(all.Comments)
+	for _, c := range flat {
+		if c.ID == 10 && (c.Resolved == nil || !*c.Resolved) {
+			t.Fatalf("expected comment 10 to be marked resolved")
+		}
+		if c.ID == 20 && (c.Outdated == nil || !*c.Outdated || c.ThreadID != "T_20") {
+			t.Fatalf("expected comment 20 to be marked outdated on T_20, got %+v", c)
+		}
+		if c.ID == 1 && c.Resolved != nil {
+			t.Fatalf("issue comments carry no resolution state")
+		}
+	}
+
+	filtered := BuildOutput(pr, payload, NormalizationOptions{UnresolvedOnly: true})
+	if filtered.CommentCount != 2 {
+		t.Fatalf("expected resolved thread to be dropped, got %d comments", filtered.CommentCount)
+	}
+	for _, c := range flattenCommentGroups(filtered.Comments) {
+		if c.ID == 10 {
+			t.Fatal("resolved comment should have been filtered out")
+		}
+	}
+}
+
+func TestBuildOutputInheritsThreadStateForLateReplies(t *testing.T) {
+	createdAt := &github.Timestamp{Time: time.Date(2025, time.November, 3, 9, 0, 0, 0, time.UTC)}
+	payload := commentPayload{
+		reviewComments: []*github.PullRequestComment{
+			{ID: github.Int64(10), Body: github.String("root"), Path: github.String("a.go"), CreatedAt: createdAt},
+			{ID: github.Int64(250), InReplyTo: github.Int64(10), Body: github.String("reply 101"), Path: github.String("a.go"), CreatedAt: createdAt},
+		},
+		threads: []ReviewThreadState{{NodeID: "T_10", IsResolved: true, CommentIDs: []int64{10}}},
+	}
+
+	out := BuildOutput(&PullRequestSummary{Number: 1}, payload, NormalizationOptions{UnresolvedOnly: true})
+	if out.CommentCount != 0 {
+		t.Fatalf("expected the late reply to follow its resolved root, got %+v", flattenCommentGroups(out.Comments))
+	}
+}
+
+func TestFetchCommentsDegradesWithoutGraphQL(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/graphql":
+			http.Error(w, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+		case "/repos/owner/repo/issues/1/comments":
+			w.Write([]byte(`[{"id":1,"body":"hello","user":{"login":"alice"}}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}
+
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	payload, err := NewFetcher(client).WithThreads().FetchComments(context.Background(), "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("FetchComments failed: %v", err)
+	}
+	if len(payload.issueComments) != 1 {
+		t.Fatalf("expected the REST comments to be kept, got %d", len(payload.issueComments))
+	}
+	if len(payload.Warnings()) != 1 {
+		t.Fatalf("expected one warning, got %v", payload.Warnings())
+	}
+}
+
+func TestFetchCommentsSkipsThreadsUnlessAsked(t *testing.T) {
+	var graphQLCalls int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/graphql" {
+			graphQLCalls++
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}}}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}
+
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	fetcher := NewFetcher(client)
+	if _, err := fetcher.FetchComments(context.Background(), "owner", "repo", 1); err != nil {
+		t.Fatalf("FetchComments failed: %v", err)
+	}
+	if graphQLCalls != 0 {
+		t.Fatalf("expected no thread query without WithThreads, got %d", graphQLCalls)
+	}
+	if _, err := fetcher.WithThreads().FetchComments(context.Background(), "owner", "repo", 1); err != nil {
+		t.Fatalf("FetchComments failed: %v", err)
+	}
+	if graphQLCalls != 1 {
+		t.Fatalf("expected one thread query with WithThreads, got %d", graphQLCalls)
+	}
+}
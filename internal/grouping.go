@@ -0,0 +1,193 @@
+package ghprcomments
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GroupBy selects how BuildOutput nests comments.
+type GroupBy string
+
+const (
+	// GroupByAuthor nests comments under their author, most recently active first.
+	GroupByAuthor GroupBy = "author"
+	// GroupByFile nests comments under their file path, in path and line order.
+	GroupByFile GroupBy = "file"
+	// GroupByType nests comments under their comment type.
+	GroupByType GroupBy = "type"
+	// GroupByThread nests review replies under the comment that started the thread.
+	GroupByThread GroupBy = "thread"
+	// GroupByNone lists every comment in one chronological timeline, oldest first.
+	GroupByNone GroupBy = "none"
+)
+
+// ParseGroupBy validates a --group-by value; empty means author.
+func ParseGroupBy(value string) (GroupBy, error) {
+	switch by := GroupBy(strings.ToLower(strings.TrimSpace(value))); by {
+	case "":
+		return GroupByAuthor, nil
+	case GroupByAuthor, GroupByFile, GroupByType, GroupByThread, GroupByNone:
+		return by, nil
+	default:
+		return "", fmt.Errorf("unknown group-by %q (want author, file, type, thread or none)", value)
+	}
+}
+
+// FileComments groups comments left on one file. An empty path collects
+// conversation-level comments and sorts last.
+type FileComments struct {
+	Path     string    `json:"path"`
+	Comments []Comment `json:"comments"`
+}
+
+// TypeComments groups comments of one type.
+type TypeComments struct {
+	Type     string    `json:"type"`
+	Comments []Comment `json:"comments"`
+}
+
+// groupOutput fills the Output field matching by and clears the others.
+func groupOutput(out Output, all []Comment, by GroupBy) Output {
+	// "comments" is always present for consumers of the original contract;
+	// other groupings leave it empty and fill their own key alongside it.
+	out.Comments, out.Threads, out.Files, out.Types, out.Timeline = []AuthorComments{}, nil, nil, nil, nil
+	switch by {
+	case GroupByThread:
+		out.Threads = buildThreads(all)
+	case GroupByFile:
+		out.Files = groupByFile(all)
+	case GroupByType:
+		out.Types = groupByType(all)
+	case GroupByNone:
+		out.Timeline = chronological(all)
+	default:
+		out.Comments = groupByAuthor(all)
+	}
+	return out
+}
+
+// outputGroupBy infers the grouping an Output was built with.
+func outputGroupBy(out Output) GroupBy {
+	switch {
+	case len(out.Threads) > 0:
+		return GroupByThread
+	case len(out.Files) > 0:
+		return GroupByFile
+	case len(out.Types) > 0:
+		return GroupByType
+	case len(out.Timeline) > 0:
+		return GroupByNone
+	default:
+		return GroupByAuthor
+	}
+}
+
+// regroupOutput rebuilds out's nesting from all, keeping its grouping.
+func regroupOutput(out Output, all []Comment) Output {
+	return groupOutput(out, all, outputGroupBy(out))
+}
+
+// outputComments flattens any grouping into a single list, newest first.
+func outputComments(out Output) []Comment {
+	switch outputGroupBy(out) {
+	case GroupByThread:
+		return flattenThreads(out.Threads)
+	case GroupByFile:
+		var all []Comment
+		for _, group := range out.Files {
+			all = append(all, group.Comments...)
+		}
+		return newestFirst(all)
+	case GroupByType:
+		var all []Comment
+		for _, group := range out.Types {
+			all = append(all, group.Comments...)
+		}
+		return newestFirst(all)
+	case GroupByNone:
+		return newestFirst(append([]Comment(nil), out.Timeline...))
+	default:
+		return flattenCommentGroups(out.Comments)
+	}
+}
+
+func groupByFile(all []Comment) []FileComments {
+	grouped := make(map[string][]Comment)
+	for _, c := range all {
+		grouped[c.Path] = append(grouped[c.Path], c)
+	}
+
+	files := make([]FileComments, 0, len(grouped))
+	for path, comments := range grouped {
+		sort.SliceStable(comments, func(i, j int) bool {
+			li, lj := commentLine(comments[i]), commentLine(comments[j])
+			if li != lj {
+				// Comments without a line (file-level) lead.
+				return li < lj
+			}
+			if comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+				return comments[i].ID < comments[j].ID
+			}
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		})
+		files = append(files, FileComments{Path: path, Comments: comments})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if (files[i].Path == "") != (files[j].Path == "") {
+			return files[j].Path == ""
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+func groupByType(all []Comment) []TypeComments {
+	grouped := make(map[string][]Comment)
+	for _, c := range all {
+		grouped[c.Type] = append(grouped[c.Type], c)
+	}
+
+	types := make([]TypeComments, 0, len(grouped))
+	for kind, comments := range grouped {
+		types = append(types, TypeComments{Type: kind, Comments: newestFirst(comments)})
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+// commentLine is the line a comment points at, or 0 for file-level and
+// conversation comments.
+func commentLine(c Comment) int {
+	switch {
+	case c.Line != nil:
+		return *c.Line
+	case c.OriginalLine != nil:
+		return *c.OriginalLine
+	default:
+		return 0
+	}
+}
+
+func chronological(all []Comment) []Comment {
+	sorted := append([]Comment(nil), all...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+	return sorted
+}
+
+func newestFirst(all []Comment) []Comment {
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].ID > all[j].ID
+		}
+		return all[i].CreatedAt.After(all[j].CreatedAt)
+	})
+	return all
+}
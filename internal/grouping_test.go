@@ -0,0 +1,110 @@
+package ghprcomments
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func groupingFixture() []Comment {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	line := func(n int) *int { return &n }
+	return []Comment{
+		{Type: "issue", ID: 1, Author: "alice", CreatedAt: base, BodyText: "overall looks good"},
+		{Type: "review_comment", ID: 2, Author: "bob", CreatedAt: base.Add(time.Hour), Path: "b.go", Line: line(20), BodyText: "rename"},
+		{Type: "review_comment", ID: 3, Author: "alice", CreatedAt: base.Add(2 * time.Hour), Path: "b.go", Line: line(5), BodyText: "typo"},
+		{Type: "review_comment", ID: 4, Author: "carol", CreatedAt: base.Add(3 * time.Hour), Path: "a.go", Line: line(1), BodyText: "nit"},
+		{Type: "review", ID: 5, Author: "bob", CreatedAt: base.Add(4 * time.Hour), State: "APPROVED"},
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	for _, value := range []string{"", "author", "File", " type ", "thread", "none"} {
+		if _, err := ParseGroupBy(value); err != nil {
+			t.Fatalf("ParseGroupBy(%q) error = %v", value, err)
+		}
+	}
+	if by, _ := ParseGroupBy(""); by != GroupByAuthor {
+		t.Fatalf("empty group-by = %q, want author", by)
+	}
+	if _, err := ParseGroupBy("reviewer"); err == nil {
+		t.Fatal("expected an error for an unknown grouping")
+	}
+}
+
+func TestGroupOutputByFile(t *testing.T) {
+	out := groupOutput(Output{}, groupingFixture(), GroupByFile)
+	if len(out.Comments) != 0 || len(out.Files) != 3 {
+		t.Fatalf("unexpected grouping: %+v", out)
+	}
+
+	var paths []string
+	for _, group := range out.Files {
+		paths = append(paths, group.Path)
+	}
+	if got := strings.Join(paths, ","); got != "a.go,b.go," {
+		t.Fatalf("paths = %q, want a.go,b.go then conversation", got)
+	}
+	if b := out.Files[1].Comments; b[0].ID != 3 || b[1].ID != 2 {
+		t.Fatalf("expected b.go comments in line order, got %d then %d", b[0].ID, b[1].ID)
+	}
+	if conv := out.Files[2].Comments; conv[0].ID != 1 || conv[1].ID != 5 {
+		t.Fatalf("expected conversation comments oldest first, got %+v", conv)
+	}
+}
+
+func TestGroupOutputByTypeAndNone(t *testing.T) {
+	byType := groupOutput(Output{}, groupingFixture(), GroupByType)
+	var types []string
+	for _, group := range byType.Types {
+		types = append(types, group.Type)
+	}
+	if got := strings.Join(types, ","); got != "issue,review,review_comment" {
+		t.Fatalf("types = %q", got)
+	}
+	if rc := byType.Types[2].Comments; rc[0].ID != 4 {
+		t.Fatalf("expected newest review comment first, got %d", rc[0].ID)
+	}
+
+	none := groupOutput(Output{}, groupingFixture(), GroupByNone)
+	for i, c := range none.Timeline {
+		if c.ID != int64(i+1) {
+			t.Fatalf("timeline[%d] = %d, want chronological order", i, c.ID)
+		}
+	}
+}
+
+func TestOutputCommentsAcrossGroupings(t *testing.T) {
+	for _, by := range []GroupBy{GroupByAuthor, GroupByFile, GroupByType, GroupByThread, GroupByNone} {
+		out := groupOutput(Output{}, groupingFixture(), by)
+		if got := outputGroupBy(out); got != by {
+			t.Fatalf("outputGroupBy = %q, want %q", got, by)
+		}
+		flat := outputComments(out)
+		if len(flat) != 5 || flat[0].ID != 5 || flat[4].ID != 1 {
+			t.Fatalf("%s: expected all comments newest first, got %+v", by, flat)
+		}
+	}
+}
+
+func TestRenderMarkdownGroupings(t *testing.T) {
+	byFile := RenderMarkdown(groupOutput(Output{PR: PullRequestMetadata{Number: 7}}, groupingFixture(), GroupByFile))
+	for _, want := range []string{"## a.go\n", "## b.go\n", "## Conversation\n", "### Review Comment by carol"} {
+		if !strings.Contains(byFile, want) {
+			t.Fatalf("file markdown missing %q:\n%s", want, byFile)
+		}
+	}
+	if strings.Index(byFile, "## a.go") > strings.Index(byFile, "## Conversation") {
+		t.Fatal("conversation comments should come after file sections")
+	}
+
+	byType := RenderMarkdown(groupOutput(Output{PR: PullRequestMetadata{Number: 7}}, groupingFixture(), GroupByType))
+	if !strings.Contains(byType, "## Review Comment\n") || !strings.Contains(byType, "### carol") {
+		t.Fatalf("type markdown missing headings:\n%s", byType)
+	}
+
+	none := RenderMarkdown(groupOutput(Output{PR: PullRequestMetadata{Number: 7}}, groupingFixture(), GroupByNone))
+	if !strings.Contains(none, "## Timeline\n") {
+		t.Fatalf("ungrouped markdown missing timeline heading:\n%s", none)
+	}
+}
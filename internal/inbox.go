@@ -0,0 +1,142 @@
+package ghprcomments
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Inbox is a single feed of feedback left by other people on a user's pull requests.
+type Inbox struct {
+	User         string       `json:"user"`
+	PRCount      int          `json:"pr_count"`
+	CommentCount int          `json:"comment_count"`
+	PullRequests []InboxEntry `json:"pull_requests"`
+	Warnings     []string     `json:"warnings,omitempty"`
+}
+
+// InboxEntry holds the feedback on one pull request, newest first.
+type InboxEntry struct {
+	PR             PullRequestMetadata `json:"pr"`
+	LatestActivity time.Time           `json:"latest_activity"`
+	CommentCount   int                 `json:"comment_count"`
+	Comments       []Comment           `json:"comments"`
+}
+
+// InboxOptions configures how the inbox is collected.
+type InboxOptions struct {
+	Normalization NormalizationOptions
+	// Concurrency bounds parallel comment fetches; values below 1 mean 1.
+	Concurrency int
+}
+
+// FetchInbox fetches comments for every pull request in prs and builds the
+// inbox for user. Pull requests whose comments cannot be fetched, or can only
+// be fetched in part, are reported as warnings rather than failing the whole
+// inbox.
+func (f *Fetcher) FetchInbox(ctx context.Context, user string, prs []*PullRequestSummary, opts InboxOptions) (Inbox, error) {
+	outputs := make([]*Output, len(prs))
+	warnings := make([][]string, len(prs))
+	fetcher := f
+	if opts.Normalization.UnresolvedOnly {
+		fetcher = f.WithThreads()
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(opts.Concurrency, 1))
+	for i, pr := range prs {
+		i, pr := i, pr
+		group.Go(func() error {
+			payload, err := fetcher.FetchComments(groupCtx, pr.RepoOwner, pr.RepoName, pr.Number)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				warnings[i] = []string{fmt.Sprintf("%s/%s#%d: %v", pr.RepoOwner, pr.RepoName, pr.Number, err)}
+				return nil
+			}
+			for _, warning := range payload.Warnings() {
+				warnings[i] = append(warnings[i], fmt.Sprintf("%s/%s#%d: %s", pr.RepoOwner, pr.RepoName, pr.Number, warning))
+			}
+			out := BuildOutput(pr, payload, opts.Normalization)
+			outputs[i] = &out
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return Inbox{}, err
+	}
+
+	var collected []Output
+	for _, out := range outputs {
+		if out != nil {
+			collected = append(collected, *out)
+		}
+	}
+	inbox := BuildInbox(user, collected)
+	for _, prWarnings := range warnings {
+		inbox.Warnings = append(inbox.Warnings, prWarnings...)
+	}
+	return inbox, nil
+}
+
+// BuildInbox keeps comments not written by user, orders them newest first
+// within each pull request, and orders pull requests by their latest feedback.
+// Pull requests without feedback from others are left out.
+func BuildInbox(user string, outputs []Output) Inbox {
+	inbox := Inbox{User: user, PullRequests: []InboxEntry{}}
+
+	for _, out := range outputs {
+		comments := outputComments(out)
+
+		feedback := make([]Comment, 0, len(comments))
+		for _, c := range comments {
+			if strings.EqualFold(c.Author, user) {
+				continue
+			}
+			feedback = append(feedback, c)
+		}
+		if len(feedback) == 0 {
+			continue
+		}
+
+		inbox.PullRequests = append(inbox.PullRequests, InboxEntry{
+			PR:             out.PR,
+			LatestActivity: feedback[0].CreatedAt,
+			CommentCount:   len(feedback),
+			Comments:       feedback,
+		})
+		inbox.CommentCount += len(feedback)
+	}
+
+	sort.SliceStable(inbox.PullRequests, func(i, j int) bool {
+		return inbox.PullRequests[i].LatestActivity.After(inbox.PullRequests[j].LatestActivity)
+	})
+	inbox.PRCount = len(inbox.PullRequests)
+	return inbox
+}
+
+// RenderInboxMarkdown emits the inbox as a Markdown feed grouped by pull request.
+func RenderInboxMarkdown(inbox Inbox) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Inbox for %s\n\n", safeMarkdownValue(inbox.User))
+	fmt.Fprintf(&b, "- Pull requests: %d\n", inbox.PRCount)
+	fmt.Fprintf(&b, "- Comments: %d\n\n", inbox.CommentCount)
+
+	for _, entry := range inbox.PullRequests {
+		fmt.Fprintf(&b, "## %s#%d: %s\n\n", safeMarkdownValue(entry.PR.Repo), entry.PR.Number, safeMarkdownValue(entry.PR.Title))
+		if entry.PR.URL != "" {
+			fmt.Fprintf(&b, "- URL: %s\n\n", entry.PR.URL)
+		}
+		for _, c := range entry.Comments {
+			writeMarkdownComment(&b, "###", formatCommentType(c.Type)+" by "+safeMarkdownValue(c.Author), c)
+		}
+	}
+
+	return strings.TrimSpace(b.String()) + "\n"
+}
@@ -0,0 +1,125 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestBuildInbox(t *testing.T) {
+	base := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	older := Output{
+		PR: PullRequestMetadata{Repo: "o/a", Number: 1, Title: "Older"},
+		Comments: groupByAuthor([]Comment{
+			{Type: "issue", Author: "bob", CreatedAt: base, BodyText: "first"},
+			{Type: "issue", Author: "Me", CreatedAt: base.Add(5 * time.Hour), BodyText: "my own reply"},
+			{Type: "review", Author: "carol", CreatedAt: base.Add(time.Hour), BodyText: "second"},
+		}),
+	}
+	newer := Output{
+		PR: PullRequestMetadata{Repo: "o/b", Number: 2, Title: "Newer"},
+		Threads: buildThreads([]Comment{
+			{Type: "review_comment", ID: 10, Author: "dave", CreatedAt: base.Add(2 * time.Hour), BodyText: "root"},
+			{Type: "review_comment", ID: 11, InReplyTo: 10, Author: "me", CreatedAt: base.Add(3 * time.Hour), BodyText: "reply"},
+		}),
+	}
+	quiet := Output{
+		PR:       PullRequestMetadata{Repo: "o/c", Number: 3},
+		Comments: groupByAuthor([]Comment{{Type: "issue", Author: "me", CreatedAt: base}}),
+	}
+
+	inbox := BuildInbox("me", []Output{older, quiet, newer})
+
+	if inbox.PRCount != 2 || inbox.CommentCount != 3 {
+		t.Fatalf("unexpected counts: %d PRs, %d comments", inbox.PRCount, inbox.CommentCount)
+	}
+	if inbox.PullRequests[0].PR.Number != 2 || inbox.PullRequests[1].PR.Number != 1 {
+		t.Fatalf("expected PRs ordered by latest feedback, got %+v", inbox.PullRequests)
+	}
+	first := inbox.PullRequests[1]
+	if len(first.Comments) != 2 || first.Comments[0].Author != "carol" || first.Comments[1].Author != "bob" {
+		t.Fatalf("expected feedback newest first without own comments, got %+v", first.Comments)
+	}
+	if !first.LatestActivity.Equal(base.Add(time.Hour)) {
+		t.Errorf("unexpected latest activity %s", first.LatestActivity)
+	}
+
+	markdown := RenderInboxMarkdown(inbox)
+	for _, want := range []string{"# Inbox for me", "## o/b#2: Newer", "### Review by carol"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestFetchInbox(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/1/comments":
+			json.NewEncoder(w).Encode([]*github.IssueComment{
+				{ID: github.Int64(1), Body: github.String("please fix"), User: &github.User{Login: github.String("reviewer")}},
+				{ID: github.Int64(2), Body: github.String("done"), User: &github.User{Login: github.String("me")}},
+			})
+		case "/repos/owner/repo/pulls/1/comments", "/repos/owner/repo/pulls/1/reviews":
+			w.Write([]byte(`[]`))
+		case "/graphql":
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}}}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	prs := []*PullRequestSummary{
+		{Number: 1, RepoOwner: "owner", RepoName: "repo", Title: "Works"},
+		{Number: 2, RepoOwner: "owner", RepoName: "repo", Title: "Missing"},
+	}
+	inbox, err := NewFetcher(client).FetchInbox(context.Background(), "me", prs, InboxOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("FetchInbox failed: %v", err)
+	}
+
+	if inbox.PRCount != 1 || inbox.PullRequests[0].Comments[0].BodyText != "please fix" {
+		t.Fatalf("unexpected inbox %+v", inbox)
+	}
+	if len(inbox.Warnings) != 1 || !strings.Contains(inbox.Warnings[0], "owner/repo#2") {
+		t.Fatalf("expected a warning for the failing PR, got %v", inbox.Warnings)
+	}
+}
+
+func TestFetchInboxForwardsPartialFetchWarnings(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/1/comments":
+			json.NewEncoder(w).Encode([]*github.IssueComment{
+				{ID: github.Int64(1), Body: github.String("please fix"), User: &github.User{Login: github.String("reviewer")}},
+			})
+		case "/repos/owner/repo/pulls/1/comments", "/repos/owner/repo/pulls/1/reviews":
+			w.Write([]byte(`[]`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	prs := []*PullRequestSummary{{Number: 1, RepoOwner: "owner", RepoName: "repo", Title: "Works"}}
+	opts := InboxOptions{Normalization: NormalizationOptions{UnresolvedOnly: true}}
+	inbox, err := NewFetcher(client).FetchInbox(context.Background(), "me", prs, opts)
+	if err != nil {
+		t.Fatalf("FetchInbox failed: %v", err)
+	}
+
+	if inbox.PRCount != 1 {
+		t.Fatalf("expected the PR to be kept, got %+v", inbox)
+	}
+	if len(inbox.Warnings) != 1 || !strings.HasPrefix(inbox.Warnings[0], "owner/repo#1: review threads unavailable") {
+		t.Fatalf("expected the thread warning for the PR, got %v", inbox.Warnings)
+	}
+}
@@ -1,9 +1,11 @@
 package ghprcomments
 
 import (
+	"fmt"
 	"html"
 	"net/url"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -16,6 +18,10 @@ type Output struct {
 	PR           PullRequestMetadata `json:"pr"`
 	CommentCount int                 `json:"comment_count"`
 	Comments     []AuthorComments    `json:"comments"`
+	Threads      []Thread            `json:"threads,omitempty"`
+	Files        []FileComments      `json:"files,omitempty"`
+	Types        []TypeComments      `json:"types,omitempty"`
+	Timeline     []Comment           `json:"timeline,omitempty"`
 }
 
 // AuthorComments groups comments by author for presentation.
@@ -39,21 +45,94 @@ type PullRequestMetadata struct {
 
 // Comment represents an individual review unit.
 type Comment struct {
-	Type      string    `json:"type"`
-	ID        int64     `json:"-"`
-	Author    string    `json:"author"`
-	IsBot     bool      `json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	Path      string    `json:"-"`
-	Line      *int      `json:"-"`
-	State     string    `json:"-"`
-	BodyText  string    `json:"body_text"`
-	Permalink string    `json:"permalink"`
+	Type         string       `json:"type"`
+	ID           int64        `json:"id,omitempty"`
+	InReplyTo    int64        `json:"in_reply_to_id,omitempty"`
+	Author       string       `json:"author"`
+	IsBot        bool         `json:"is_bot"`
+	CreatedAt    time.Time    `json:"created_at"`
+	Path         string       `json:"path,omitempty"`
+	Line         *int         `json:"line,omitempty"`
+	StartLine    *int         `json:"start_line,omitempty"`
+	OriginalLine *int         `json:"original_line,omitempty"`
+	Side         string       `json:"side,omitempty"`
+	CommitID     string       `json:"commit_id,omitempty"`
+	DiffHunk     string       `json:"diff_hunk,omitempty"`
+	State        string       `json:"-"`
+	ThreadID     string       `json:"thread_id,omitempty"`
+	Resolved     *bool        `json:"resolved,omitempty"`
+	Outdated     *bool        `json:"outdated,omitempty"`
+	BodyText     string       `json:"body_text"`
+	BodyMarkdown string       `json:"body_markdown"`
+	Suggestions  []Suggestion `json:"suggestions,omitempty"`
+	Permalink    string       `json:"permalink"`
+	// Reactions counts emoji reactions by GitHub reaction content, e.g. "+1".
+	Reactions  map[string]int `json:"reactions,omitempty"`
+	SyncStatus string         `json:"sync_status,omitempty"`
+	// CollapsedCount is how many bot comments were folded into this one.
+	CollapsedCount int `json:"collapsed_count,omitempty"`
+	// Occurrences and FirstSeen describe the near-identical comments that
+	// DedupeBotComments folded into this one.
+	Occurrences int        `json:"occurrences,omitempty"`
+	FirstSeen   *time.Time `json:"first_seen,omitempty"`
+	// Draft marks a locally drafted comment that has not been submitted;
+	// DraftID is its number in the draft store.
+	Draft   bool `json:"draft,omitempty"`
+	DraftID int  `json:"draft_id,omitempty"`
+	// OriginalCommitID is the commit the comment was written against;
+	// CommitID moves to the latest commit the comment still applies to.
+	OriginalCommitID string `json:"original_commit_id,omitempty"`
+}
+
+// StripLevel selects how aggressively comment bodies are normalised into body_text.
+type StripLevel string
+
+const (
+	// StripLevelPlain flattens bodies into a single line of plain text.
+	StripLevelPlain StripLevel = "plain"
+	// StripLevelMarkdown keeps Markdown structure (code fences, tables, links)
+	// while dropping HTML comments and unwrapping <details> blocks.
+	StripLevelMarkdown StripLevel = "markdown"
+	// StripLevelRaw leaves bodies exactly as posted.
+	StripLevelRaw StripLevel = "raw"
+)
+
+// ParseStripLevel validates a user-supplied strip level. An empty value selects plain text.
+func ParseStripLevel(value string) (StripLevel, error) {
+	switch level := StripLevel(strings.ToLower(strings.TrimSpace(value))); level {
+	case "":
+		return StripLevelPlain, nil
+	case StripLevelPlain, StripLevelMarkdown, StripLevelRaw:
+		return level, nil
+	default:
+		return "", fmt.Errorf("unknown strip level %q (want raw, markdown or plain)", value)
+	}
 }
 
 // NormalizationOptions controls comment shaping.
 type NormalizationOptions struct {
+	// StripHTML removes HTML tags from body_markdown and from body_text at the
+	// raw and markdown levels, leaving code spans alone. Plain text never
+	// contains HTML.
 	StripHTML bool
+	// Level controls body_text normalisation; the zero value means plain text.
+	Level StripLevel
+	// Threads groups the output by conversation thread instead of by author.
+	// It is shorthand for GroupBy: GroupByThread.
+	Threads bool
+	// GroupBy selects the nesting; the zero value groups by author.
+	GroupBy GroupBy
+	// UnresolvedOnly drops review comments whose thread has been resolved.
+	UnresolvedOnly bool
+	// IgnoredAuthors drops comments by these logins (case-insensitive).
+	IgnoredAuthors []string
+	// ExcludeBots drops comments by bots; OnlyBots drops everyone else's.
+	ExcludeBots bool
+	OnlyBots    bool
+	// BotCollapse maps lower-case bot logins, or BotCollapseAll, to a collapse mode.
+	BotCollapse map[string]string
+	// DedupeBots runs DedupeBotComments over the built output.
+	DedupeBots bool
 }
 
 // BuildOutput merges PR metadata and comments into the external contract.
@@ -61,26 +140,94 @@ func BuildOutput(pr *PullRequestSummary, payload commentPayload, opts Normalizat
 	if pr == nil {
 		return Output{}
 	}
+	return shapeOutput(pr, filterComments(normalizeComments(pr, payload, opts), opts), opts)
+}
 
+// normalizeComments converts every comment in payload, before any of the
+// display filters in opts are applied.
+func normalizeComments(pr *PullRequestSummary, payload commentPayload, opts NormalizationOptions) []Comment {
 	total := len(payload.issueComments) + len(payload.reviewComments) + len(payload.reviews)
-	grouped := make(map[string][]Comment, total)
+	all := make([]Comment, 0, total)
 
 	for _, ic := range payload.issueComments {
-		comment := normalizeIssueComment(ic, opts)
-		author := comment.Author
-		grouped[author] = append(grouped[author], comment)
+		all = append(all, normalizeIssueComment(ic, opts))
+	}
+
+	threadStates := make(map[int64]ReviewThreadState, len(payload.reviewComments))
+	for _, state := range payload.threads {
+		for _, id := range state.CommentIDs {
+			threadStates[id] = state
+		}
 	}
 
 	for _, rc := range payload.reviewComments {
 		comment := normalizeReviewComment(rc, opts)
-		author := comment.Author
-		grouped[author] = append(grouped[author], comment)
+		state, ok := threadStates[comment.ID]
+		if !ok && comment.InReplyTo != 0 {
+			// Only the first 100 comments of a thread are listed; later
+			// replies point at the root, which always is.
+			state, ok = threadStates[comment.InReplyTo]
+		}
+		if ok {
+			resolved, outdated := state.IsResolved, state.IsOutdated
+			comment.ThreadID = state.NodeID
+			comment.Resolved = &resolved
+			comment.Outdated = &outdated
+		}
+		all = append(all, comment)
 	}
 
 	for _, review := range payload.reviews {
-		comment := normalizeReview(review, opts)
-		author := comment.Author
-		grouped[author] = append(grouped[author], comment)
+		all = append(all, normalizeReview(review, opts))
+	}
+
+	for _, ev := range payload.timeline {
+		if event, ok := normalizeTimelineEvent(ev, pr.URL); ok {
+			all = append(all, event)
+		}
+	}
+	return all
+}
+
+// filterComments drops the comments opts hides: resolved threads, ignored
+// authors and bots.
+func filterComments(all []Comment, opts NormalizationOptions) []Comment {
+	if opts.UnresolvedOnly {
+		all = slices.DeleteFunc(all, func(c Comment) bool {
+			return c.Resolved != nil && *c.Resolved
+		})
+	}
+
+	if len(opts.IgnoredAuthors) > 0 {
+		all = slices.DeleteFunc(all, func(c Comment) bool {
+			return slices.ContainsFunc(opts.IgnoredAuthors, func(login string) bool {
+				return strings.EqualFold(login, c.Author)
+			})
+		})
+	}
+
+	return filterBotComments(all, opts)
+}
+
+// shapeOutput groups the filtered comments for pr as opts asks.
+func shapeOutput(pr *PullRequestSummary, all []Comment, opts NormalizationOptions) Output {
+	meta := buildMetadata(pr)
+
+	groupBy := opts.GroupBy
+	if opts.Threads {
+		groupBy = GroupByThread
+	}
+	out := groupOutput(Output{PR: meta, CommentCount: len(all)}, all, groupBy)
+	if opts.DedupeBots {
+		out = DedupeBotComments(out)
+	}
+	return out
+}
+
+func groupByAuthor(all []Comment) []AuthorComments {
+	grouped := make(map[string][]Comment, len(all))
+	for _, comment := range all {
+		grouped[comment.Author] = append(grouped[comment.Author], comment)
 	}
 
 	authors := make([]string, 0, len(grouped))
@@ -128,12 +275,16 @@ func BuildOutput(pr *PullRequestSummary, payload commentPayload, opts Normalizat
 		})
 	}
 
+	return commentGroups
+}
+
+func buildMetadata(pr *PullRequestSummary) PullRequestMetadata {
 	repo := pr.RepoOwner
 	if pr.RepoName != "" {
 		repo = strings.Trim(pr.RepoOwner+"/"+pr.RepoName, "/")
 	}
 
-	meta := PullRequestMetadata{
+	return PullRequestMetadata{
 		Repo:      repo,
 		Number:    pr.Number,
 		Title:     pr.Title,
@@ -144,27 +295,29 @@ func BuildOutput(pr *PullRequestSummary, payload commentPayload, opts Normalizat
 		HeadRef:   pr.HeadRef,
 		BaseRef:   pr.BaseRef,
 	}
-
-	return Output{PR: meta, CommentCount: total, Comments: commentGroups}
 }
 
 func normalizeIssueComment(c *github.IssueComment, opts NormalizationOptions) Comment {
 	body := cleanCommentBody(c.GetBody(), opts)
+	markdown := preserveMarkdownBody(c.GetBody(), opts)
 	author := canonicalAuthor(safeLogin(c.GetUser()))
 
 	return Comment{
-		Type:      "issue",
-		ID:        c.GetID(),
-		Author:    author,
-		IsBot:     IsBotAuthor(c.GetUser()),
-		CreatedAt: derefTimestamp(c.CreatedAt),
-		BodyText:  body,
-		Permalink: c.GetHTMLURL(),
+		Type:         "issue",
+		ID:           c.GetID(),
+		Author:       author,
+		IsBot:        IsBotAuthor(c.GetUser()),
+		CreatedAt:    derefTimestamp(c.CreatedAt),
+		BodyText:     body,
+		BodyMarkdown: markdown,
+		Permalink:    c.GetHTMLURL(),
+		Reactions:    reactionCounts(c.Reactions),
 	}
 }
 
 func normalizeReviewComment(c *github.PullRequestComment, opts NormalizationOptions) Comment {
 	body := cleanCommentBody(c.GetBody(), opts)
+	markdown := preserveMarkdownBody(c.GetBody(), opts)
 	author := canonicalAuthor(safeLogin(c.GetUser()))
 
 	var linePtr *int
@@ -172,33 +325,62 @@ func normalizeReviewComment(c *github.PullRequestComment, opts NormalizationOpti
 		lineVal := c.GetLine()
 		linePtr = &lineVal
 	}
+	var startLinePtr *int
+	if c.StartLine != nil {
+		startVal := c.GetStartLine()
+		startLinePtr = &startVal
+	}
+	var originalLinePtr *int
+	if c.OriginalLine != nil {
+		originalVal := c.GetOriginalLine()
+		originalLinePtr = &originalVal
+	}
+
+	suggestions := parseSuggestions(c.GetBody(), c.GetPath(), c.GetStartLine(), c.GetLine())
+	for i := range suggestions {
+		suggestions[i].CommentID = c.GetID()
+		suggestions[i].Author = author
+		suggestions[i].CommitID = c.GetCommitID()
+	}
 
 	return Comment{
-		Type:      "review_comment",
-		ID:        c.GetID(),
-		Author:    author,
-		IsBot:     IsBotAuthor(c.GetUser()),
-		CreatedAt: derefTimestamp(c.CreatedAt),
-		Path:      c.GetPath(),
-		Line:      linePtr,
-		BodyText:  body,
-		Permalink: c.GetHTMLURL(),
+		Type:             "review_comment",
+		ID:               c.GetID(),
+		InReplyTo:        c.GetInReplyTo(),
+		Author:           author,
+		IsBot:            IsBotAuthor(c.GetUser()),
+		CreatedAt:        derefTimestamp(c.CreatedAt),
+		Path:             c.GetPath(),
+		Line:             linePtr,
+		StartLine:        startLinePtr,
+		OriginalLine:     originalLinePtr,
+		Side:             c.GetSide(),
+		CommitID:         c.GetCommitID(),
+		OriginalCommitID: c.GetOriginalCommitID(),
+		DiffHunk:         strings.TrimRight(strings.ReplaceAll(c.GetDiffHunk(), "\r\n", "\n"), "\n"),
+		BodyText:         body,
+		BodyMarkdown:     markdown,
+		Suggestions:      suggestions,
+		Permalink:        c.GetHTMLURL(),
+		Reactions:        reactionCounts(c.Reactions),
 	}
 }
 
 func normalizeReview(r *github.PullRequestReview, opts NormalizationOptions) Comment {
 	body := cleanCommentBody(r.GetBody(), opts)
+	markdown := preserveMarkdownBody(r.GetBody(), opts)
 	author := canonicalAuthor(safeLogin(r.GetUser()))
 
 	return Comment{
-		Type:      "review_event",
-		ID:        r.GetID(),
-		Author:    author,
-		IsBot:     IsBotAuthor(r.GetUser()),
-		CreatedAt: derefTimestamp(r.SubmittedAt),
-		State:     r.GetState(),
-		BodyText:  body,
-		Permalink: r.GetHTMLURL(),
+		Type:         "review_event",
+		ID:           r.GetID(),
+		Author:       author,
+		IsBot:        IsBotAuthor(r.GetUser()),
+		CreatedAt:    derefTimestamp(r.SubmittedAt),
+		State:        r.GetState(),
+		BodyText:     body,
+		BodyMarkdown: markdown,
+		Permalink:    r.GetHTMLURL(),
 	}
 }
 
@@ -225,20 +407,75 @@ var (
 	htmlCommentRegex        = regexp.MustCompile(`(?s)<!--.*?-->`)
 	codeFenceRegex          = regexp.MustCompile("(?s)```.*?```")
 	inlineCodeRegex         = regexp.MustCompile("`([^`]*)`")
+	codeSpanRegex           = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
 	imageMarkdownRegex      = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
 	linkMarkdownRegex       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
 	orderedListRegex        = regexp.MustCompile(`^\d+\.\s+`)
 	base64BlobRegex         = regexp.MustCompile(`\b[A-Za-z0-9+/]{40,}={0,2}\b`)
 	urlRegex                = regexp.MustCompile(`https?://[^\s)]+`)
+	blankLineRunRegex       = regexp.MustCompile(`\n[ \t]*(?:\n[ \t]*){2,}`)
 )
 
+// cleanCommentBody produces body_text at the configured strip level.
 func cleanCommentBody(body string, opts NormalizationOptions) string {
+	switch opts.Level {
+	case StripLevelRaw:
+		return preserveMarkdownBody(body, opts)
+	case StripLevelMarkdown:
+		return cleanMarkdownBody(body, opts)
+	default:
+		return flattenCommentBody(body)
+	}
+}
+
+// preserveMarkdownBody returns the body as posted, only normalising line endings
+// and, when requested, removing HTML tags.
+func preserveMarkdownBody(body string, opts NormalizationOptions) string {
 	if strings.TrimSpace(body) == "" {
 		return ""
 	}
+	normalized := strings.ReplaceAll(body, "\r\n", "\n")
+	if opts.StripHTML {
+		normalized = stripProseHTML(normalized)
+	}
+	return strings.TrimSpace(normalized)
+}
 
-	// Always normalize to human-readable plain text regardless of incoming flags.
-	_ = opts // retained for future expansion and to preserve function signature
+// cleanMarkdownBody removes bot noise while keeping the Markdown structure reviewers wrote.
+func cleanMarkdownBody(body string, opts NormalizationOptions) string {
+	if strings.TrimSpace(body) == "" {
+		return ""
+	}
+
+	normalized := strings.ReplaceAll(body, "\r\n", "\n")
+	normalized = htmlCommentRegex.ReplaceAllString(normalized, "")
+	normalized = expandDetailsBlocks(normalized)
+	if opts.StripHTML {
+		normalized = stripProseHTML(normalized)
+	}
+	normalized = blankLineRunRegex.ReplaceAllString(normalized, "\n\n")
+	return strings.TrimSpace(normalized)
+}
+
+// stripProseHTML removes HTML tags outside fenced and inline code, leaving
+// code such as `List<T>` as written.
+func stripProseHTML(body string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range codeSpanRegex.FindAllStringIndex(body, -1) {
+		b.WriteString(html.UnescapeString(StripHTML(body[last:span[0]])))
+		b.WriteString(body[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(html.UnescapeString(StripHTML(body[last:])))
+	return b.String()
+}
+
+// flattenCommentBody normalises a body to a single line of human-readable plain text.
+func flattenCommentBody(body string) string {
+	if strings.TrimSpace(body) == "" {
+		return ""
+	}
 
 	normalized := html.UnescapeString(body)
 	normalized = expandDetailsBlocks(normalized)
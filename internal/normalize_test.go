@@ -104,3 +104,123 @@ func TestCleanCommentBodyPreservesDetailsContent(t *testing.T) {
 		}
 	}
 }
+
+func TestCleanCommentBodyStripLevels(t *testing.T) {
+	body := "<!-- bot metadata -->\r\nUse this:\r\n\r\n```go\r\nx := 1\r\n```\r\n\r\n\r\n\r\n| a | b |\r\n|---|---|\r\n\r\nSee https://example.com/docs <b>now</b>"
+
+	raw := cleanCommentBody(body, NormalizationOptions{Level: StripLevelRaw})
+	if !strings.Contains(raw, "<!-- bot metadata -->") || strings.Contains(raw, "\r") {
+		t.Fatalf("raw level should keep the body verbatim apart from line endings, got %q", raw)
+	}
+
+	markdown := cleanCommentBody(body, NormalizationOptions{Level: StripLevelMarkdown})
+	for _, want := range []string{"```go\nx := 1\n```", "| a | b |", "https://example.com/docs", "<b>now</b>"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown level missing %q in %q", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "bot metadata") || strings.Contains(markdown, "\n\n\n") {
+		t.Errorf("markdown level should drop HTML comments and collapse blank lines, got %q", markdown)
+	}
+
+	stripped := cleanCommentBody(body, NormalizationOptions{Level: StripLevelMarkdown, StripHTML: true})
+	if strings.Contains(stripped, "<b>") || !strings.Contains(stripped, "now") {
+		t.Errorf("markdown level should honour StripHTML, got %q", stripped)
+	}
+
+	plain := cleanCommentBody(body, NormalizationOptions{})
+	if strings.Contains(plain, "\n") || strings.Contains(plain, "```") {
+		t.Errorf("plain level should flatten the body, got %q", plain)
+	}
+}
+
+func TestStripHTMLKeepsCode(t *testing.T) {
+	body := "Prefer <b>this</b> & `Map<K, V>`:\n\n```go\nfunc Keys[K comparable, V any](m map[K]V) List<K> {\n\treturn nil\n}\n```"
+	opts := NormalizationOptions{StripHTML: true}
+
+	for name, got := range map[string]string{
+		"markdown":  preserveMarkdownBody(body, opts),
+		"body_text": cleanCommentBody(body, NormalizationOptions{Level: StripLevelMarkdown, StripHTML: true}),
+	} {
+		for _, want := range []string{"Prefer this & `Map<K, V>`", "List<K> {\n\treturn nil"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s missing %q in %q", name, want, got)
+			}
+		}
+		if strings.Contains(got, "<b>") {
+			t.Errorf("%s kept prose HTML: %q", name, got)
+		}
+	}
+}
+
+func TestBuildOutputKeepsMarkdownBody(t *testing.T) {
+	body := "Consider:\n\n```suggestion\nreturn nil\n```"
+	payload := commentPayload{
+		issueComments: []*github.IssueComment{
+			{ID: github.Int64(1), Body: github.String(body), User: &github.User{Login: github.String("alice")}},
+		},
+	}
+
+	out := BuildOutput(&PullRequestSummary{Number: 1}, payload, NormalizationOptions{})
+	comment := out.Comments[0].Comments[0]
+	if comment.BodyMarkdown != body {
+		t.Fatalf("expected body_markdown to keep original Markdown, got %q", comment.BodyMarkdown)
+	}
+	if comment.BodyText != "Consider:" {
+		t.Fatalf("expected plain body_text, got %q", comment.BodyText)
+	}
+}
+
+func TestBuildOutputDropsIgnoredAuthors(t *testing.T) {
+	payload := commentPayload{
+		issueComments: []*github.IssueComment{
+			{ID: github.Int64(1), Body: github.String("coverage report"), User: &github.User{Login: github.String("Codecov")}},
+			{ID: github.Int64(2), Body: github.String("looks good"), User: &github.User{Login: github.String("alice")}},
+		},
+	}
+
+	out := BuildOutput(&PullRequestSummary{Number: 1}, payload, NormalizationOptions{IgnoredAuthors: []string{"codecov"}})
+	if out.CommentCount != 1 || len(out.Comments) != 1 || out.Comments[0].Author != "alice" {
+		t.Fatalf("expected only alice's comment, got %+v", out.Comments)
+	}
+}
+
+func TestParseStripLevel(t *testing.T) {
+	if level, err := ParseStripLevel(""); err != nil || level != StripLevelPlain {
+		t.Fatalf("expected empty level to default to plain, got %q, %v", level, err)
+	}
+	if level, err := ParseStripLevel("Markdown"); err != nil || level != StripLevelMarkdown {
+		t.Fatalf("expected markdown level, got %q, %v", level, err)
+	}
+	if _, err := ParseStripLevel("html"); err == nil {
+		t.Fatal("expected unknown level to be rejected")
+	}
+}
+
+func TestBuildOutputExposesReviewCommentLocation(t *testing.T) {
+	payload := commentPayload{
+		reviewComments: []*github.PullRequestComment{
+			{
+				ID:           github.Int64(3),
+				Body:         github.String("nit"),
+				Path:         github.String("main.go"),
+				Line:         github.Int(12),
+				OriginalLine: github.Int(10),
+				Side:         github.String("RIGHT"),
+				CommitID:     github.String("abc123"),
+				DiffHunk:     github.String("@@ -8,3 +8,5 @@\r\n context\r\n+added\r\n"),
+			},
+		},
+	}
+
+	out := BuildOutput(&PullRequestSummary{Number: 1}, payload, NormalizationOptions{})
+	data, err := MarshalJSON(out, true)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{`"path": "main.go"`, `"line": 12`, `"original_line": 10`, `"side": "RIGHT"`, `"commit_id": "abc123"`, `"diff_hunk": "@@ -8,3 +8,5 @@\n context\n+added"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected JSON to contain %s, got %s", want, data)
+		}
+	}
+}
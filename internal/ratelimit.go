@@ -0,0 +1,265 @@
+package ghprcomments
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 4
+	defaultMaxWait    = 90 * time.Second
+	baseBackoff       = 500 * time.Millisecond
+)
+
+// RateLimitStatus is the most recent core quota reported by GitHub.
+type RateLimitStatus struct {
+	Known     bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// String renders a short "4821/5000 requests left, resets 14:05" description.
+func (s RateLimitStatus) String() string {
+	if !s.Known {
+		return "API quota unknown"
+	}
+	text := fmt.Sprintf("API quota %d/%d", s.Remaining, s.Limit)
+	if !s.Reset.IsZero() {
+		text += ", resets " + s.Reset.Local().Format("15:04")
+	}
+	return text
+}
+
+// Low reports whether less than a fifth of the quota remains.
+func (s RateLimitStatus) Low() bool {
+	return s.Known && s.Limit > 0 && s.Remaining*5 < s.Limit
+}
+
+// RateLimitTracker records the quota headers seen on responses so callers can
+// report remaining quota and pace themselves. It is safe for concurrent use.
+type RateLimitTracker struct {
+	mu     sync.Mutex
+	status RateLimitStatus
+}
+
+// NewRateLimitTracker creates an empty tracker.
+func NewRateLimitTracker() *RateLimitTracker {
+	return &RateLimitTracker{}
+}
+
+// Status returns the latest observed quota.
+func (t *RateLimitTracker) Status() RateLimitStatus {
+	if t == nil {
+		return RateLimitStatus{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// Concurrency scales max down as the remaining quota shrinks. Unknown quota
+// leaves max untouched.
+func (t *RateLimitTracker) Concurrency(max int) int {
+	status := t.Status()
+	switch {
+	case max < 1:
+		return 1
+	case !status.Known:
+		return max
+	case status.Remaining < 100:
+		return 1
+	case status.Low():
+		return min(max, 2)
+	default:
+		return max
+	}
+}
+
+func (t *RateLimitTracker) observe(header http.Header) {
+	if t == nil {
+		return
+	}
+	// GraphQL and search have their own buckets; track the core REST quota.
+	if resource := header.Get("X-RateLimit-Resource"); resource != "" && resource != "core" {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	status := RateLimitStatus{Known: true, Remaining: remaining}
+	status.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		status.Reset = time.Unix(reset, 0)
+	}
+
+	t.mu.Lock()
+	t.status = status
+	t.mu.Unlock()
+}
+
+// RetryTransport retries requests that GitHub rejected because of primary or
+// secondary rate limits, honouring Retry-After and X-RateLimit-Reset, and
+// retries 5xx responses to idempotent requests with jittered exponential
+// backoff. A 5xx to a POST or PATCH may come after the write was applied, so
+// those are returned as is. Waits longer than MaxWait are not attempted; the
+// limited response is returned instead.
+type RetryTransport struct {
+	base       http.RoundTripper
+	tracker    *RateLimitTracker
+	MaxRetries int
+	MaxWait    time.Duration
+	// Notify, when set, is called before each backoff with the reason and delay.
+	Notify func(reason string, wait time.Duration)
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport wraps base (or http.DefaultTransport when nil). tracker may be nil.
+func NewRetryTransport(base http.RoundTripper, tracker *RateLimitTracker) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{
+		base:       base,
+		tracker:    tracker,
+		MaxRetries: defaultMaxRetries,
+		MaxWait:    defaultMaxWait,
+		sleep:      sleepContext,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		t.tracker.observe(resp.Header)
+
+		if !replayable || attempt >= t.MaxRetries {
+			return resp, nil
+		}
+		reason, wait := t.retryDelay(req, resp, attempt)
+		if reason == "" || wait > t.MaxWait {
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if t.Notify != nil {
+			t.Notify(reason, wait)
+		}
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay decides whether resp warrants another attempt and how long to
+// wait first. An empty reason means the response should be returned as is.
+// Rate-limit rejections are safe to repeat for any method because GitHub did
+// not process the request; server errors only for idempotent methods.
+func (t *RetryTransport) retryDelay(req *http.Request, resp *http.Response, attempt int) (string, time.Duration) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden:
+		if wait, ok := retryAfter(resp.Header); ok {
+			return "secondary rate limit", wait
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				return "rate limit exhausted", max(time.Until(time.Unix(reset, 0))+time.Second, 0)
+			}
+			return "rate limit exhausted", t.backoff(attempt)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || isSecondaryLimit(resp) {
+			// GitHub asks clients to wait at least a minute without guidance.
+			return "secondary rate limit", time.Minute + t.backoff(attempt)
+		}
+		return "", 0
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented && idempotent(req.Method):
+		return fmt.Sprintf("server error %d", resp.StatusCode), t.backoff(attempt)
+	default:
+		return "", 0
+	}
+}
+
+// idempotent reports whether repeating a request with this method cannot
+// apply a change twice.
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns a full-jitter exponential delay for the given attempt.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	ceiling := baseBackoff << attempt
+	return ceiling/2 + rand.N(ceiling/2+1)
+}
+
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return max(time.Until(when), 0), true
+	}
+	return 0, false
+}
+
+// isSecondaryLimit inspects a 403 body for GitHub's secondary or abuse
+// rate-limit message, restoring the body for the caller.
+func isSecondaryLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	text := strings.ToLower(string(body))
+	return strings.Contains(text, "secondary rate limit") || strings.Contains(text, "abuse")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
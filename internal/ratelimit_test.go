@@ -0,0 +1,210 @@
+package ghprcomments
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRetryTransport(tracker *RateLimitTracker) (*RetryTransport, *[]time.Duration) {
+	var waits []time.Duration
+	transport := NewRetryTransport(nil, tracker)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return transport, &waits
+}
+
+func TestRetryTransport_RetriesServerErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			http.Error(w, "boom", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport, waits := newTestRetryTransport(nil)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts != 3 {
+		t.Fatalf("expected success on third attempt, got %d after %d attempts", resp.StatusCode, attempts)
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("expected 2 backoffs, got %v", *waits)
+	}
+	for i, wait := range *waits {
+		ceiling := baseBackoff << i
+		if wait < ceiling/2 || wait > ceiling {
+			t.Errorf("backoff %d = %s, want within [%s, %s]", i, wait, ceiling/2, ceiling)
+		}
+	}
+}
+
+func TestRetryTransport_HonoursRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "7")
+			http.Error(w, "You have exceeded a secondary rate limit", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport, waits := newTestRetryTransport(nil)
+	var notified string
+	transport.Notify = func(reason string, wait time.Duration) { notified = reason }
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 after retry, got %d", resp.StatusCode)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 7*time.Second {
+		t.Fatalf("expected a single 7s wait, got %v", *waits)
+	}
+	if notified != "secondary rate limit" {
+		t.Errorf("unexpected notify reason %q", notified)
+	}
+}
+
+func TestRetryTransport_GivesUpOnDistantReset(t *testing.T) {
+	attempts := 0
+	reset := time.Now().Add(30 * time.Minute).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		http.Error(w, "API rate limit exceeded", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	tracker := NewRateLimitTracker()
+	transport, waits := newTestRetryTransport(tracker)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden || attempts != 1 || len(*waits) != 0 {
+		t.Fatalf("expected the limited response without waiting, got %d after %d attempts (waits %v)", resp.StatusCode, attempts, *waits)
+	}
+	if !strings.Contains(string(body), "rate limit exceeded") {
+		t.Errorf("expected original body to be preserved, got %q", body)
+	}
+
+	status := tracker.Status()
+	if !status.Known || status.Remaining != 0 || status.Limit != 5000 || status.Reset.Unix() != reset {
+		t.Fatalf("unexpected tracked status %+v", status)
+	}
+	if got := tracker.Concurrency(4); got != 1 {
+		t.Errorf("expected exhausted quota to serialise work, got concurrency %d", got)
+	}
+}
+
+func TestRetryTransport_ReplaysRequestBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	transport, _ := newTestRetryTransport(nil)
+	resp, err := (&http.Client{Transport: transport}).Post(server.URL, "application/json", strings.NewReader(`{"query":"q"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(bodies) != 2 || bodies[0] != bodies[1] || bodies[1] != `{"query":"q"}` {
+		t.Fatalf("expected body to be replayed, got %q", bodies)
+	}
+}
+
+func TestRetryTransport_DoesNotRepeatWritesAfterServerErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	transport, waits := newTestRetryTransport(nil)
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		attempts = 0
+		req, _ := http.NewRequest(method, server.URL, strings.NewReader(`{"body":"reply"}`))
+		resp, err := (&http.Client{Transport: transport}).Do(req)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadGateway || attempts != 1 {
+			t.Fatalf("%s: expected the 502 after one attempt, got %d after %d", method, resp.StatusCode, attempts)
+		}
+	}
+	if len(*waits) != 0 {
+		t.Fatalf("expected no backoff, got %v", *waits)
+	}
+}
+
+func TestRateLimitTracker(t *testing.T) {
+	tracker := NewRateLimitTracker()
+	if got := tracker.Concurrency(4); got != 4 {
+		t.Fatalf("expected unknown quota to keep max concurrency, got %d", got)
+	}
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "5000")
+	header.Set("X-RateLimit-Remaining", "800")
+	tracker.observe(header)
+	if got := tracker.Concurrency(4); got != 2 {
+		t.Fatalf("expected low quota to halve concurrency, got %d", got)
+	}
+
+	graphql := http.Header{}
+	graphql.Set("X-RateLimit-Resource", "graphql")
+	graphql.Set("X-RateLimit-Remaining", "10")
+	tracker.observe(graphql)
+	if got := tracker.Status().Remaining; got != 800 {
+		t.Fatalf("expected GraphQL quota to be ignored, got remaining %d", got)
+	}
+
+	if got := tracker.Status().String(); got != "API quota 800/5000" {
+		t.Errorf("unexpected status string %q", got)
+	}
+
+	var nilTracker *RateLimitTracker
+	if got := nilTracker.Concurrency(3); got != 3 {
+		t.Errorf("expected nil tracker to keep max concurrency, got %d", got)
+	}
+}
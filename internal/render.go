@@ -11,7 +11,7 @@ import (
 // MarshalJSON encodes the output as either nested or flat JSON.
 func MarshalJSON(out Output, flat bool) ([]byte, error) {
 	if flat {
-		return json.MarshalIndent(flattenCommentGroups(out.Comments), "", "  ")
+		return json.MarshalIndent(outputComments(out), "", "  ")
 	}
 	return json.MarshalIndent(out, "", "  ")
 }
@@ -49,33 +49,150 @@ func RenderMarkdown(out Output) string {
 	for _, group := range out.Comments {
 		fmt.Fprintf(&b, "## %s\n\n", safeMarkdownValue(group.Author))
 		for _, c := range group.Comments {
-			heading := formatCommentType(c.Type)
-			timestamp := "(unknown time)"
-			if !c.CreatedAt.IsZero() {
-				timestamp = c.CreatedAt.Format(time.RFC3339)
-			}
-			fmt.Fprintf(&b, "### %s — %s\n", heading, timestamp)
-			if c.Path != "" {
-				fmt.Fprintf(&b, "- Path: %s\n", safeMarkdownValue(c.Path))
-			}
-			if c.Line != nil {
-				fmt.Fprintf(&b, "- Line: %d\n", *c.Line)
-			}
-			if c.State != "" {
-				fmt.Fprintf(&b, "- State: %s\n", safeMarkdownValue(c.State))
-			}
-			if c.Permalink != "" {
-				fmt.Fprintf(&b, "- Link: %s\n", c.Permalink)
-			}
-			b.WriteString("\n")
-			b.WriteString(blockQuote(c.BodyText))
-			b.WriteString("\n\n")
+			writeMarkdownEntry(&b, "###", formatCommentType(c.Type), c)
+		}
+	}
+
+	for _, thread := range out.Threads {
+		fmt.Fprintf(&b, "## %s\n\n", threadHeading(thread))
+		if IsTimelineEvent(thread.Root.Type) {
+			writeMarkdownEvent(&b, "###", thread.Root)
+			continue
+		}
+		writeMarkdownComment(&b, "###", formatCommentType(thread.Root.Type)+" by "+safeMarkdownValue(thread.Root.Author), thread.Root)
+		for _, reply := range thread.Replies {
+			writeMarkdownComment(&b, "####", "Reply by "+safeMarkdownValue(reply.Author), reply)
+		}
+	}
+
+	for _, group := range out.Files {
+		heading := group.Path
+		if heading == "" {
+			heading = "Conversation"
+		}
+		fmt.Fprintf(&b, "## %s\n\n", heading)
+		for _, c := range group.Comments {
+			writeMarkdownEntry(&b, "###", formatCommentType(c.Type)+" by "+safeMarkdownValue(c.Author), c)
+		}
+	}
+
+	for _, group := range out.Types {
+		fmt.Fprintf(&b, "## %s\n\n", formatCommentType(group.Type))
+		for _, c := range group.Comments {
+			writeMarkdownEntry(&b, "###", safeMarkdownValue(c.Author), c)
+		}
+	}
+
+	if len(out.Timeline) > 0 {
+		b.WriteString("## Timeline\n\n")
+		for _, c := range out.Timeline {
+			writeMarkdownEntry(&b, "###", formatCommentType(c.Type)+" by "+safeMarkdownValue(c.Author), c)
 		}
 	}
 
 	return strings.TrimSpace(b.String()) + "\n"
 }
 
+// writeMarkdownEntry renders timeline events compactly and everything else in full.
+func writeMarkdownEntry(b *strings.Builder, level, heading string, c Comment) {
+	if IsTimelineEvent(c.Type) {
+		writeMarkdownEvent(b, level, c)
+		return
+	}
+	writeMarkdownComment(b, level, heading, c)
+}
+
+// writeMarkdownEvent renders a timeline event as a heading and its link; the
+// event text is already a one-line description.
+func writeMarkdownEvent(b *strings.Builder, level string, c Comment) {
+	timestamp := "(unknown time)"
+	if !c.CreatedAt.IsZero() {
+		timestamp = c.CreatedAt.Format(time.RFC3339)
+	}
+	fmt.Fprintf(b, "%s %s — %s\n", level, c.BodyText, timestamp)
+	fmt.Fprintf(b, "- By: %s\n", safeMarkdownValue(c.Author))
+	if c.Permalink != "" {
+		fmt.Fprintf(b, "- Link: %s\n", c.Permalink)
+	}
+	b.WriteString("\n")
+}
+
+func writeMarkdownComment(b *strings.Builder, level, heading string, c Comment) {
+	timestamp := "(unknown time)"
+	if !c.CreatedAt.IsZero() {
+		timestamp = c.CreatedAt.Format(time.RFC3339)
+	}
+	fmt.Fprintf(b, "%s %s — %s\n", level, heading, timestamp)
+	if c.Path != "" {
+		fmt.Fprintf(b, "- Path: %s\n", safeMarkdownValue(c.Path))
+	}
+	switch {
+	case c.Line != nil && c.StartLine != nil && *c.StartLine != *c.Line:
+		fmt.Fprintf(b, "- Lines: %d-%d\n", *c.StartLine, *c.Line)
+	case c.Line != nil:
+		fmt.Fprintf(b, "- Line: %d\n", *c.Line)
+	case c.OriginalLine != nil:
+		fmt.Fprintf(b, "- Line: %d (original)\n", *c.OriginalLine)
+	}
+	if c.State != "" {
+		fmt.Fprintf(b, "- State: %s\n", safeMarkdownValue(c.State))
+	}
+	if c.Resolved != nil {
+		fmt.Fprintf(b, "- Resolved: %s\n", yesNo(*c.Resolved))
+	}
+	if c.Outdated != nil && *c.Outdated {
+		b.WriteString("- Outdated: yes\n")
+	}
+	if c.Permalink != "" {
+		fmt.Fprintf(b, "- Link: %s\n", c.Permalink)
+	}
+	b.WriteString("\n")
+	if c.DiffHunk != "" {
+		fence := codeFenceFor(c.DiffHunk)
+		fmt.Fprintf(b, "%sdiff\n%s\n%s\n\n", fence, c.DiffHunk, fence)
+	}
+	body := c.BodyMarkdown
+	if body == "" {
+		body = c.BodyText
+	}
+	b.WriteString(blockQuote(body))
+	b.WriteString("\n\n")
+}
+
+// codeFenceFor returns a backtick fence longer than any run of backticks in content.
+func codeFenceFor(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+			continue
+		}
+		run = 0
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+func threadHeading(t Thread) string {
+	if IsTimelineEvent(t.Root.Type) {
+		return "Timeline"
+	}
+	if t.Path == "" {
+		return "Conversation"
+	}
+	if t.Line != nil {
+		return fmt.Sprintf("%s:%d", t.Path, *t.Line)
+	}
+	return t.Path
+}
+
 func blockQuote(body string) string {
 	if body == "" {
 		return "> (empty)"
@@ -126,3 +243,18 @@ func flattenCommentGroups(groups []AuthorComments) []Comment {
 	})
 	return flat
 }
+
+func flattenThreads(threads []Thread) []Comment {
+	flat := make([]Comment, 0, len(threads))
+	for _, thread := range threads {
+		flat = append(flat, thread.Root)
+		flat = append(flat, thread.Replies...)
+	}
+	sort.SliceStable(flat, func(i, j int) bool {
+		if flat[i].CreatedAt.Equal(flat[j].CreatedAt) {
+			return flat[i].ID > flat[j].ID
+		}
+		return flat[i].CreatedAt.After(flat[j].CreatedAt)
+	})
+	return flat
+}
@@ -96,3 +96,47 @@ func TestMarshalJSONIncludesCommentCount(t *testing.T) {
 		t.Fatalf("expected payload to include comment_count, got %q", string(payload))
 	}
 }
+
+func TestRenderMarkdownPrefersMarkdownBody(t *testing.T) {
+	out := Output{
+		PR: PullRequestMetadata{Repo: "owner/repo", Number: 3},
+		Comments: []AuthorComments{
+			{Author: "octocat", Comments: []Comment{{
+				Type:         "issue",
+				Author:       "octocat",
+				BodyText:     "Try this:",
+				BodyMarkdown: "Try this:\n\n```go\nreturn nil\n```",
+			}}},
+		},
+	}
+
+	markup := RenderMarkdown(out)
+	if !strings.Contains(markup, "> ```go\n> return nil\n> ```") {
+		t.Fatalf("expected code fence to survive in markdown output, got:\n%s", markup)
+	}
+}
+
+func TestRenderMarkdownIncludesDiffHunk(t *testing.T) {
+	start, line := 4, 6
+	out := Output{
+		PR: PullRequestMetadata{Repo: "owner/repo", Number: 5},
+		Comments: []AuthorComments{
+			{Author: "octocat", Comments: []Comment{{
+				Type:      "review_comment",
+				Author:    "octocat",
+				Path:      "main.go",
+				StartLine: &start,
+				Line:      &line,
+				DiffHunk:  "@@ -1,3 +1,3 @@\n-old\n+new",
+				BodyText:  "why?",
+			}}},
+		},
+	}
+
+	markup := RenderMarkdown(out)
+	for _, want := range []string{"- Lines: 4-6", "```diff\n@@ -1,3 +1,3 @@\n-old\n+new\n```", "> why?"} {
+		if !strings.Contains(markup, want) {
+			t.Fatalf("expected markdown to contain %q, got:\n%s", want, markup)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package ghprcomments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const resolveThreadMutation = `mutation($id: ID!) {
+  resolveReviewThread(input: {threadId: $id}) { thread { id isResolved } }
+}`
+
+const unresolveThreadMutation = `mutation($id: ID!) {
+  unresolveReviewThread(input: {threadId: $id}) { thread { id isResolved } }
+}`
+
+// SetThreadResolved resolves or unresolves the review thread with GraphQL node ID threadID.
+func (f *Fetcher) SetThreadResolved(ctx context.Context, threadID string, resolved bool) error {
+	if threadID == "" {
+		return errors.New("review thread ID is empty")
+	}
+	mutation := resolveThreadMutation
+	if !resolved {
+		mutation = unresolveThreadMutation
+	}
+	var data map[string]any
+	if err := f.graphQL(ctx, mutation, map[string]any{"id": threadID}, &data); err != nil {
+		return fmt.Errorf("set thread %s resolved=%t: %w", threadID, resolved, err)
+	}
+	return nil
+}
+
+// ThreadFilter selects review threads. Every non-zero field must match.
+type ThreadFilter struct {
+	// CommentIDs matches threads containing any of these comments.
+	CommentIDs []int64
+	// Locations matches threads started at any of these path:line positions.
+	Locations []ThreadLocation
+	// Author matches threads started by this login (case-insensitive).
+	Author string
+	// Bots matches threads started by bots.
+	Bots bool
+	// Outdated matches threads whose code has since changed.
+	Outdated bool
+}
+
+// ThreadLocation is a file position; Line 0 matches anywhere in the file.
+type ThreadLocation struct {
+	Path string
+	Line int
+}
+
+// ReviewThread summarises one review thread for resolve.
+type ReviewThread struct {
+	ID       string
+	Root     Comment
+	Resolved bool
+	Outdated bool
+	Comments int
+}
+
+// Location renders the thread's position as path:line.
+func (t ReviewThread) Location() string {
+	if line := commentLine(t.Root); line > 0 {
+		return fmt.Sprintf("%s:%d", t.Root.Path, line)
+	}
+	return t.Root.Path
+}
+
+var discussionIDPattern = regexp.MustCompile(`discussion_r(\d+)$`)
+
+// ParseThreadTarget reads a resolve target: a comment ID, a review comment
+// permalink (…#discussion_r123), or a path:line location.
+func ParseThreadTarget(target string, filter *ThreadFilter) error {
+	target = strings.TrimSpace(target)
+	if id, err := strconv.ParseInt(target, 10, 64); err == nil && id > 0 {
+		filter.CommentIDs = append(filter.CommentIDs, id)
+		return nil
+	}
+	if m := discussionIDPattern.FindStringSubmatch(target); m != nil {
+		id, _ := strconv.ParseInt(m[1], 10, 64)
+		filter.CommentIDs = append(filter.CommentIDs, id)
+		return nil
+	}
+	path, lineText, hasLine := strings.Cut(target, ":")
+	if path == "" {
+		return fmt.Errorf("invalid target %q (want a comment ID, permalink or path:line)", target)
+	}
+	loc := ThreadLocation{Path: path}
+	if hasLine {
+		line, err := strconv.Atoi(lineText)
+		if err != nil || line <= 0 {
+			return fmt.Errorf("invalid line in %q", target)
+		}
+		loc.Line = line
+	}
+	filter.Locations = append(filter.Locations, loc)
+	return nil
+}
+
+// IsEmpty reports whether the filter would match every thread.
+func (f ThreadFilter) IsEmpty() bool {
+	return len(f.CommentIDs) == 0 && len(f.Locations) == 0 && f.Author == "" && !f.Bots && !f.Outdated
+}
+
+// SelectThreads groups out's review comments into threads and returns those
+// matching filter, in path and line order.
+func SelectThreads(out Output, filter ThreadFilter) []ReviewThread {
+	byID := make(map[string][]Comment)
+	for _, c := range outputComments(out) {
+		if c.Type == "review_comment" && c.ThreadID != "" {
+			byID[c.ThreadID] = append(byID[c.ThreadID], c)
+		}
+	}
+
+	var threads []ReviewThread
+	for id, comments := range byID {
+		comments = chronological(comments)
+		root := comments[0]
+		for _, c := range comments {
+			if c.InReplyTo == 0 {
+				root = c
+				break
+			}
+		}
+		thread := ReviewThread{
+			ID:       id,
+			Root:     root,
+			Resolved: root.Resolved != nil && *root.Resolved,
+			Outdated: root.Outdated != nil && *root.Outdated,
+			Comments: len(comments),
+		}
+		if filter.matches(thread, comments) {
+			threads = append(threads, thread)
+		}
+	}
+
+	sort.Slice(threads, func(i, j int) bool {
+		if threads[i].Root.Path != threads[j].Root.Path {
+			return threads[i].Root.Path < threads[j].Root.Path
+		}
+		return commentLine(threads[i].Root) < commentLine(threads[j].Root)
+	})
+	return threads
+}
+
+func (f ThreadFilter) matches(thread ReviewThread, comments []Comment) bool {
+	if len(f.CommentIDs) > 0 && !containsComment(comments, f.CommentIDs) {
+		return false
+	}
+	if len(f.Locations) > 0 && !matchesLocation(thread.Root, f.Locations) {
+		return false
+	}
+	if f.Author != "" && !strings.EqualFold(thread.Root.Author, strings.TrimPrefix(f.Author, "@")) {
+		return false
+	}
+	if f.Bots && !thread.Root.IsBot {
+		return false
+	}
+	if f.Outdated && !thread.Outdated {
+		return false
+	}
+	return true
+}
+
+func containsComment(comments []Comment, ids []int64) bool {
+	for _, c := range comments {
+		for _, id := range ids {
+			if c.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesLocation(root Comment, locations []ThreadLocation) bool {
+	for _, loc := range locations {
+		if root.Path != loc.Path {
+			continue
+		}
+		if loc.Line == 0 {
+			return true
+		}
+		end := commentLine(root)
+		start := end
+		if root.StartLine != nil {
+			start = *root.StartLine
+		}
+		if loc.Line >= start && loc.Line <= end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetThreadResolved(t *testing.T) {
+	var queries []string
+	server, client := mockGitHubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Variables["id"] != "T_1" {
+			t.Errorf("unexpected thread id %v", req.Variables["id"])
+		}
+		queries = append(queries, req.Query)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.Query, "unresolveReviewThread") {
+			w.Write([]byte(`{"errors":[{"message":"Resource not accessible by integration"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"resolveReviewThread":{"thread":{"id":"T_1","isResolved":true}}}}`))
+	})
+	defer server.Close()
+
+	fetcher := NewFetcher(client)
+	if err := fetcher.SetThreadResolved(context.Background(), "T_1", true); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	err := fetcher.SetThreadResolved(context.Background(), "T_1", false)
+	if err == nil || !strings.Contains(err.Error(), "not accessible") {
+		t.Fatalf("expected the GraphQL error, got %v", err)
+	}
+	if len(queries) != 2 || !strings.Contains(queries[0], "resolveReviewThread(") || !strings.Contains(queries[1], "unresolveReviewThread(") {
+		t.Fatalf("unexpected mutations %q", queries)
+	}
+}
+
+func TestParseThreadTarget(t *testing.T) {
+	var filter ThreadFilter
+	for _, target := range []string{"42", "https://github.com/o/r/pull/1#discussion_r77", "src/a.go:12", "README.md"} {
+		if err := ParseThreadTarget(target, &filter); err != nil {
+			t.Fatalf("ParseThreadTarget(%q): %v", target, err)
+		}
+	}
+	if len(filter.CommentIDs) != 2 || filter.CommentIDs[0] != 42 || filter.CommentIDs[1] != 77 {
+		t.Fatalf("comment IDs = %v", filter.CommentIDs)
+	}
+	want := []ThreadLocation{{Path: "src/a.go", Line: 12}, {Path: "README.md"}}
+	if len(filter.Locations) != 2 || filter.Locations[0] != want[0] || filter.Locations[1] != want[1] {
+		t.Fatalf("locations = %+v", filter.Locations)
+	}
+
+	for _, target := range []string{"a.go:x", ":3", "a.go:0"} {
+		if err := ParseThreadTarget(target, &ThreadFilter{}); err == nil {
+			t.Fatalf("expected %q to be rejected", target)
+		}
+	}
+}
+
+func TestSelectThreads(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	yes, no := true, false
+	line := func(n int) *int { return &n }
+	comment := func(id, replyTo int64, thread, author string, bot bool, path string, ln int, outdated *bool) Comment {
+		return Comment{
+			Type: "review_comment", ID: id, InReplyTo: replyTo, ThreadID: thread, Author: author, IsBot: bot,
+			Path: path, Line: line(ln), Resolved: &no, Outdated: outdated, CreatedAt: base.Add(time.Duration(id) * time.Minute),
+		}
+	}
+	all := []Comment{
+		comment(1, 0, "T1", "lint[bot]", true, "b.go", 10, &yes),
+		comment(2, 1, "T1", "alice", false, "b.go", 10, &yes),
+		comment(3, 0, "T2", "lint[bot]", true, "a.go", 5, &no),
+		comment(4, 0, "T3", "bob", false, "a.go", 20, &no),
+		{Type: "issue", ID: 5, Author: "lint[bot]", IsBot: true, CreatedAt: base},
+	}
+	out := groupOutput(Output{}, all, GroupByAuthor)
+
+	ids := func(threads []ReviewThread) string {
+		var parts []string
+		for _, thread := range threads {
+			parts = append(parts, thread.ID)
+		}
+		return strings.Join(parts, ",")
+	}
+
+	tests := []struct {
+		name   string
+		filter ThreadFilter
+		want   string
+	}{
+		{name: "all", filter: ThreadFilter{}, want: "T2,T3,T1"},
+		{name: "reply id", filter: ThreadFilter{CommentIDs: []int64{2}}, want: "T1"},
+		{name: "location", filter: ThreadFilter{Locations: []ThreadLocation{{Path: "a.go", Line: 20}}}, want: "T3"},
+		{name: "whole file", filter: ThreadFilter{Locations: []ThreadLocation{{Path: "a.go"}}}, want: "T2,T3"},
+		{name: "bots", filter: ThreadFilter{Bots: true}, want: "T2,T1"},
+		{name: "author", filter: ThreadFilter{Author: "@LINT[bot]"}, want: "T2,T1"},
+		{name: "outdated bots", filter: ThreadFilter{Bots: true, Outdated: true}, want: "T1"},
+		{name: "reply author does not start threads", filter: ThreadFilter{Author: "alice"}, want: ""},
+	}
+	for _, tc := range tests {
+		if got := ids(SelectThreads(out, tc.filter)); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	threads := SelectThreads(out, ThreadFilter{CommentIDs: []int64{1}})
+	if threads[0].Root.ID != 1 || threads[0].Comments != 2 || !threads[0].Outdated || threads[0].Location() != "b.go:10" {
+		t.Fatalf("unexpected thread summary %+v", threads[0])
+	}
+}
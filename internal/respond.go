@@ -0,0 +1,131 @@
+package ghprcomments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// ReactionContents lists the reactions GitHub accepts, in its display order.
+var ReactionContents = []string{"+1", "-1", "laugh", "confused", "heart", "hooray", "rocket", "eyes"}
+
+// Responder posts replies and reactions and resolves threads on one pull request.
+type Responder struct {
+	fetcher *Fetcher
+	owner   string
+	repo    string
+	number  int
+}
+
+// NewResponder returns a Responder for owner/repo#number.
+func NewResponder(fetcher *Fetcher, owner, repo string, number int) *Responder {
+	return &Responder{fetcher: fetcher, owner: owner, repo: repo, number: number}
+}
+
+// Reply answers to. Review comments get a threaded reply; anything else gets a
+// new conversation comment that quotes the original. It returns the posted comment.
+func (r *Responder) Reply(ctx context.Context, to Comment, body string) (Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return Comment{}, errors.New("reply is empty")
+	}
+
+	if to.Type == "review_comment" {
+		if to.ID == 0 {
+			return Comment{}, errors.New("comment has no ID")
+		}
+		// GitHub threads replies under the comment that started the conversation.
+		target := to.ID
+		if to.InReplyTo != 0 {
+			target = to.InReplyTo
+		}
+		posted, _, err := r.fetcher.client.PullRequests.CreateCommentInReplyTo(ctx, r.owner, r.repo, r.number, body, target)
+		if err != nil {
+			return Comment{}, fmt.Errorf("reply to review comment %d: %w", target, err)
+		}
+		return normalizeReviewComment(posted, NormalizationOptions{}), nil
+	}
+
+	posted, _, err := r.fetcher.client.Issues.CreateComment(ctx, r.owner, r.repo, r.number, &github.IssueComment{
+		Body: github.String(QuoteReply(to, body)),
+	})
+	if err != nil {
+		return Comment{}, fmt.Errorf("post comment: %w", err)
+	}
+	return normalizeIssueComment(posted, NormalizationOptions{}), nil
+}
+
+// React adds an emoji reaction, one of ReactionContents, to a review or issue comment.
+func (r *Responder) React(ctx context.Context, to Comment, content string) error {
+	if !slices.Contains(ReactionContents, content) {
+		return fmt.Errorf("unknown reaction %q (want %s)", content, strings.Join(ReactionContents, ", "))
+	}
+	if to.ID == 0 {
+		return errors.New("comment has no ID")
+	}
+
+	var err error
+	switch to.Type {
+	case "review_comment":
+		_, _, err = r.fetcher.client.Reactions.CreatePullRequestCommentReaction(ctx, r.owner, r.repo, to.ID, content)
+	case "issue":
+		_, _, err = r.fetcher.client.Reactions.CreateIssueCommentReaction(ctx, r.owner, r.repo, to.ID, content)
+	default:
+		return fmt.Errorf("reactions are not supported on %s entries", formatCommentType(to.Type))
+	}
+	if err != nil {
+		return fmt.Errorf("react to comment %d: %w", to.ID, err)
+	}
+	return nil
+}
+
+// SetResolved resolves or unresolves the review thread to belongs to.
+func (r *Responder) SetResolved(ctx context.Context, to Comment, resolved bool) error {
+	if to.ThreadID == "" {
+		return errors.New("comment is not part of a review thread")
+	}
+	return r.fetcher.SetThreadResolved(ctx, to.ThreadID, resolved)
+}
+
+// QuoteReply prefixes body with a Markdown quote of the comment it answers.
+func QuoteReply(to Comment, body string) string {
+	original := to.BodyMarkdown
+	if original == "" {
+		original = to.BodyText
+	}
+
+	var b strings.Builder
+	if to.Author != "" {
+		fmt.Fprintf(&b, "> @%s wrote:\n>\n", to.Author)
+	}
+	b.WriteString(blockQuote(strings.TrimSpace(original)))
+	b.WriteString("\n\n")
+	b.WriteString(body)
+	return b.String()
+}
+
+func reactionCounts(r *github.Reactions) map[string]int {
+	if r.GetTotalCount() == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for content, n := range map[string]int{
+		"+1":       r.GetPlusOne(),
+		"-1":       r.GetMinusOne(),
+		"laugh":    r.GetLaugh(),
+		"confused": r.GetConfused(),
+		"heart":    r.GetHeart(),
+		"hooray":   r.GetHooray(),
+		"rocket":   r.GetRocket(),
+		"eyes":     r.GetEyes(),
+	} {
+		if n > 0 {
+			counts[content] = n
+		}
+	}
+	return counts
+}
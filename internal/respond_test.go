@@ -0,0 +1,124 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestResponderReplyToReviewComment(t *testing.T) {
+	var got struct {
+		Body      string `json:"body"`
+		InReplyTo int64  `json:"in_reply_to"`
+	}
+	server, client := mockGitHubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/octo/repo/pulls/7/comments" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 900, "in_reply_to_id": 11, "body": "done", "path": "a.go", "user": {"login": "me"}}`))
+	})
+	defer server.Close()
+
+	responder := NewResponder(NewFetcher(client), "octo", "repo", 7)
+	reply, err := responder.Reply(context.Background(), Comment{Type: "review_comment", ID: 12, InReplyTo: 11}, "  done ")
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if got.InReplyTo != 11 || got.Body != "done" {
+		t.Fatalf("expected a trimmed reply to the thread root, got %+v", got)
+	}
+	if reply.ID != 900 || reply.Type != "review_comment" || reply.Author != "me" {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+}
+
+func TestResponderReplyQuotesIssueComments(t *testing.T) {
+	var body string
+	server, client := mockGitHubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/octo/repo/issues/7/comments" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		var payload struct {
+			Body string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		body = payload.Body
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 901, "body": "x", "user": {"login": "me"}}`))
+	})
+	defer server.Close()
+
+	responder := NewResponder(NewFetcher(client), "octo", "repo", 7)
+	to := Comment{Type: "issue", ID: 5, Author: "alice", BodyMarkdown: "Can we\nsplit this?"}
+	if _, err := responder.Reply(context.Background(), to, "Yes, done."); err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	want := "> @alice wrote:\n>\n> Can we\n> split this?\n\nYes, done."
+	if body != want {
+		t.Fatalf("body = %q, want %q", body, want)
+	}
+
+	if _, err := responder.Reply(context.Background(), to, "   "); err == nil {
+		t.Fatal("expected empty replies to be rejected")
+	}
+}
+
+func TestResponderReact(t *testing.T) {
+	var paths []string
+	server, client := mockGitHubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Content string `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		paths = append(paths, r.URL.Path+" "+payload.Content)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "content": "` + payload.Content + `"}`))
+	})
+	defer server.Close()
+
+	responder := NewResponder(NewFetcher(client), "octo", "repo", 7)
+	ctx := context.Background()
+	if err := responder.React(ctx, Comment{Type: "review_comment", ID: 12}, "+1"); err != nil {
+		t.Fatalf("React review comment: %v", err)
+	}
+	if err := responder.React(ctx, Comment{Type: "issue", ID: 5}, "rocket"); err != nil {
+		t.Fatalf("React issue comment: %v", err)
+	}
+	want := "/repos/octo/repo/pulls/comments/12/reactions +1,/repos/octo/repo/issues/comments/5/reactions rocket"
+	if got := strings.Join(paths, ","); got != want {
+		t.Fatalf("requests = %q, want %q", got, want)
+	}
+
+	if err := responder.React(ctx, Comment{Type: "review_event", ID: 3}, "heart"); err == nil {
+		t.Fatal("expected reviews to reject reactions")
+	}
+	if err := responder.React(ctx, Comment{Type: "issue", ID: 5}, "party"); err == nil {
+		t.Fatal("expected unknown reactions to be rejected")
+	}
+}
+
+func TestReactionCounts(t *testing.T) {
+	var ic github.IssueComment
+	if err := json.Unmarshal([]byte(`{"id": 1, "reactions": {"total_count": 3, "+1": 2, "eyes": 1, "heart": 0}}`), &ic); err != nil {
+		t.Fatal(err)
+	}
+	c := normalizeIssueComment(&ic, NormalizationOptions{})
+	if len(c.Reactions) != 2 || c.Reactions["+1"] != 2 || c.Reactions["eyes"] != 1 {
+		t.Fatalf("unexpected reactions %v", c.Reactions)
+	}
+	if c := normalizeIssueComment(&github.IssueComment{ID: github.Int64(2)}, NormalizationOptions{}); c.Reactions != nil {
+		t.Fatalf("expected no reactions, got %v", c.Reactions)
+	}
+}
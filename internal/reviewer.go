@@ -0,0 +1,318 @@
+package ghprcomments
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Reviewer thread statuses, from the reviewer's point of view.
+const (
+	ReviewerStatusNeedsReReview  = "needs_rereview"
+	ReviewerStatusAwaitingAuthor = "awaiting_author"
+	ReviewerStatusResolved       = "resolved"
+)
+
+// ReviewerReport lists the review threads a user started across pull requests.
+type ReviewerReport struct {
+	User         string          `json:"user"`
+	PRCount      int             `json:"pr_count"`
+	ThreadCount  int             `json:"thread_count"`
+	PullRequests []ReviewerEntry `json:"pull_requests"`
+	Warnings     []string        `json:"warnings,omitempty"`
+}
+
+// ReviewerEntry holds the reviewer's threads on one pull request.
+type ReviewerEntry struct {
+	PR      PullRequestMetadata `json:"pr"`
+	Threads []ReviewerThread    `json:"threads"`
+}
+
+// ReviewerThread summarises one thread the reviewer started and where it stands.
+type ReviewerThread struct {
+	Status        string     `json:"status"`
+	Path          string     `json:"path,omitempty"`
+	Line          *int       `json:"line,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	BodyText      string     `json:"body_text"`
+	Permalink     string     `json:"permalink"`
+	Open          bool       `json:"open"`
+	Replies       int        `json:"replies"`
+	AuthorReplied bool       `json:"author_replied"`
+	LastReplyBy   string     `json:"last_reply_by,omitempty"`
+	LastReplyAt   *time.Time `json:"last_reply_at,omitempty"`
+	PathChanged   bool       `json:"path_changed_since_comment"`
+	Outdated      bool       `json:"outdated"`
+}
+
+// ReviewerOptions configures how the reviewer report is collected.
+type ReviewerOptions struct {
+	Normalization NormalizationOptions
+	// Concurrency bounds parallel per-PR fetches; values below 1 mean 1.
+	Concurrency int
+	// OpenOnly drops threads that have been resolved.
+	OpenOnly bool
+}
+
+// FetchReviewerReport collects the review threads user started on prs. For
+// each thread it checks whether the PR author replied and whether a later
+// commit touched the commented path. Pull requests that cannot be loaded are
+// reported as warnings.
+func (f *Fetcher) FetchReviewerReport(ctx context.Context, user string, prs []*PullRequestSummary, opts ReviewerOptions) (ReviewerReport, error) {
+	entries := make([]*ReviewerEntry, len(prs))
+	warnings := make([][]string, len(prs))
+
+	fetcher := f.WithThreads()
+	normOpts := opts.Normalization
+	normOpts.Threads = true
+	normOpts.UnresolvedOnly = false
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(opts.Concurrency, 1))
+	for i, pr := range prs {
+		i, pr := i, pr
+		group.Go(func() error {
+			entry, entryWarnings, err := fetcher.reviewerEntry(groupCtx, user, pr, normOpts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				warnings[i] = []string{fmt.Sprintf("%s/%s#%d: %v", pr.RepoOwner, pr.RepoName, pr.Number, err)}
+				return nil
+			}
+			entries[i] = &entry
+			warnings[i] = entryWarnings
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return ReviewerReport{}, err
+	}
+
+	report := ReviewerReport{User: user, PullRequests: []ReviewerEntry{}}
+	for i, entry := range entries {
+		report.Warnings = append(report.Warnings, warnings[i]...)
+		if entry == nil {
+			continue
+		}
+		if opts.OpenOnly {
+			open := entry.Threads[:0]
+			for _, thread := range entry.Threads {
+				if thread.Open {
+					open = append(open, thread)
+				}
+			}
+			entry.Threads = open
+		}
+		if len(entry.Threads) == 0 {
+			continue
+		}
+		report.PullRequests = append(report.PullRequests, *entry)
+		report.ThreadCount += len(entry.Threads)
+	}
+	sortReviewerEntries(report.PullRequests)
+	report.PRCount = len(report.PullRequests)
+	return report, nil
+}
+
+// reviewerEntry builds the entry for pr. Comparisons that fail leave
+// path_changed_since_comment false and are returned as warnings.
+func (f *Fetcher) reviewerEntry(ctx context.Context, user string, pr *PullRequestSummary, opts NormalizationOptions) (ReviewerEntry, []string, error) {
+	// Search results lack the head commit needed to look for later changes.
+	if err := f.FillBranches(ctx, pr); err != nil {
+		return ReviewerEntry{}, nil, err
+	}
+
+	payload, err := f.FetchComments(ctx, pr.RepoOwner, pr.RepoName, pr.Number)
+	if err != nil {
+		return ReviewerEntry{}, nil, err
+	}
+	out := BuildOutput(pr, payload, opts)
+
+	var warnings []string
+	for _, warning := range payload.Warnings() {
+		warnings = append(warnings, fmt.Sprintf("%s/%s#%d: %s", pr.RepoOwner, pr.RepoName, pr.Number, warning))
+	}
+
+	// One comparison per distinct base commit; comments on the same commit share it.
+	changedByCommit := make(map[string]map[string]bool)
+	pathChanged := func(commit, path string) bool {
+		if commit == "" || path == "" || pr.HeadSHA == "" || commit == pr.HeadSHA {
+			return false
+		}
+		files, ok := changedByCommit[commit]
+		if !ok {
+			// Force-pushed commits can disappear, which leaves the paths unknown.
+			var err error
+			files, err = f.changedFiles(ctx, pr.RepoOwner, pr.RepoName, commit, pr.HeadSHA)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s/%s#%d: compare %s...%s: %v; path changes since those comments are unknown", pr.RepoOwner, pr.RepoName, pr.Number, ShortSHA(commit), ShortSHA(pr.HeadSHA), err))
+			}
+			changedByCommit[commit] = files
+		}
+		return files[path]
+	}
+
+	return buildReviewerEntry(user, out, pathChanged), warnings, nil
+}
+
+// changedFiles lists the paths that differ between base and head.
+func (f *Fetcher) changedFiles(ctx context.Context, owner, repo, base, head string) (map[string]bool, error) {
+	comparison, _, err := f.client.Repositories.CompareCommits(ctx, owner, repo, base, head, nil)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]bool, len(comparison.Files))
+	for _, file := range comparison.Files {
+		files[file.GetFilename()] = true
+		if previous := file.GetPreviousFilename(); previous != "" {
+			files[previous] = true
+		}
+	}
+	return files, nil
+}
+
+// buildReviewerEntry picks out the review threads user started in out.
+// pathChanged reports whether path changed after the given commit.
+func buildReviewerEntry(user string, out Output, pathChanged func(commit, path string) bool) ReviewerEntry {
+	entry := ReviewerEntry{PR: out.PR, Threads: []ReviewerThread{}}
+
+	for _, thread := range out.Threads {
+		root := thread.Root
+		if root.Type != "review_comment" || !strings.EqualFold(root.Author, user) {
+			continue
+		}
+
+		rt := ReviewerThread{
+			Path:      root.Path,
+			Line:      root.Line,
+			CreatedAt: root.CreatedAt,
+			BodyText:  root.BodyText,
+			Permalink: root.Permalink,
+			Open:      thread.Resolved == nil || !*thread.Resolved,
+			Replies:   len(thread.Replies),
+			Outdated:  thread.Outdated != nil && *thread.Outdated,
+		}
+
+		// The reviewer's most recent word in the thread, and anyone answering after it.
+		lastMine := root
+		for _, reply := range thread.Replies {
+			if strings.EqualFold(reply.Author, user) {
+				lastMine = reply
+				continue
+			}
+			if strings.EqualFold(reply.Author, out.PR.Author) {
+				rt.AuthorReplied = true
+			}
+		}
+		answered := false
+		if n := len(thread.Replies); n > 0 {
+			last := thread.Replies[n-1]
+			at := last.CreatedAt
+			rt.LastReplyBy = last.Author
+			rt.LastReplyAt = &at
+			answered = !strings.EqualFold(last.Author, user)
+		}
+		rt.PathChanged = pathChanged(reviewedCommit(lastMine), root.Path)
+
+		switch {
+		case !rt.Open:
+			rt.Status = ReviewerStatusResolved
+		case answered || rt.PathChanged:
+			rt.Status = ReviewerStatusNeedsReReview
+		default:
+			rt.Status = ReviewerStatusAwaitingAuthor
+		}
+		entry.Threads = append(entry.Threads, rt)
+	}
+
+	sort.SliceStable(entry.Threads, func(i, j int) bool {
+		ri, rj := reviewerStatusRank(entry.Threads[i].Status), reviewerStatusRank(entry.Threads[j].Status)
+		if ri != rj {
+			return ri < rj
+		}
+		return entry.Threads[i].CreatedAt.After(entry.Threads[j].CreatedAt)
+	})
+	return entry
+}
+
+// reviewedCommit is the commit c was written against. GitHub moves commit_id
+// forward while the comment still applies, so only original_commit_id says
+// what the reviewer actually saw.
+func reviewedCommit(c Comment) string {
+	if c.OriginalCommitID != "" {
+		return c.OriginalCommitID
+	}
+	return c.CommitID
+}
+
+// reviewerStatusRank puts threads blocked on the reviewer first.
+func reviewerStatusRank(status string) int {
+	switch status {
+	case ReviewerStatusNeedsReReview:
+		return 0
+	case ReviewerStatusAwaitingAuthor:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// sortReviewerEntries orders pull requests by how many threads need the
+// reviewer's attention, then by PR update time.
+func sortReviewerEntries(entries []ReviewerEntry) {
+	pending := func(e ReviewerEntry) int {
+		n := 0
+		for _, thread := range e.Threads {
+			if thread.Status == ReviewerStatusNeedsReReview {
+				n++
+			}
+		}
+		return n
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		pi, pj := pending(entries[i]), pending(entries[j])
+		if pi != pj {
+			return pi > pj
+		}
+		return entries[i].PR.UpdatedAt.After(entries[j].PR.UpdatedAt)
+	})
+}
+
+// RenderReviewerMarkdown emits the reviewer report grouped by pull request.
+func RenderReviewerMarkdown(report ReviewerReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Review threads by %s\n\n", safeMarkdownValue(report.User))
+	fmt.Fprintf(&b, "- Pull requests: %d\n", report.PRCount)
+	fmt.Fprintf(&b, "- Threads: %d\n\n", report.ThreadCount)
+
+	for _, entry := range report.PullRequests {
+		fmt.Fprintf(&b, "## %s#%d: %s\n\n", safeMarkdownValue(entry.PR.Repo), entry.PR.Number, safeMarkdownValue(entry.PR.Title))
+		for _, thread := range entry.Threads {
+			location := safeMarkdownValue(thread.Path)
+			if thread.Line != nil {
+				location = fmt.Sprintf("%s:%d", location, *thread.Line)
+			}
+			fmt.Fprintf(&b, "### %s — %s\n", location, strings.ReplaceAll(thread.Status, "_", " "))
+			fmt.Fprintf(&b, "- Author replied: %s\n", yesNo(thread.AuthorReplied))
+			fmt.Fprintf(&b, "- Path changed since comment: %s\n", yesNo(thread.PathChanged))
+			if thread.LastReplyAt != nil {
+				fmt.Fprintf(&b, "- Last reply: %s at %s\n", safeMarkdownValue(thread.LastReplyBy), thread.LastReplyAt.Format(time.RFC3339))
+			}
+			if thread.Permalink != "" {
+				fmt.Fprintf(&b, "- Link: %s\n", thread.Permalink)
+			}
+			if body := strings.TrimSpace(thread.BodyText); body != "" {
+				fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(body, "\n", "\n> "))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSpace(b.String()) + "\n"
+}
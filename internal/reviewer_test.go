@@ -0,0 +1,152 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestBuildReviewerEntry(t *testing.T) {
+	base := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	resolved := true
+	comments := []Comment{
+		// Author answered: ball is back with the reviewer.
+		{Type: "review_comment", ID: 1, Author: "me", Path: "a.go", CommitID: "c1", CreatedAt: base},
+		{Type: "review_comment", ID: 2, InReplyTo: 1, Author: "author", CreatedAt: base.Add(time.Hour)},
+		// Reviewer had the last word and nothing changed: waiting on the author.
+		{Type: "review_comment", ID: 3, Author: "me", Path: "b.go", CommitID: "c1", CreatedAt: base.Add(2 * time.Hour)},
+		{Type: "review_comment", ID: 4, InReplyTo: 3, Author: "author", CreatedAt: base.Add(3 * time.Hour)},
+		{Type: "review_comment", ID: 5, InReplyTo: 3, Author: "me", CommitID: "c2", CreatedAt: base.Add(4 * time.Hour)},
+		// No reply but the file changed since.
+		{Type: "review_comment", ID: 6, Author: "me", Path: "c.go", CommitID: "c1", CreatedAt: base.Add(5 * time.Hour)},
+		// Resolved thread.
+		{Type: "review_comment", ID: 7, Author: "me", Path: "d.go", CommitID: "c1", CreatedAt: base.Add(6 * time.Hour), Resolved: &resolved},
+		// Someone else's thread and a top-level comment are ignored.
+		{Type: "review_comment", ID: 8, Author: "other", Path: "e.go", CreatedAt: base},
+		{Type: "issue", ID: 9, Author: "me", CreatedAt: base},
+	}
+	out := Output{PR: PullRequestMetadata{Author: "author", Number: 5}, Threads: buildThreads(comments)}
+
+	changed := map[string]bool{"c1:c.go": true, "c2:b.go": false}
+	entry := buildReviewerEntry("me", out, func(commit, path string) bool {
+		return changed[commit+":"+path]
+	})
+
+	got := make(map[string]ReviewerThread)
+	for _, thread := range entry.Threads {
+		got[thread.Path] = thread
+	}
+	if len(entry.Threads) != 4 {
+		t.Fatalf("expected 4 threads started by me, got %+v", entry.Threads)
+	}
+
+	if a := got["a.go"]; a.Status != ReviewerStatusNeedsReReview || !a.AuthorReplied || a.LastReplyBy != "author" {
+		t.Errorf("a.go: unexpected %+v", a)
+	}
+	if b := got["b.go"]; b.Status != ReviewerStatusAwaitingAuthor || !b.AuthorReplied || b.Replies != 2 {
+		t.Errorf("b.go: unexpected %+v", b)
+	}
+	if c := got["c.go"]; c.Status != ReviewerStatusNeedsReReview || !c.PathChanged || c.AuthorReplied {
+		t.Errorf("c.go: unexpected %+v", c)
+	}
+	if d := got["d.go"]; d.Status != ReviewerStatusResolved || d.Open {
+		t.Errorf("d.go: unexpected %+v", d)
+	}
+
+	if entry.Threads[0].Status != ReviewerStatusNeedsReReview || entry.Threads[3].Status != ReviewerStatusResolved {
+		t.Errorf("expected threads needing re-review first and resolved last, got %+v", entry.Threads)
+	}
+
+	markdown := RenderReviewerMarkdown(ReviewerReport{User: "me", PRCount: 1, ThreadCount: 4, PullRequests: []ReviewerEntry{entry}})
+	if !strings.Contains(markdown, "### c.go — needs rereview") || !strings.Contains(markdown, "- Path changed since comment: yes") {
+		t.Errorf("unexpected markdown:\n%s", markdown)
+	}
+}
+
+func TestFetchReviewerReport(t *testing.T) {
+	compares := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/pulls/3":
+			json.NewEncoder(w).Encode(&github.PullRequest{
+				Number: github.Int(3),
+				User:   &github.User{Login: github.String("author")},
+				Head:   &github.PullRequestBranch{SHA: github.String("head")},
+			})
+		case "/repos/owner/repo/issues/3/comments", "/repos/owner/repo/pulls/3/reviews":
+			w.Write([]byte(`[]`))
+		case "/repos/owner/repo/pulls/3/comments":
+			json.NewEncoder(w).Encode([]*github.PullRequestComment{
+				// commit_id has moved on to the head; original_commit_id is what was reviewed.
+				{ID: github.Int64(1), Body: github.String("rename this"), Path: github.String("main.go"), CommitID: github.String("head"), OriginalCommitID: github.String("old"), User: &github.User{Login: github.String("me")}},
+				{ID: github.Int64(2), Body: github.String("and this"), Path: github.String("util.go"), CommitID: github.String("head"), OriginalCommitID: github.String("old"), User: &github.User{Login: github.String("me")}},
+			})
+		case "/repos/owner/repo/compare/old...head":
+			compares++
+			w.Write([]byte(`{"files":[{"filename":"main.go"}]}`))
+		case "/graphql":
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[{"id":"T","isResolved":true,"isOutdated":false,"comments":{"nodes":[{"databaseId":2}]}}]}}}}}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	prs := []*PullRequestSummary{{Number: 3, RepoOwner: "owner", RepoName: "repo"}}
+	report, err := NewFetcher(client).FetchReviewerReport(context.Background(), "me", prs, ReviewerOptions{Concurrency: 1, OpenOnly: true})
+	if err != nil {
+		t.Fatalf("FetchReviewerReport failed: %v", err)
+	}
+
+	if report.PRCount != 1 || report.ThreadCount != 1 {
+		t.Fatalf("expected the resolved thread to be dropped, got %+v", report)
+	}
+	thread := report.PullRequests[0].Threads[0]
+	if thread.Path != "main.go" || !thread.PathChanged || thread.Status != ReviewerStatusNeedsReReview {
+		t.Fatalf("unexpected thread %+v", thread)
+	}
+	if compares != 1 {
+		t.Errorf("expected one comparison per base commit, got %d", compares)
+	}
+}
+
+func TestFetchReviewerReportWarnsOnFailedComparison(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/3/comments", "/repos/owner/repo/pulls/3/reviews":
+			w.Write([]byte(`[]`))
+		case "/repos/owner/repo/pulls/3/comments":
+			json.NewEncoder(w).Encode([]*github.PullRequestComment{
+				{ID: github.Int64(1), Body: github.String("rename this"), Path: github.String("main.go"), OriginalCommitID: github.String("gone"), User: &github.User{Login: github.String("me")}},
+			})
+		case "/repos/owner/repo/compare/gone...head":
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		case "/graphql":
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}}}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	prs := []*PullRequestSummary{{Number: 3, RepoOwner: "owner", RepoName: "repo", HeadSHA: "head"}}
+	report, err := NewFetcher(client).FetchReviewerReport(context.Background(), "me", prs, ReviewerOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatalf("FetchReviewerReport failed: %v", err)
+	}
+	if report.ThreadCount != 1 || report.PullRequests[0].Threads[0].PathChanged {
+		t.Fatalf("expected the thread to be kept without a path change, got %+v", report)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "compare gone...head") {
+		t.Fatalf("expected a comparison warning, got %v", report.Warnings)
+	}
+}
@@ -0,0 +1,371 @@
+package ghprcomments
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// statsTopFiles caps how many files the most-commented list keeps.
+const statsTopFiles = 5
+
+// StatsReport summarises review activity per pull request and across all of them.
+type StatsReport struct {
+	PullRequests []PullRequestStats `json:"pull_requests"`
+	Total        PullRequestStats   `json:"total"`
+	Warnings     []string           `json:"warnings,omitempty"`
+}
+
+// PullRequestStats holds the review metrics for one pull request, or the
+// totals when PR is nil. Durations are in seconds and omitted when unknown.
+type PullRequestStats struct {
+	PR            *PullRequestMetadata `json:"pr,omitempty"`
+	PullRequests  int                  `json:"pull_requests"`
+	Comments      int                  `json:"comments"`
+	HumanComments int                  `json:"human_comments"`
+	BotComments   int                  `json:"bot_comments"`
+	BotRatio      float64              `json:"bot_ratio"`
+	ByAuthor      []StatCount          `json:"by_author"`
+	ByType        []StatCount          `json:"by_type"`
+	TopFiles      []StatCount          `json:"top_files"`
+	// ReviewRounds counts change requests that were answered with new commits.
+	ReviewRounds int `json:"review_rounds"`
+	// TimeToFirstReview runs from opening (or leaving draft) to the first
+	// review from someone other than the author; the total is the median.
+	TimeToFirstReview *int64 `json:"time_to_first_review_seconds,omitempty"`
+	// AuthorResponse and ReviewerResponse are the median times each side took
+	// to answer the other.
+	AuthorResponse   *int64 `json:"author_response_seconds,omitempty"`
+	ReviewerResponse *int64 `json:"reviewer_response_seconds,omitempty"`
+
+	// Raw tallies and samples, kept so per-PR stats can be merged into totals.
+	authors, types, files          map[string]int
+	firstReviews                   []time.Duration
+	authorLatency, reviewerLatency []time.Duration
+}
+
+// StatCount is a name with how often it occurred.
+type StatCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// StatsOptions configures how statistics are collected.
+type StatsOptions struct {
+	Normalization NormalizationOptions
+	// Concurrency bounds parallel per-PR fetches; values below 1 mean 1.
+	Concurrency int
+}
+
+// FetchStats computes review statistics for prs. The timeline is fetched as
+// well so review rounds can see commits. Pull requests that cannot be loaded,
+// or can only be loaded in part, are reported as warnings.
+func (f *Fetcher) FetchStats(ctx context.Context, prs []*PullRequestSummary, opts StatsOptions) (StatsReport, error) {
+	fetcher := f.WithTimeline()
+	normOpts := opts.Normalization
+	normOpts.GroupBy = GroupByNone
+	normOpts.Threads = false
+	normOpts.UnresolvedOnly = false
+
+	entries := make([]*PullRequestStats, len(prs))
+	warnings := make([][]string, len(prs))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(opts.Concurrency, 1))
+	for i, pr := range prs {
+		i, pr := i, pr
+		group.Go(func() error {
+			payload, err := fetcher.FetchComments(groupCtx, pr.RepoOwner, pr.RepoName, pr.Number)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				warnings[i] = []string{fmt.Sprintf("%s/%s#%d: %v", pr.RepoOwner, pr.RepoName, pr.Number, err)}
+				return nil
+			}
+			for _, warning := range payload.Warnings() {
+				warnings[i] = append(warnings[i], fmt.Sprintf("%s/%s#%d: %s", pr.RepoOwner, pr.RepoName, pr.Number, warning))
+			}
+			stats := BuildStats(BuildOutput(pr, payload, normOpts), pr.Created)
+			entries[i] = &stats
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return StatsReport{}, err
+	}
+
+	report := StatsReport{PullRequests: []PullRequestStats{}}
+	for i, entry := range entries {
+		report.Warnings = append(report.Warnings, warnings[i]...)
+		if entry != nil {
+			report.PullRequests = append(report.PullRequests, *entry)
+		}
+	}
+	report.Total = mergeStats(report.PullRequests)
+	return report, nil
+}
+
+// BuildStats computes the metrics for one pull request from its output.
+// opened is when the pull request was created.
+func BuildStats(out Output, opened time.Time) PullRequestStats {
+	pr := out.PR
+	stats := PullRequestStats{
+		PR:           &pr,
+		PullRequests: 1,
+		authors:      make(map[string]int),
+		types:        make(map[string]int),
+		files:        make(map[string]int),
+	}
+
+	all := chronological(outputComments(out))
+	// Time spent as a draft does not count towards waiting for review.
+	reviewStart, leftDraft := opened, false
+	var pushes, changeRequests []time.Time
+	for _, c := range all {
+		switch c.Type {
+		case EventCommitted, EventForcePushed:
+			pushes = append(pushes, c.CreatedAt)
+		case EventReadyForReview:
+			if !leftDraft {
+				reviewStart, leftDraft = c.CreatedAt, true
+			}
+		}
+	}
+
+	var feedback []Comment
+	for _, c := range all {
+		if !countsAsFeedback(c) {
+			continue
+		}
+		feedback = append(feedback, c)
+		stats.Comments++
+		stats.authors[c.Author]++
+		stats.types[c.Type]++
+		if c.IsBot {
+			stats.BotComments++
+		} else {
+			stats.HumanComments++
+		}
+		if c.Type == "review_comment" && c.Path != "" {
+			stats.files[c.Path]++
+		}
+		if c.Type == "review_event" && strings.EqualFold(c.State, "CHANGES_REQUESTED") {
+			changeRequests = append(changeRequests, c.CreatedAt)
+		}
+	}
+
+	for _, c := range feedback {
+		if isReviewer(c, pr.Author) && (c.Type == "review_event" || c.Type == "review_comment") && !c.CreatedAt.Before(reviewStart) {
+			if !reviewStart.IsZero() {
+				stats.firstReviews = []time.Duration{c.CreatedAt.Sub(reviewStart)}
+			}
+			break
+		}
+	}
+	stats.ReviewRounds = reviewRounds(changeRequests, pushes)
+	stats.authorLatency, stats.reviewerLatency = responseLatencies(feedback, pr.Author)
+	stats.summarise()
+	return stats
+}
+
+// countsAsFeedback drops timeline events and the empty review events GitHub
+// records around a batch of inline comments.
+func countsAsFeedback(c Comment) bool {
+	if IsTimelineEvent(c.Type) || c.Draft {
+		return false
+	}
+	if c.Type == "review_event" && strings.TrimSpace(c.BodyText) == "" {
+		return !strings.EqualFold(c.State, "COMMENTED") && c.State != ""
+	}
+	return true
+}
+
+func isReviewer(c Comment, prAuthor string) bool {
+	return !c.IsBot && !strings.EqualFold(c.Author, prAuthor)
+}
+
+// reviewRounds counts change requests followed by a push before the next
+// change request, so several reviewers asking at once make one round.
+func reviewRounds(changeRequests, pushes []time.Time) int {
+	rounds := 0
+	for i, requested := range changeRequests {
+		var next time.Time
+		if i+1 < len(changeRequests) {
+			next = changeRequests[i+1]
+		}
+		for _, pushed := range pushes {
+			if pushed.After(requested) && (next.IsZero() || !pushed.After(next)) {
+				rounds++
+				break
+			}
+		}
+	}
+	return rounds
+}
+
+// responseLatencies walks the human conversation and measures, each time the
+// turn passes between the author and the reviewers, how long the first
+// unanswered message waited.
+func responseLatencies(feedback []Comment, prAuthor string) (author, reviewer []time.Duration) {
+	var waitingSince time.Time
+	waitingOnAuthor := false
+	for _, c := range feedback {
+		if c.IsBot || c.Author == "" {
+			continue
+		}
+		fromReviewer := isReviewer(c, prAuthor)
+		switch {
+		case waitingSince.IsZero():
+		case fromReviewer == waitingOnAuthor:
+			// Same side again; the turn has not passed.
+			continue
+		case waitingOnAuthor:
+			author = append(author, c.CreatedAt.Sub(waitingSince))
+		default:
+			reviewer = append(reviewer, c.CreatedAt.Sub(waitingSince))
+		}
+		waitingSince, waitingOnAuthor = c.CreatedAt, fromReviewer
+	}
+	return author, reviewer
+}
+
+// mergeStats adds up per-PR stats into totals.
+func mergeStats(all []PullRequestStats) PullRequestStats {
+	total := PullRequestStats{
+		authors: make(map[string]int),
+		types:   make(map[string]int),
+		files:   make(map[string]int),
+	}
+	for _, stats := range all {
+		total.PullRequests++
+		total.Comments += stats.Comments
+		total.HumanComments += stats.HumanComments
+		total.BotComments += stats.BotComments
+		total.ReviewRounds += stats.ReviewRounds
+		for _, tally := range []struct{ into, from map[string]int }{
+			{total.authors, stats.authors},
+			{total.types, stats.types},
+			{total.files, stats.files},
+		} {
+			for name, n := range tally.from {
+				tally.into[name] += n
+			}
+		}
+		total.firstReviews = append(total.firstReviews, stats.firstReviews...)
+		total.authorLatency = append(total.authorLatency, stats.authorLatency...)
+		total.reviewerLatency = append(total.reviewerLatency, stats.reviewerLatency...)
+	}
+	total.summarise()
+	return total
+}
+
+// summarise fills the exported fields from the raw tallies and samples.
+func (s *PullRequestStats) summarise() {
+	if s.Comments > 0 {
+		s.BotRatio = float64(s.BotComments) / float64(s.Comments)
+	}
+	s.ByAuthor = rankCounts(s.authors, 0)
+	s.ByType = rankCounts(s.types, 0)
+	s.TopFiles = rankCounts(s.files, statsTopFiles)
+	s.TimeToFirstReview = medianSeconds(s.firstReviews)
+	s.AuthorResponse = medianSeconds(s.authorLatency)
+	s.ReviewerResponse = medianSeconds(s.reviewerLatency)
+}
+
+// rankCounts orders counts highest first, then by name, keeping at most limit
+// entries when limit is positive.
+func rankCounts(counts map[string]int, limit int) []StatCount {
+	ranked := make([]StatCount, 0, len(counts))
+	for name, n := range counts {
+		ranked = append(ranked, StatCount{Name: name, Count: n})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+func medianSeconds(samples []time.Duration) *int64 {
+	if len(samples) == 0 {
+		return nil
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		mid = (sorted[len(sorted)/2-1] + mid) / 2
+	}
+	seconds := int64(mid / time.Second)
+	return &seconds
+}
+
+// RenderStatsTable writes the report as aligned terminal tables: one row per
+// pull request plus a total, then the author, type and file breakdowns.
+func RenderStatsTable(w io.Writer, report StatsReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PR\tCOMMENTS\tBOTS\tFIRST REVIEW\tROUNDS\tAUTHOR REPLY\tREVIEWER REPLY\tTOP FILE")
+	row := func(name string, s PullRequestStats) {
+		topFile := "-"
+		if len(s.TopFiles) > 0 {
+			topFile = fmt.Sprintf("%s (%d)", s.TopFiles[0].Name, s.TopFiles[0].Count)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.0f%%\t%s\t%d\t%s\t%s\t%s\n", name, s.Comments, s.BotRatio*100,
+			formatStatsDuration(s.TimeToFirstReview), s.ReviewRounds,
+			formatStatsDuration(s.AuthorResponse), formatStatsDuration(s.ReviewerResponse), topFile)
+	}
+	for _, s := range report.PullRequests {
+		row(fmt.Sprintf("%s#%d", s.PR.Repo, s.PR.Number), s)
+	}
+	if len(report.PullRequests) > 1 {
+		row(fmt.Sprintf("total (%d PRs)", report.Total.PullRequests), report.Total)
+	}
+
+	for _, section := range []struct {
+		heading string
+		counts  []StatCount
+	}{
+		{"AUTHOR", report.Total.ByAuthor},
+		{"TYPE", report.Total.ByType},
+		{"FILE", report.Total.TopFiles},
+	} {
+		if len(section.counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%s\tCOMMENTS\n", section.heading)
+		for _, count := range section.counts {
+			fmt.Fprintf(tw, "%s\t%d\n", count.Name, count.Count)
+		}
+	}
+	return tw.Flush()
+}
+
+// formatStatsDuration renders seconds in the two largest useful units.
+func formatStatsDuration(seconds *int64) string {
+	if seconds == nil {
+		return "-"
+	}
+	d := time.Duration(*seconds) * time.Second
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	default:
+		return fmt.Sprintf("%dd%02dh", int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour))
+	}
+}
@@ -0,0 +1,120 @@
+package ghprcomments
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func statsOutput(number int, base time.Time) Output {
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	line := 3
+	all := []Comment{
+		{Type: EventReadyForReview, ID: 1, Author: "owner", CreatedAt: at(60)},
+		{Type: "review_comment", ID: 2, Author: "alice", Path: "a.go", Line: &line, BodyText: "rename", CreatedAt: at(90)},
+		{Type: "review_comment", ID: 3, Author: "alice", Path: "a.go", Line: &line, BodyText: "and this", CreatedAt: at(91)},
+		{Type: "review_event", ID: 4, Author: "alice", State: "COMMENTED", CreatedAt: at(91)},
+		{Type: "review_event", ID: 5, Author: "bob", State: "CHANGES_REQUESTED", BodyText: "tests please", CreatedAt: at(100)},
+		{Type: "issue", ID: 6, Author: "ci[bot]", IsBot: true, BodyText: "coverage", CreatedAt: at(101)},
+		{Type: "issue", ID: 7, Author: "owner", BodyText: "done", CreatedAt: at(130)},
+		{Type: EventCommitted, ID: 8, Author: "owner", CreatedAt: at(131)},
+		{Type: "review_comment", ID: 9, Author: "alice", Path: "b.go", Line: &line, BodyText: "thanks", CreatedAt: at(190)},
+		{Type: "review_event", ID: 10, Author: "bob", State: "APPROVED", CreatedAt: at(200)},
+	}
+	return groupOutput(Output{PR: PullRequestMetadata{Repo: "o/r", Number: number, Author: "owner"}}, all, GroupByNone)
+}
+
+func TestBuildStats(t *testing.T) {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	stats := BuildStats(statsOutput(1, base), base)
+
+	if stats.Comments != 7 || stats.BotComments != 1 || stats.HumanComments != 6 {
+		t.Fatalf("counts = %d comments, %d bot, %d human", stats.Comments, stats.BotComments, stats.HumanComments)
+	}
+	if got := stats.ByAuthor[0]; got != (StatCount{Name: "alice", Count: 3}) {
+		t.Fatalf("top author = %+v", got)
+	}
+	if got := stats.TopFiles; len(got) != 2 || got[0] != (StatCount{Name: "a.go", Count: 2}) {
+		t.Fatalf("top files = %+v", got)
+	}
+	if stats.ReviewRounds != 1 {
+		t.Fatalf("review rounds = %d", stats.ReviewRounds)
+	}
+	// Ready for review at +60m, first review at +90m.
+	if stats.TimeToFirstReview == nil || *stats.TimeToFirstReview != 30*60 {
+		t.Fatalf("time to first review = %v", stats.TimeToFirstReview)
+	}
+	// The author answered alice's +90m comment at +130m; alice answered at +190m.
+	if stats.AuthorResponse == nil || *stats.AuthorResponse != 40*60 {
+		t.Fatalf("author response = %v", stats.AuthorResponse)
+	}
+	if stats.ReviewerResponse == nil || *stats.ReviewerResponse != 60*60 {
+		t.Fatalf("reviewer response = %v", stats.ReviewerResponse)
+	}
+}
+
+func TestReviewRounds(t *testing.T) {
+	at := func(minutes int) time.Time { return time.Date(2024, 5, 1, 9, minutes, 0, 0, time.UTC) }
+	requests := []time.Time{at(1), at(2), at(10), at(20)}
+	pushes := []time.Time{at(0), at(5), at(15)}
+	// The requests at 1 and 2 share the push at 5; the one at 20 is unanswered.
+	if got := reviewRounds(requests, pushes); got != 2 {
+		t.Fatalf("rounds = %d, want 2", got)
+	}
+}
+
+func TestStatsTotals(t *testing.T) {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	report := StatsReport{PullRequests: []PullRequestStats{
+		BuildStats(statsOutput(1, base), base),
+		BuildStats(statsOutput(2, base), base),
+	}}
+	report.Total = mergeStats(report.PullRequests)
+
+	total := report.Total
+	if total.PR != nil || total.PullRequests != 2 || total.Comments != 14 || total.ReviewRounds != 2 || total.ByAuthor[0].Count != 6 {
+		t.Fatalf("unexpected total %+v", total)
+	}
+	if *total.TimeToFirstReview != 30*60 {
+		t.Fatalf("median first review = %d", *total.TimeToFirstReview)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"bot_ratio":0.14`, `"time_to_first_review_seconds":1800`, `"top_files":[{"name":"a.go","count":4}`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("JSON missing %s:\n%s", want, data)
+		}
+	}
+
+	var table strings.Builder
+	if err := RenderStatsTable(&table, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"o/r#1", "total (2 PRs)", "30m", "40m", "1h00m", "a.go (4)", "alice"} {
+		if !strings.Contains(table.String(), want) {
+			t.Fatalf("table missing %q:\n%s", want, table.String())
+		}
+	}
+}
+
+func TestFormatStatsDuration(t *testing.T) {
+	seconds := func(n int64) *int64 { return &n }
+	for _, tc := range []struct {
+		in   *int64
+		want string
+	}{
+		{nil, "-"},
+		{seconds(30), "<1m"},
+		{seconds(45 * 60), "45m"},
+		{seconds(3*3600 + 5*60), "3h05m"},
+		{seconds(50 * 3600), "2d02h"},
+	} {
+		if got := formatStatsDuration(tc.in); got != tc.want {
+			t.Errorf("formatStatsDuration(%v) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package ghprcomments
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Suggestion is a GitHub ```suggestion block parsed into an appliable replacement.
+// StartLine and Line are 1-based and inclusive, matching the review comment
+// anchor, and refer to the file as of CommitID.
+type Suggestion struct {
+	CommentID   int64  `json:"-"`
+	Author      string `json:"-"`
+	CommitID    string `json:"-"`
+	Path        string `json:"path"`
+	StartLine   int    `json:"start_line"`
+	Line        int    `json:"line"`
+	Replacement string `json:"replacement"`
+}
+
+var suggestionFenceRegex = regexp.MustCompile("(?ms)^[ \\t]*(`{3,}|~{3,})[ \\t]*suggestion[ \\t]*\\n(.*?)^[ \\t]*(`{3,}|~{3,})[ \\t]*$")
+
+// parseSuggestions extracts every suggestion block from a raw comment body.
+func parseSuggestions(body, path string, startLine, line int) []Suggestion {
+	if path == "" || line <= 0 || !strings.Contains(body, "suggestion") {
+		return nil
+	}
+	if startLine <= 0 || startLine > line {
+		startLine = line
+	}
+
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	var suggestions []Suggestion
+	for _, match := range suggestionFenceRegex.FindAllStringSubmatch(body, -1) {
+		open, closing := match[1], match[3]
+		if open[0] != closing[0] || len(closing) < len(open) {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{
+			Path:        path,
+			StartLine:   startLine,
+			Line:        line,
+			Replacement: match[2],
+		})
+	}
+	return suggestions
+}
+
+// CollectSuggestions returns every suggestion in the output, oldest comment first.
+func CollectSuggestions(out Output) []Suggestion {
+	comments := outputComments(out)
+
+	var suggestions []Suggestion
+	for i := len(comments) - 1; i >= 0; i-- {
+		suggestions = append(suggestions, comments[i].Suggestions...)
+	}
+	return suggestions
+}
+
+// SuggestionSkip records a suggestion that could not be applied.
+type SuggestionSkip struct {
+	Suggestion Suggestion
+	Reason     string
+}
+
+// FilePatch is the planned result of applying suggestions to a single file.
+type FilePatch struct {
+	Path    string
+	Applied []Suggestion
+	edits   []lineEdit
+	before  []string
+	after   []string
+	mode    os.FileMode
+	trailer bool
+}
+
+type lineEdit struct {
+	start int // 0-based index of the first replaced line
+	end   int // exclusive
+	lines []string
+}
+
+// SuggestionPlan describes the changes suggestions would make to a checkout.
+type SuggestionPlan struct {
+	Root    string
+	Files   []FilePatch
+	Skipped []SuggestionSkip
+}
+
+// PlanSuggestions resolves suggestions against the files under root without
+// modifying anything. Overlapping or out-of-range suggestions are skipped, as
+// are suggestions whose line numbers refer to a commit other than head, the
+// pull request head the checkout is expected to be at; an empty head skips
+// that check.
+func PlanSuggestions(root, head string, suggestions []Suggestion) (SuggestionPlan, error) {
+	plan := SuggestionPlan{Root: root}
+	if strings.TrimSpace(root) == "" {
+		return plan, errors.New("apply suggestions requires a local checkout")
+	}
+
+	byPath := make(map[string][]Suggestion)
+	var paths []string
+	for _, s := range suggestions {
+		if head != "" && s.CommitID != "" && s.CommitID != head {
+			plan.Skipped = append(plan.Skipped, SuggestionSkip{Suggestion: s, Reason: fmt.Sprintf("written against %s, not the head %s", ShortSHA(s.CommitID), ShortSHA(head))})
+			continue
+		}
+		if _, ok := byPath[s.Path]; !ok {
+			paths = append(paths, s.Path)
+		}
+		byPath[s.Path] = append(byPath[s.Path], s)
+	}
+	sort.Strings(paths)
+
+	for _, rel := range paths {
+		target, err := checkoutPath(root, rel)
+		if err != nil {
+			for _, s := range byPath[rel] {
+				plan.Skipped = append(plan.Skipped, SuggestionSkip{Suggestion: s, Reason: err.Error()})
+			}
+			continue
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			for _, s := range byPath[rel] {
+				plan.Skipped = append(plan.Skipped, SuggestionSkip{Suggestion: s, Reason: "file not found in checkout"})
+			}
+			continue
+		}
+		data, err := os.ReadFile(target)
+		if err != nil {
+			return plan, fmt.Errorf("read %s: %w", rel, err)
+		}
+
+		patch := FilePatch{Path: rel, mode: info.Mode().Perm()}
+		text := strings.ReplaceAll(string(data), "\r\n", "\n")
+		patch.trailer = strings.HasSuffix(text, "\n")
+		text = strings.TrimSuffix(text, "\n")
+		if text != "" {
+			patch.before = strings.Split(text, "\n")
+		}
+
+		// Earlier suggestions win when ranges overlap; edits are applied in line order.
+		for _, s := range byPath[rel] {
+			if s.StartLine < 1 || s.Line > len(patch.before) {
+				plan.Skipped = append(plan.Skipped, SuggestionSkip{Suggestion: s, Reason: fmt.Sprintf("lines %d-%d are outside the file (%d lines)", s.StartLine, s.Line, len(patch.before))})
+				continue
+			}
+			overlaps := false
+			for _, e := range patch.edits {
+				if s.StartLine-1 < e.end && e.start < s.Line {
+					overlaps = true
+					break
+				}
+			}
+			if overlaps {
+				plan.Skipped = append(plan.Skipped, SuggestionSkip{Suggestion: s, Reason: "overlaps an earlier suggestion"})
+				continue
+			}
+			var replacement []string
+			if s.Replacement != "" {
+				replacement = strings.Split(strings.TrimSuffix(s.Replacement, "\n"), "\n")
+			}
+			patch.edits = append(patch.edits, lineEdit{start: s.StartLine - 1, end: s.Line, lines: replacement})
+			patch.Applied = append(patch.Applied, s)
+		}
+		sort.SliceStable(patch.edits, func(i, j int) bool {
+			return patch.edits[i].start < patch.edits[j].start
+		})
+		if len(patch.edits) == 0 {
+			continue
+		}
+
+		cursor := 0
+		for _, e := range patch.edits {
+			patch.after = append(patch.after, patch.before[cursor:e.start]...)
+			patch.after = append(patch.after, e.lines...)
+			cursor = e.end
+		}
+		patch.after = append(patch.after, patch.before[cursor:]...)
+		plan.Files = append(plan.Files, patch)
+	}
+
+	return plan, nil
+}
+
+// Apply writes every planned file patch to disk.
+func (p SuggestionPlan) Apply() error {
+	for _, patch := range p.Files {
+		target, err := checkoutPath(p.Root, patch.Path)
+		if err != nil {
+			return err
+		}
+		content := strings.Join(patch.after, "\n")
+		if patch.trailer && len(patch.after) > 0 {
+			content += "\n"
+		}
+		if err := os.WriteFile(target, []byte(content), patch.mode); err != nil {
+			return fmt.Errorf("write %s: %w", patch.Path, err)
+		}
+	}
+	return nil
+}
+
+// UnifiedDiff renders the plan as a patch that `git apply` accepts.
+func (p SuggestionPlan) UnifiedDiff() string {
+	var b strings.Builder
+	for _, patch := range p.Files {
+		b.WriteString(patch.UnifiedDiff())
+	}
+	return b.String()
+}
+
+const diffContextLines = 3
+
+// UnifiedDiff renders the file patch as a unified diff with git-style headers.
+func (f FilePatch) UnifiedDiff() string {
+	if len(f.edits) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	path := filepath.ToSlash(f.Path)
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+
+	delta := 0
+	for i := 0; i < len(f.edits); {
+		j := i
+		for j+1 < len(f.edits) && f.edits[j+1].start-f.edits[j].end <= 2*diffContextLines {
+			j++
+		}
+		group := f.edits[i : j+1]
+
+		oldStart := max(0, group[0].start-diffContextLines)
+		oldEnd := min(len(f.before), group[len(group)-1].end+diffContextLines)
+		newStart := oldStart + delta
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		cursor := oldStart
+		for _, e := range group {
+			for ; cursor < e.start; cursor++ {
+				body.WriteString(" " + f.before[cursor] + "\n")
+				oldCount++
+				newCount++
+			}
+			for ; cursor < e.end; cursor++ {
+				body.WriteString("-" + f.before[cursor] + "\n")
+				oldCount++
+			}
+			for _, line := range e.lines {
+				body.WriteString("+" + line + "\n")
+				newCount++
+			}
+			delta += len(e.lines) - (e.end - e.start)
+		}
+		for ; cursor < oldEnd; cursor++ {
+			body.WriteString(" " + f.before[cursor] + "\n")
+			oldCount++
+			newCount++
+		}
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		b.WriteString(body.String())
+		i = j + 1
+	}
+	return b.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// checkoutPath joins a repository-relative path onto root, refusing paths that escape it.
+func checkoutPath(root, rel string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("path %q escapes the repository", rel)
+	}
+	return filepath.Join(root, cleaned), nil
+}
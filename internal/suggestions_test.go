@@ -0,0 +1,162 @@
+package ghprcomments
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestParseSuggestions(t *testing.T) {
+	body := "Tighten this up:\r\n\r\n```suggestion\r\nreturn nil\r\n```\r\n\r\n```go\r\nnot a suggestion\r\n```"
+
+	got := parseSuggestions(body, "main.go", 4, 6)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d", len(got))
+	}
+	if got[0].Path != "main.go" || got[0].StartLine != 4 || got[0].Line != 6 {
+		t.Fatalf("unexpected anchor: %+v", got[0])
+	}
+	if got[0].Replacement != "return nil\n" {
+		t.Fatalf("unexpected replacement %q", got[0].Replacement)
+	}
+
+	single := parseSuggestions("```suggestion\n```", "main.go", 0, 9)
+	if len(single) != 1 || single[0].StartLine != 9 || single[0].Replacement != "" {
+		t.Fatalf("expected empty single-line suggestion at line 9, got %+v", single)
+	}
+
+	if got := parseSuggestions(body, "main.go", 0, 0); got != nil {
+		t.Fatalf("expected no suggestions without a line anchor, got %+v", got)
+	}
+}
+
+func TestBuildOutputExtractsSuggestions(t *testing.T) {
+	payload := commentPayload{
+		reviewComments: []*github.PullRequestComment{
+			{
+				ID:        github.Int64(7),
+				Body:      github.String("```suggestion\nfixed()\n```"),
+				Path:      github.String("a.go"),
+				StartLine: github.Int(2),
+				Line:      github.Int(3),
+				User:      &github.User{Login: github.String("alice")},
+			},
+		},
+	}
+
+	out := BuildOutput(&PullRequestSummary{Number: 1}, payload, NormalizationOptions{})
+	suggestions := CollectSuggestions(out)
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d", len(suggestions))
+	}
+	if s := suggestions[0]; s.CommentID != 7 || s.Author != "alice" || s.StartLine != 2 || s.Line != 3 {
+		t.Fatalf("unexpected suggestion %+v", s)
+	}
+}
+
+func writeFixture(t *testing.T, root, rel, content string) string {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+func TestPlanSuggestionsDryRunDiff(t *testing.T) {
+	root := t.TempDir()
+	path := writeFixture(t, root, "pkg/a.go", "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n")
+
+	plan, err := PlanSuggestions(root, "", []Suggestion{
+		{Path: "pkg/a.go", StartLine: 2, Line: 3, Replacement: "TWO-THREE\n"},
+		{Path: "pkg/a.go", StartLine: 9, Line: 9, Replacement: ""},
+	})
+	if err != nil {
+		t.Fatalf("PlanSuggestions failed: %v", err)
+	}
+	if len(plan.Skipped) != 0 {
+		t.Fatalf("expected nothing skipped, got %+v", plan.Skipped)
+	}
+
+	want := strings.Join([]string{
+		"diff --git a/pkg/a.go b/pkg/a.go",
+		"--- a/pkg/a.go",
+		"+++ b/pkg/a.go",
+		"@@ -1,10 +1,8 @@",
+		" one",
+		"-two",
+		"-three",
+		"+TWO-THREE",
+		" four",
+		" five",
+		" six",
+		" seven",
+		" eight",
+		"-nine",
+		" ten",
+		"",
+	}, "\n")
+	if got := plan.UnifiedDiff(); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "one\ntwo\n") {
+		t.Fatalf("planning must not modify files, got %q", data)
+	}
+}
+
+func TestSuggestionPlanApplyPatchesFiles(t *testing.T) {
+	root := t.TempDir()
+	path := writeFixture(t, root, "a.go", "package a\n\nfunc A() int {\n\treturn 1\n}\n")
+
+	plan, err := PlanSuggestions(root, "", []Suggestion{
+		{Path: "a.go", StartLine: 4, Line: 4, Replacement: "\treturn 2\n"},
+		{Path: "a.go", StartLine: 3, Line: 4, Replacement: "overlap\n"},
+		{Path: "a.go", StartLine: 40, Line: 41, Replacement: "x\n"},
+		{Path: "missing.go", StartLine: 1, Line: 1, Replacement: "x\n"},
+		{Path: "../escape.go", StartLine: 1, Line: 1, Replacement: "x\n"},
+	})
+	if err != nil {
+		t.Fatalf("PlanSuggestions failed: %v", err)
+	}
+	if len(plan.Skipped) != 4 {
+		t.Fatalf("expected overlap, out-of-range, missing and escaping suggestions to be skipped, got %+v", plan.Skipped)
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read patched file: %v", err)
+	}
+	if string(data) != "package a\n\nfunc A() int {\n\treturn 2\n}\n" {
+		t.Fatalf("unexpected patched content %q", data)
+	}
+}
+
+func TestPlanSuggestionsSkipsOtherCommits(t *testing.T) {
+	root := t.TempDir()
+	writeFixture(t, root, "a.go", "one\ntwo\n")
+
+	plan, err := PlanSuggestions(root, "headsha1234", []Suggestion{
+		{Path: "a.go", StartLine: 1, Line: 1, Replacement: "ONE\n", CommitID: "headsha1234"},
+		{Path: "a.go", StartLine: 2, Line: 2, Replacement: "TWO\n", CommitID: "oldsha56789"},
+	})
+	if err != nil {
+		t.Fatalf("PlanSuggestions failed: %v", err)
+	}
+	if len(plan.Files) != 1 || len(plan.Files[0].Applied) != 1 || plan.Files[0].Applied[0].Line != 1 {
+		t.Fatalf("expected only the suggestion on the head to be planned, got %+v", plan.Files)
+	}
+	if len(plan.Skipped) != 1 || !strings.Contains(plan.Skipped[0].Reason, "oldsha5") {
+		t.Fatalf("expected the stale suggestion to be skipped, got %+v", plan.Skipped)
+	}
+}
@@ -0,0 +1,320 @@
+package ghprcomments
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sync statuses recorded on comments in a saved snapshot.
+const (
+	SyncStatusNew     = "new"
+	SyncStatusEdited  = "edited"
+	SyncStatusDeleted = "deleted"
+)
+
+var permalinkIDRegex = regexp.MustCompile(`(?:issuecomment-|discussion_r|pullrequestreview-)(\d+)$`)
+
+// SyncSummary describes how a save differs from the previous snapshot.
+type SyncSummary struct {
+	Previous        bool
+	PreviousSavedAt time.Time
+	New             int
+	Edited          int
+	Deleted         int
+}
+
+// String renders a short "3 new, 1 edited since last save" description.
+func (s SyncSummary) String() string {
+	if !s.Previous {
+		return fmt.Sprintf("%d new (no previous save)", s.New)
+	}
+	summary := fmt.Sprintf("%d new, %d edited", s.New, s.Edited)
+	if s.Deleted > 0 {
+		summary += fmt.Sprintf(", %d deleted", s.Deleted)
+	}
+	return summary + " since last save"
+}
+
+// SyncSavedOutput writes the comments in payload for pr, marked against the
+// previous save. Comments are matched by type and ID across the full,
+// unfiltered set: those that appeared since the last save are marked new,
+// those whose body or state changed upstream are marked edited, and comments
+// that disappeared upstream are kept and marked deleted. The display filters
+// in opts are applied afterwards, so hiding bots or resolved threads in one
+// save does not read as a deletion in the next.
+func SyncSavedOutput(repoRoot string, pr *PullRequestSummary, payload commentPayload, opts NormalizationOptions, flat bool, saveDir string) (string, SyncSummary, error) {
+	if pr == nil || pr.Number <= 0 {
+		return "", SyncSummary{}, errors.New("save requires a pull request with a number")
+	}
+
+	target, err := prepareSaveTarget(repoRoot, pr, saveDir)
+	if err != nil {
+		return "", SyncSummary{}, err
+	}
+
+	dir := filepath.Dir(target)
+	previousPath := findSavedSnapshot(dir, pr.Number)
+	previous, err := loadSyncState(syncStatePath(dir, pr.Number), previousPath)
+	if err != nil {
+		return "", SyncSummary{}, err
+	}
+
+	merged, state, summary := mergeSnapshot(normalizeComments(pr, payload, opts), payloadFingerprints(payload), previous)
+	out := shapeOutput(pr, filterComments(merged, opts), opts)
+
+	payloadJSON, err := MarshalJSON(out, flat)
+	if err != nil {
+		return "", SyncSummary{}, err
+	}
+	if err := os.WriteFile(target, buildFeedbackMarkdown(pr, payloadJSON, &summary), 0o644); err != nil {
+		return "", SyncSummary{}, err
+	}
+	if err := writeSyncState(syncStatePath(dir, pr.Number), state); err != nil {
+		return "", SyncSummary{}, err
+	}
+	// A retitled PR changes the slug; drop the snapshot saved under the old name.
+	if previousPath != "" && previousPath != target {
+		if err := os.Remove(previousPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", SyncSummary{}, err
+		}
+	}
+	return target, summary, nil
+}
+
+type savedSnapshot struct {
+	SavedAt  time.Time
+	Comments []Comment
+}
+
+// syncState records every comment seen at a save, before display filters,
+// with a fingerprint of its upstream body. It lives beside the Markdown
+// snapshot, which only holds what was shown.
+type syncState struct {
+	SavedAt  time.Time   `json:"saved_at"`
+	Comments []syncEntry `json:"comments"`
+}
+
+type syncEntry struct {
+	Fingerprint string  `json:"fingerprint,omitempty"`
+	Comment     Comment `json:"comment"`
+}
+
+func syncStatePath(dir string, number int) string {
+	return filepath.Join(dir, "snapshots", fmt.Sprintf("pr-%d.json", number))
+}
+
+// loadSyncState reads the state recorded by the last save. Saves made before
+// the state file existed fall back to the comments in the Markdown snapshot,
+// without fingerprints, so edits are only detected from the next save on.
+func loadSyncState(path, markdownPath string) (*syncState, error) {
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var state syncState
+		// An unreadable state file is replaced rather than blocking the save.
+		if json.Unmarshal(data, &state) == nil {
+			return &state, nil
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("read sync state: %w", err)
+	}
+
+	if markdownPath == "" {
+		return nil, nil
+	}
+	data, err = os.ReadFile(markdownPath)
+	if err != nil {
+		return nil, fmt.Errorf("read previous snapshot: %w", err)
+	}
+	snapshot, err := parseSavedSnapshot(data)
+	if err != nil {
+		return nil, nil
+	}
+	state := &syncState{SavedAt: snapshot.SavedAt}
+	for _, c := range snapshot.Comments {
+		state.Comments = append(state.Comments, syncEntry{Comment: c})
+	}
+	return state, nil
+}
+
+func writeSyncState(path string, state syncState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create sync state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode sync state: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write sync state: %w", err)
+	}
+	return nil
+}
+
+// mergeSnapshot marks all against the previous save and appends tombstones
+// for comments that have gone. A tombstone is kept for one save, so the
+// snapshot does not collect every comment ever deleted. Timeline events are
+// passed through unmarked; they are only fetched on request and never change.
+func mergeSnapshot(all []Comment, fingerprints map[string]string, previous *syncState) ([]Comment, syncState, SyncSummary) {
+	var summary SyncSummary
+	if previous == nil {
+		previous = &syncState{}
+	} else {
+		summary.Previous = true
+		summary.PreviousSavedAt = previous.SavedAt
+	}
+	state := syncState{SavedAt: time.Now().UTC()}
+
+	known := make(map[string]syncEntry, len(previous.Comments))
+	for _, entry := range previous.Comments {
+		known[snapshotKey(entry.Comment)] = entry
+	}
+
+	merged := make([]Comment, 0, len(all))
+	current := make(map[string]bool, len(all))
+	for _, c := range all {
+		if IsTimelineEvent(c.Type) {
+			merged = append(merged, c)
+			continue
+		}
+		key := snapshotKey(c)
+		current[key] = true
+		fingerprint := fingerprints[key]
+		before, ok := known[key]
+		switch {
+		case !ok || before.Comment.SyncStatus == SyncStatusDeleted:
+			c.SyncStatus = SyncStatusNew
+			summary.New++
+		case before.Fingerprint != "" && before.Fingerprint != fingerprint:
+			c.SyncStatus = SyncStatusEdited
+			summary.Edited++
+		default:
+			c.SyncStatus = ""
+		}
+		merged = append(merged, c)
+		state.Comments = append(state.Comments, syncEntry{Fingerprint: fingerprint, Comment: c})
+	}
+
+	for _, entry := range previous.Comments {
+		c := entry.Comment
+		if current[snapshotKey(c)] || c.SyncStatus == SyncStatusDeleted {
+			continue
+		}
+		summary.Deleted++
+		c.SyncStatus = SyncStatusDeleted
+		if c.ID == 0 {
+			c.ID = permalinkID(c.Permalink)
+		}
+		merged = append(merged, c)
+		state.Comments = append(state.Comments, syncEntry{Comment: c})
+	}
+	return merged, state, summary
+}
+
+// payloadFingerprints summarises each comment as GitHub returned it, keyed
+// like snapshotKey. Raw bodies keep --strip-level and --strip-html changes
+// between saves from being reported as upstream edits.
+func payloadFingerprints(payload commentPayload) map[string]string {
+	fingerprints := make(map[string]string, len(payload.issueComments)+len(payload.reviewComments)+len(payload.reviews))
+	for _, c := range payload.issueComments {
+		fingerprints["issue:"+strconv.FormatInt(c.GetID(), 10)] = c.GetBody() + "\x00" + c.GetUpdatedAt().UTC().Format(time.RFC3339Nano)
+	}
+	for _, c := range payload.reviewComments {
+		fingerprints["review_comment:"+strconv.FormatInt(c.GetID(), 10)] = c.GetBody() + "\x00" + c.GetUpdatedAt().UTC().Format(time.RFC3339Nano)
+	}
+	// Reviews carry no updated_at; their body and state are all that can change.
+	for _, r := range payload.reviews {
+		fingerprints["review_event:"+strconv.FormatInt(r.GetID(), 10)] = r.GetBody() + "\x00" + r.GetState()
+	}
+	return fingerprints
+}
+
+// snapshotKey identifies a comment across saves. Saved comments do not carry
+// their ID, so it is recovered from the permalink anchor.
+func snapshotKey(c Comment) string {
+	id := c.ID
+	if id == 0 {
+		id = permalinkID(c.Permalink)
+	}
+	if id != 0 {
+		return c.Type + ":" + strconv.FormatInt(id, 10)
+	}
+	return c.Type + "|" + c.Author + "|" + c.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+func permalinkID(permalink string) int64 {
+	match := permalinkIDRegex.FindStringSubmatch(permalink)
+	if match == nil {
+		return 0
+	}
+	id, _ := strconv.ParseInt(match[1], 10, 64)
+	return id
+}
+
+func findSavedSnapshot(dir string, number int) string {
+	matches, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("pr-%d-*.md", number)))
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	for _, match := range matches {
+		if n, ok := extractPullRequestNumber(filepath.Base(match)); ok && n == number {
+			return match
+		}
+	}
+	return ""
+}
+
+// parseSavedSnapshot reads the front matter and JSON block written by
+// buildFeedbackMarkdown, accepting nested, threaded and flat payloads.
+func parseSavedSnapshot(data []byte) (savedSnapshot, error) {
+	var snapshot savedSnapshot
+	content := string(data)
+
+	if rest, ok := strings.CutPrefix(content, "---\n"); ok {
+		frontMatter, _, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			return snapshot, errors.New("unterminated front matter")
+		}
+		for _, line := range strings.Split(frontMatter, "\n") {
+			key, value, ok := strings.Cut(line, ": ")
+			if !ok || key != "saved_at" {
+				continue
+			}
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			if ts, err := time.Parse(time.RFC3339, value); err == nil {
+				snapshot.SavedAt = ts
+			}
+		}
+	}
+
+	start := strings.Index(content, "```json\n")
+	end := strings.LastIndex(content, "```")
+	if start < 0 || end <= start {
+		return snapshot, errors.New("missing JSON block")
+	}
+	payload := bytes.TrimSpace([]byte(content[start+len("```json\n") : end]))
+
+	if bytes.HasPrefix(payload, []byte("[")) {
+		if err := json.Unmarshal(payload, &snapshot.Comments); err != nil {
+			return snapshot, err
+		}
+		return snapshot, nil
+	}
+
+	var out Output
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return snapshot, err
+	}
+	snapshot.Comments = outputComments(out)
+	return snapshot, nil
+}
@@ -0,0 +1,372 @@
+package ghprcomments
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+var syncTestBase = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func syncIssueComment(id int64, author, body string, updated time.Duration) *github.IssueComment {
+	created := github.Timestamp{Time: syncTestBase.Add(time.Duration(id) * time.Minute)}
+	updatedAt := github.Timestamp{Time: created.Add(updated)}
+	return &github.IssueComment{
+		ID:        github.Int64(id),
+		Body:      github.String(body),
+		User:      &github.User{Login: github.String(author)},
+		CreatedAt: &created,
+		UpdatedAt: &updatedAt,
+		HTMLURL:   github.String("https://github.com/o/r/pull/9#issuecomment-" + strconv.FormatInt(id, 10)),
+	}
+}
+
+func syncReviewComment(id int64, author, body string, updated time.Duration) *github.PullRequestComment {
+	created := github.Timestamp{Time: syncTestBase.Add(time.Duration(id) * time.Minute)}
+	updatedAt := github.Timestamp{Time: created.Add(updated)}
+	return &github.PullRequestComment{
+		ID:        github.Int64(id),
+		Body:      github.String(body),
+		Path:      github.String("main.go"),
+		User:      &github.User{Login: github.String(author)},
+		CreatedAt: &created,
+		UpdatedAt: &updatedAt,
+		HTMLURL:   github.String("https://github.com/o/r/pull/9#discussion_r" + strconv.FormatInt(id, 10)),
+	}
+}
+
+func syncReview(id int64, author, body string) *github.PullRequestReview {
+	submitted := github.Timestamp{Time: syncTestBase.Add(time.Duration(id) * time.Minute)}
+	return &github.PullRequestReview{
+		ID:          github.Int64(id),
+		Body:        github.String(body),
+		State:       github.String("COMMENTED"),
+		User:        &github.User{Login: github.String(author)},
+		SubmittedAt: &submitted,
+		HTMLURL:     github.String("https://github.com/o/r/pull/9#pullrequestreview-" + strconv.FormatInt(id, 10)),
+	}
+}
+
+func savedStatuses(t *testing.T, path string) map[string]string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	snapshot, err := parseSavedSnapshot(data)
+	if err != nil {
+		t.Fatalf("parse snapshot: %v", err)
+	}
+	status := make(map[string]string)
+	for _, c := range snapshot.Comments {
+		status[snapshotKey(c)] = c.SyncStatus
+	}
+	return status
+}
+
+func TestSyncSavedOutputMarksChanges(t *testing.T) {
+	repoRoot := t.TempDir()
+	pr := &PullRequestSummary{Number: 9, Title: "Sync", HeadRef: "feature"}
+
+	first := commentPayload{
+		issueComments:  []*github.IssueComment{syncIssueComment(1, "alice", "one", 0)},
+		reviewComments: []*github.PullRequestComment{syncReviewComment(2, "bob", "two", 0)},
+		reviews:        []*github.PullRequestReview{syncReview(3, "bob", "three")},
+	}
+
+	path, summary, err := SyncSavedOutput(repoRoot, pr, first, NormalizationOptions{}, false, "")
+	if err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	if summary.Previous || summary.New != 3 {
+		t.Fatalf("unexpected first summary: %+v", summary)
+	}
+
+	second := commentPayload{
+		issueComments: []*github.IssueComment{
+			syncIssueComment(1, "alice", "one", 0),
+			syncIssueComment(4, "carol", "four", 0),
+		},
+		reviewComments: []*github.PullRequestComment{syncReviewComment(2, "bob", "two (edited)", time.Hour)},
+	}
+
+	path2, summary, err := SyncSavedOutput(repoRoot, pr, second, NormalizationOptions{}, false, "")
+	if err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	if path2 != path {
+		t.Fatalf("expected same snapshot path, got %q and %q", path, path2)
+	}
+	if !summary.Previous || summary.New != 1 || summary.Edited != 1 || summary.Deleted != 1 {
+		t.Fatalf("unexpected second summary: %+v", summary)
+	}
+	if got := summary.String(); got != "1 new, 1 edited, 1 deleted since last save" {
+		t.Fatalf("unexpected summary string %q", got)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"previous_saved_at: ", "new_since_last_save: 1", "edited_since_last_save: 1", "deleted_since_last_save: 1"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("front matter missing %q:\n%s", want, content)
+		}
+	}
+
+	status := savedStatuses(t, path)
+	want := map[string]string{
+		"issue:1":          "",
+		"review_comment:2": SyncStatusEdited,
+		"review_event:3":   SyncStatusDeleted,
+		"issue:4":          SyncStatusNew,
+	}
+	for key, wantStatus := range want {
+		got, ok := status[key]
+		if !ok {
+			t.Errorf("snapshot missing %s", key)
+			continue
+		}
+		if got != wantStatus {
+			t.Errorf("%s: sync_status = %q, want %q", key, got, wantStatus)
+		}
+	}
+
+	// A third save with no upstream changes clears the markers and drops the
+	// tombstone, which has been shown once.
+	_, summary, err = SyncSavedOutput(repoRoot, pr, second, NormalizationOptions{}, false, "")
+	if err != nil {
+		t.Fatalf("third sync failed: %v", err)
+	}
+	if summary.New != 0 || summary.Edited != 0 || summary.Deleted != 0 {
+		t.Fatalf("expected no changes, got %+v", summary)
+	}
+	if _, ok := savedStatuses(t, path)["review_event:3"]; ok {
+		t.Fatal("expected the tombstone to be dropped after one save")
+	}
+}
+
+func TestSyncSavedOutputIgnoresDisplayFilters(t *testing.T) {
+	repoRoot := t.TempDir()
+	pr := &PullRequestSummary{Number: 9, Title: "Sync"}
+	payload := commentPayload{
+		issueComments: []*github.IssueComment{
+			syncIssueComment(1, "alice", "<b>one</b>", 0),
+			syncIssueComment(2, "dependabot[bot]", "bump", 0),
+		},
+	}
+
+	if _, _, err := SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{}, false, ""); err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+
+	// Hiding bots and stripping HTML changes what is shown, not what exists upstream.
+	path, summary, err := SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{ExcludeBots: true, StripHTML: true}, false, "")
+	if err != nil {
+		t.Fatalf("filtered sync failed: %v", err)
+	}
+	if summary.New != 0 || summary.Edited != 0 || summary.Deleted != 0 {
+		t.Fatalf("expected no changes, got %+v", summary)
+	}
+	status := savedStatuses(t, path)
+	if _, ok := status["issue:2"]; ok {
+		t.Fatalf("expected the bot comment to stay hidden, got %v", status)
+	}
+	if got, ok := status["issue:1"]; !ok || got != "" {
+		t.Fatalf("expected issue:1 unmarked, got %v", status)
+	}
+
+	// Showing bots again does not report the bot comment as new.
+	_, summary, err = SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{}, false, "")
+	if err != nil {
+		t.Fatalf("unfiltered sync failed: %v", err)
+	}
+	if summary.New != 0 || summary.Deleted != 0 {
+		t.Fatalf("expected no changes, got %+v", summary)
+	}
+}
+
+func TestSyncSavedOutputReadsLegacySnapshot(t *testing.T) {
+	repoRoot := t.TempDir()
+	pr := &PullRequestSummary{Number: 9, Title: "Sync"}
+	payload := commentPayload{issueComments: []*github.IssueComment{syncIssueComment(1, "alice", "one", 0)}}
+
+	path, _, err := SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{}, false, "")
+	if err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(filepath.Dir(path), "snapshots")); err != nil {
+		t.Fatalf("remove sync state: %v", err)
+	}
+
+	_, summary, err := SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{}, false, "")
+	if err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	if !summary.Previous || summary.New != 0 || summary.Edited != 0 || summary.Deleted != 0 {
+		t.Fatalf("expected the Markdown snapshot to be read back, got %+v", summary)
+	}
+}
+
+func TestSyncSavedOutputReplacesRetitledSnapshot(t *testing.T) {
+	repoRoot := t.TempDir()
+	payload := commentPayload{issueComments: []*github.IssueComment{syncIssueComment(1, "alice", "hi", 0)}}
+
+	oldPath, _, err := SyncSavedOutput(repoRoot, &PullRequestSummary{Number: 3, Title: "Old title"}, payload, NormalizationOptions{}, true, "")
+	if err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	newPath, summary, err := SyncSavedOutput(repoRoot, &PullRequestSummary{Number: 3, Title: "New title"}, payload, NormalizationOptions{}, true, "")
+	if err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+
+	if filepath.Base(newPath) != "pr-3-new-title.md" {
+		t.Fatalf("unexpected path %q", newPath)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("expected old snapshot %s to be removed", oldPath)
+	}
+	if !summary.Previous || summary.New != 0 {
+		t.Fatalf("expected the previous save to be read back, got %+v", summary)
+	}
+}
+
+func TestSyncSavedOutputCreatesDirectoryAndFile(t *testing.T) {
+	repoRoot := t.TempDir()
+	pr := &PullRequestSummary{
+		Number:    123,
+		Title:     "Add Feature 🚀",
+		HeadRef:   "feature/add-feature",
+		BaseRef:   "main",
+		RepoOwner: "octo",
+		RepoName:  "repo",
+		Author:    "tester",
+		URL:       "https://example.com",
+	}
+	payload := commentPayload{issueComments: []*github.IssueComment{syncIssueComment(1, "alice", "ok", 0)}}
+
+	path, _, err := SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{}, false, "")
+	if err != nil {
+		t.Fatalf("SyncSavedOutput returned error: %v", err)
+	}
+
+	dir := filepath.Join(repoRoot, ".pr-comments")
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("expected directory %s to exist", dir)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read saved payload: %v", err)
+	}
+	content := string(data)
+
+	if !strings.HasPrefix(content, "---\n") {
+		t.Fatalf("expected YAML front matter, got %q", content)
+	}
+	if !strings.Contains(content, "pr_number: 123") {
+		t.Fatalf("front matter missing pr_number: %q", content)
+	}
+	if !strings.Contains(content, `pr_title: "Add Feature 🚀"`) {
+		t.Fatalf("front matter missing pr_title: %q", content)
+	}
+	if !strings.Contains(content, `repo_owner: "octo"`) {
+		t.Fatalf("front matter missing repo_owner: %q", content)
+	}
+	if !strings.Contains(content, `repo_name: "repo"`) {
+		t.Fatalf("front matter missing repo_name: %q", content)
+	}
+	if !strings.Contains(content, `head_ref: "feature/add-feature"`) {
+		t.Fatalf("front matter missing head_ref: %q", content)
+	}
+	if !strings.Contains(content, `base_ref: "main"`) {
+		t.Fatalf("front matter missing base_ref: %q", content)
+	}
+	if !strings.Contains(content, `author: "tester"`) {
+		t.Fatalf("front matter missing author: %q", content)
+	}
+	if !strings.Contains(content, `url: "https://example.com"`) {
+		t.Fatalf("front matter missing url: %q", content)
+	}
+
+	savedAtRe := regexp.MustCompile(`saved_at: "[^"]+"`)
+	if !savedAtRe.MatchString(content) {
+		t.Fatalf("front matter missing saved_at timestamp: %q", content)
+	}
+
+	if !strings.Contains(content, "```json\n{") || !strings.Contains(content, `"body_text": "ok"`) {
+		t.Fatalf("expected a JSON block holding the comments, got %q", content)
+	}
+
+	base := filepath.Base(path)
+	if base != "pr-123-add-feature.md" {
+		t.Fatalf("unexpected filename %q, expected pr-123-add-feature.md", base)
+	}
+}
+
+func TestSyncSavedOutputRespectsCustomDirectory(t *testing.T) {
+	repoRoot := t.TempDir()
+	customDir := "codex-artifacts"
+	pr := &PullRequestSummary{Number: 42, Title: "Custom Save", HeadRef: "feature"}
+	payload := commentPayload{}
+
+	path, _, err := SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{}, false, customDir)
+	if err != nil {
+		t.Fatalf("SyncSavedOutput returned error: %v", err)
+	}
+
+	expectedDir := filepath.Join(repoRoot, customDir)
+	if dirInfo, err := os.Stat(expectedDir); err != nil || !dirInfo.IsDir() {
+		t.Fatalf("expected custom directory %s to exist", expectedDir)
+	}
+	if !strings.HasPrefix(path, expectedDir+string(os.PathSeparator)) {
+		t.Fatalf("expected path %q to reside within %s", path, expectedDir)
+	}
+}
+
+func TestSyncSavedOutputSupportsAbsoluteDirectory(t *testing.T) {
+	repoRoot := t.TempDir()
+	absoluteDir := filepath.Join(t.TempDir(), "gh-pr-comments-artifacts")
+	pr := &PullRequestSummary{
+		Number:    7,
+		Title:     "Absolute",
+		HeadRef:   "feature",
+		RepoOwner: "octo",
+		RepoName:  "repo",
+	}
+	payload := commentPayload{}
+
+	path, _, err := SyncSavedOutput(repoRoot, pr, payload, NormalizationOptions{}, false, absoluteDir)
+	if err != nil {
+		t.Fatalf("SyncSavedOutput returned error: %v", err)
+	}
+
+	expectedDir := filepath.Join(absoluteDir, "octo", "repo")
+	if info, err := os.Stat(expectedDir); err != nil || !info.IsDir() {
+		t.Fatalf("expected directory %s to exist", expectedDir)
+	}
+	if !strings.HasPrefix(path, expectedDir+string(os.PathSeparator)) {
+		t.Fatalf("expected path %q to reside within %s", path, expectedDir)
+	}
+}
+
+func TestSyncSavedOutputRequiresPullRequestNumber(t *testing.T) {
+	repoRoot := t.TempDir()
+	payload := commentPayload{}
+
+	if _, _, err := SyncSavedOutput(repoRoot, nil, payload, NormalizationOptions{}, false, ""); err == nil {
+		t.Fatal("expected error when PR is nil")
+	}
+
+	if _, _, err := SyncSavedOutput(repoRoot, &PullRequestSummary{Number: 0}, payload, NormalizationOptions{}, false, ""); err == nil {
+		t.Fatal("expected error when PR number is zero")
+	}
+}
@@ -0,0 +1,160 @@
+package ghprcomments
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// TemplateNames lists the templates shipped in the binary.
+func TemplateNames() []string {
+	entries, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tmpl"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTemplate parses a template from a file on disk, falling back to the
+// built-in template of the same name.
+func LoadTemplate(nameOrPath string) (*template.Template, error) {
+	if data, err := os.ReadFile(nameOrPath); err == nil {
+		return ParseTemplate(path.Base(nameOrPath), string(data))
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+
+	data, err := builtinTemplates.ReadFile("templates/" + nameOrPath + ".tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("template %q is neither a file nor a built-in template (available: %s)", nameOrPath, strings.Join(TemplateNames(), ", "))
+	}
+	return ParseTemplate(nameOrPath, string(data))
+}
+
+// ParseTemplate compiles text with the helper functions available to comment templates.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs(time.Now)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate executes tmpl against the output.
+func RenderTemplate(w io.Writer, tmpl *template.Template, out Output) error {
+	if err := tmpl.Execute(w, out); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	return nil
+}
+
+func templateFuncs(now func() time.Time) template.FuncMap {
+	return template.FuncMap{
+		// comments flattens grouped or threaded output, newest first.
+		"comments": outputComments,
+		// authors returns per-author groups even when the output was threaded.
+		"authors":      templateAuthors,
+		"commentType":  formatCommentType,
+		"location":     commentLocation,
+		"relativeTime": func(t time.Time) string { return relativeTime(t, now()) },
+		"truncate":     truncateText,
+		"oneline":      func(s string) string { return strings.Join(strings.Fields(s), " ") },
+		"link":         markdownLink,
+		"quote":        blockQuote,
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"date": func(layout string, t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format(layout)
+		},
+	}
+}
+
+func templateAuthors(out Output) []AuthorComments {
+	if outputGroupBy(out) == GroupByAuthor {
+		return out.Comments
+	}
+	return groupByAuthor(outputComments(out))
+}
+
+func commentLocation(c Comment) string {
+	switch {
+	case c.Path == "":
+		return ""
+	case c.Line != nil:
+		return fmt.Sprintf("%s:%d", c.Path, *c.Line)
+	case c.OriginalLine != nil:
+		return fmt.Sprintf("%s:%d", c.Path, *c.OriginalLine)
+	default:
+		return c.Path
+	}
+}
+
+// relativeTime describes t relative to now in the coarse units a digest needs.
+func relativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "at an unknown time"
+	}
+	d := now.Sub(t)
+	suffix := "ago"
+	if d < 0 {
+		d, suffix = -d, "from now"
+	}
+	var n int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		n, unit = int(d/(365*24*time.Hour)), "year"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s %s", n, unit, suffix)
+}
+
+// truncateText shortens s to at most n runes, marking the cut with an ellipsis.
+func truncateText(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return strings.TrimRight(string(runes[:n-1]), " ") + "…"
+}
+
+func markdownLink(text, url string) string {
+	if url == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s)", text, url)
+}
@@ -0,0 +1,94 @@
+package ghprcomments
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadTemplateBuiltins(t *testing.T) {
+	names := TemplateNames()
+	if strings.Join(names, ",") != "compact,digest" {
+		t.Fatalf("TemplateNames() = %v", names)
+	}
+
+	for _, name := range names {
+		tmpl, err := LoadTemplate(name)
+		if err != nil {
+			t.Fatalf("LoadTemplate(%q): %v", name, err)
+		}
+		var b strings.Builder
+		if err := RenderTemplate(&b, tmpl, sampleFormatOutput()); err != nil {
+			t.Fatalf("render %q: %v", name, err)
+		}
+		if !strings.Contains(b.String(), "nit, rename") {
+			t.Fatalf("%s output missing comment body:\n%s", name, b.String())
+		}
+	}
+
+	if _, err := LoadTemplate("weekly"); err == nil || !strings.Contains(err.Error(), "available: compact, digest") {
+		t.Fatalf("LoadTemplate(weekly) error = %v", err)
+	}
+}
+
+func TestLoadTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digest.tmpl")
+	text := `{{range authors .}}{{.Author}}:{{range .Comments}} {{commentType .Type}}@{{location .}}={{truncate 6 .BodyText}}{{end}}
+{{end}}`
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	var b strings.Builder
+	if err := RenderTemplate(&b, tmpl, sampleFormatOutput()); err != nil {
+		t.Fatalf("RenderTemplate: %v", err)
+	}
+	want := "alice: Review Comment@pkg/a.go:12=nit,… Issue@=looks…\nbob: Review Comment@pkg/a.go=why?\n"
+	if b.String() != want {
+		t.Fatalf("unexpected output\n got: %q\nwant: %q", b.String(), want)
+	}
+
+	if err := os.WriteFile(path, []byte("{{.Missing"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplate(path); err == nil || !strings.Contains(err.Error(), "parse template") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Time{}, "at an unknown time"},
+		{now.Add(-20 * time.Second), "just now"},
+		{now.Add(-time.Minute), "1 minute ago"},
+		{now.Add(-5 * time.Hour), "5 hours ago"},
+		{now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{now.Add(-70 * 24 * time.Hour), "2 months ago"},
+		{now.Add(-800 * 24 * time.Hour), "2 years ago"},
+		{now.Add(2 * time.Hour), "2 hours from now"},
+	}
+	for _, tc := range testCases {
+		if got := relativeTime(tc.at, now); got != tc.want {
+			t.Fatalf("relativeTime(%v) = %q, want %q", tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	if got := truncateText(5, "héllo"); got != "héllo" {
+		t.Fatalf("unexpected truncation of short text: %q", got)
+	}
+	if got := truncateText(4, "héllo world"); got != "hél…" {
+		t.Fatalf("truncateText = %q", got)
+	}
+}
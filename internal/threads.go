@@ -0,0 +1,112 @@
+package ghprcomments
+
+import (
+	"sort"
+	"time"
+)
+
+// Thread is a root comment together with every reply made to it.
+type Thread struct {
+	ID           int64     `json:"id"`
+	Path         string    `json:"path,omitempty"`
+	Line         *int      `json:"line,omitempty"`
+	Resolved     *bool     `json:"resolved,omitempty"`
+	Outdated     *bool     `json:"outdated,omitempty"`
+	LastActivity time.Time `json:"last_activity"`
+	CommentCount int       `json:"comment_count"`
+	Root         Comment   `json:"root"`
+	Replies      []Comment `json:"replies,omitempty"`
+}
+
+// buildThreads nests review-comment replies under their root comment.
+// Issue comments and review events have no reply chain, so each forms a
+// thread of its own. Replies are ordered oldest-first to read as a
+// conversation, and threads are ordered by most recent activity.
+func buildThreads(comments []Comment) []Thread {
+	byID := make(map[int64]Comment, len(comments))
+	for _, c := range comments {
+		if c.Type == "review_comment" && c.ID != 0 {
+			byID[c.ID] = c
+		}
+	}
+
+	rootOf := func(c Comment) int64 {
+		current := c
+		seen := map[int64]struct{}{current.ID: {}}
+		for current.InReplyTo != 0 {
+			parent, ok := byID[current.InReplyTo]
+			if !ok {
+				break
+			}
+			if _, loop := seen[parent.ID]; loop {
+				break
+			}
+			seen[parent.ID] = struct{}{}
+			current = parent
+		}
+		return current.ID
+	}
+
+	threads := make([]Thread, 0, len(comments))
+	index := make(map[int64]int)
+	var replies []Comment
+
+	for _, c := range comments {
+		if c.Type != "review_comment" {
+			threads = append(threads, Thread{ID: c.ID, Root: c})
+			continue
+		}
+		root := rootOf(c)
+		if root != c.ID {
+			replies = append(replies, c)
+			continue
+		}
+		index[c.ID] = len(threads)
+		threads = append(threads, Thread{ID: c.ID, Path: c.Path, Line: c.Line, Resolved: c.Resolved, Outdated: c.Outdated, Root: c})
+	}
+
+	for _, reply := range replies {
+		idx, ok := index[rootOf(reply)]
+		if !ok {
+			continue
+		}
+		threads[idx].Replies = append(threads[idx].Replies, reply)
+	}
+
+	for i := range threads {
+		t := &threads[i]
+		sort.SliceStable(t.Replies, func(a, b int) bool {
+			ra, rb := t.Replies[a], t.Replies[b]
+			if ra.CreatedAt.Equal(rb.CreatedAt) {
+				return ra.ID < rb.ID
+			}
+			return ra.CreatedAt.Before(rb.CreatedAt)
+		})
+		if t.Path == "" {
+			for _, reply := range t.Replies {
+				if reply.Path != "" {
+					t.Path = reply.Path
+					t.Line = reply.Line
+					break
+				}
+			}
+		}
+		t.LastActivity = t.Root.CreatedAt
+		for _, reply := range t.Replies {
+			if reply.CreatedAt.After(t.LastActivity) {
+				t.LastActivity = reply.CreatedAt
+			}
+		}
+		t.CommentCount = 1 + len(t.Replies)
+	}
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		ti, tj := threads[i], threads[j]
+		if ti.LastActivity.Equal(tj.LastActivity) {
+			return ti.ID > tj.ID
+		}
+		return ti.LastActivity.After(tj.LastActivity)
+	})
+
+	return threads
+}
@@ -0,0 +1,104 @@
+package ghprcomments
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestBuildOutputThreadsNestsReplies(t *testing.T) {
+	base := time.Date(2025, time.November, 3, 9, 0, 0, 0, time.UTC)
+	ts := func(minutes int) *github.Timestamp {
+		return &github.Timestamp{Time: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	payload := commentPayload{
+		issueComments: []*github.IssueComment{
+			{ID: github.Int64(1), Body: github.String("general note"), CreatedAt: ts(5), User: &github.User{Login: github.String("carol")}},
+		},
+		reviewComments: []*github.PullRequestComment{
+			{ID: github.Int64(10), Body: github.String("rename this"), Path: github.String("main.go"), Line: github.Int(12), CreatedAt: ts(0), User: &github.User{Login: github.String("alice")}},
+			{ID: github.Int64(12), InReplyTo: github.Int64(11), Body: github.String("thanks"), CreatedAt: ts(30), User: &github.User{Login: github.String("alice")}},
+			{ID: github.Int64(11), InReplyTo: github.Int64(10), Body: github.String("done"), CreatedAt: ts(20), User: &github.User{Login: github.String("bob")}},
+			{ID: github.Int64(20), Body: github.String("typo"), Path: github.String("util.go"), Line: github.Int(3), CreatedAt: ts(1), User: &github.User{Login: github.String("alice")}},
+		},
+	}
+
+	pr := &PullRequestSummary{Number: 7, RepoOwner: "octo", RepoName: "repo"}
+	out := BuildOutput(pr, payload, NormalizationOptions{Threads: true})
+
+	if len(out.Comments) != 0 {
+		t.Fatalf("expected no author groups in thread mode, got %d", len(out.Comments))
+	}
+	encoded, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"comments":[],"threads":[`) {
+		t.Fatalf("expected an empty comments key alongside threads, got %s", encoded)
+	}
+	if out.CommentCount != 5 {
+		t.Fatalf("expected comment count 5, got %d", out.CommentCount)
+	}
+	if len(out.Threads) != 3 {
+		t.Fatalf("expected 3 threads, got %d", len(out.Threads))
+	}
+
+	first := out.Threads[0]
+	if first.ID != 10 {
+		t.Fatalf("expected most recently active thread first, got root %d", first.ID)
+	}
+	if first.Path != "main.go" || first.Line == nil || *first.Line != 12 {
+		t.Fatalf("expected thread anchored at main.go:12, got %s:%v", first.Path, first.Line)
+	}
+	if len(first.Replies) != 2 || first.Replies[0].ID != 11 || first.Replies[1].ID != 12 {
+		t.Fatalf("expected replies 11 then 12, got %+v", first.Replies)
+	}
+	if first.CommentCount != 3 {
+		t.Fatalf("expected thread comment count 3, got %d", first.CommentCount)
+	}
+	if !first.LastActivity.Equal(base.Add(30 * time.Minute)) {
+		t.Fatalf("expected last activity at latest reply, got %v", first.LastActivity)
+	}
+
+	if out.Threads[1].Root.Type != "issue" || out.Threads[2].ID != 20 {
+		t.Fatalf("unexpected thread order: %d, %d", out.Threads[1].ID, out.Threads[2].ID)
+	}
+}
+
+func TestBuildThreadsOrphanReplyBecomesRoot(t *testing.T) {
+	threads := buildThreads([]Comment{
+		{Type: "review_comment", ID: 5, InReplyTo: 4, Path: "a.go"},
+	})
+	if len(threads) != 1 || threads[0].ID != 5 {
+		t.Fatalf("expected orphaned reply to start its own thread, got %+v", threads)
+	}
+}
+
+func TestRenderMarkdownThreads(t *testing.T) {
+	line := 12
+	out := Output{
+		PR: PullRequestMetadata{Repo: "octo/repo", Number: 7},
+		Threads: []Thread{
+			{
+				ID:   10,
+				Path: "main.go",
+				Line: &line,
+				Root: Comment{Type: "review_comment", Author: "alice", BodyText: "rename this"},
+				Replies: []Comment{
+					{Type: "review_comment", Author: "bob", BodyText: "done"},
+				},
+			},
+		},
+	}
+
+	markup := RenderMarkdown(out)
+	for _, want := range []string{"## main.go:12", "### Review Comment by alice", "#### Reply by bob", "> done"} {
+		if !strings.Contains(markup, want) {
+			t.Fatalf("expected markdown to contain %q, got:\n%s", want, markup)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package ghprcomments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// Comment types for pull request timeline events.
+const (
+	EventCommitted            = "event_committed"
+	EventForcePushed          = "event_force_pushed"
+	EventReviewRequested      = "event_review_requested"
+	EventReviewRequestRemoved = "event_review_request_removed"
+	EventLabeled              = "event_labeled"
+	EventUnlabeled            = "event_unlabeled"
+	EventReadyForReview       = "event_ready_for_review"
+	EventConvertedToDraft     = "event_converted_to_draft"
+	EventMerged               = "event_merged"
+	EventClosed               = "event_closed"
+	EventReopened             = "event_reopened"
+)
+
+// timelineEventTypes maps the REST timeline event names we surface to comment types.
+var timelineEventTypes = map[string]string{
+	"committed":              EventCommitted,
+	"head_ref_force_pushed":  EventForcePushed,
+	"review_requested":       EventReviewRequested,
+	"review_request_removed": EventReviewRequestRemoved,
+	"labeled":                EventLabeled,
+	"unlabeled":              EventUnlabeled,
+	"ready_for_review":       EventReadyForReview,
+	"convert_to_draft":       EventConvertedToDraft,
+	"merged":                 EventMerged,
+	"closed":                 EventClosed,
+	"reopened":               EventReopened,
+}
+
+// IsTimelineEvent reports whether a comment type is a timeline event rather than feedback.
+func IsTimelineEvent(kind string) bool {
+	return strings.HasPrefix(kind, "event_")
+}
+
+// WithTimeline returns a Fetcher whose FetchComments also retrieves the issue
+// timeline, so BuildOutput interleaves pushes, review requests, label changes
+// and state transitions with the feedback.
+func (f *Fetcher) WithTimeline() *Fetcher {
+	clone := *f
+	clone.timeline = true
+	return &clone
+}
+
+func (f *Fetcher) listTimeline(ctx context.Context, owner, repo string, number int) ([]*github.Timeline, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var all []*github.Timeline
+	for {
+		items, resp, err := f.client.Issues.ListIssueTimeline(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// normalizeTimelineEvent converts a timeline entry into a Comment. Events we do
+// not surface (comments and reviews arrive through their own endpoints) report false.
+func normalizeTimelineEvent(ev *github.Timeline, prURL string) (Comment, bool) {
+	kind, ok := timelineEventTypes[ev.GetEvent()]
+	if !ok {
+		return Comment{}, false
+	}
+
+	c := Comment{
+		Type:      kind,
+		ID:        ev.GetID(),
+		Author:    canonicalAuthor(safeLogin(ev.Actor)),
+		IsBot:     IsBotAuthor(ev.Actor),
+		CreatedAt: derefTimestamp(ev.CreatedAt),
+		CommitID:  ev.GetCommitID(),
+	}
+	if c.ID != 0 && prURL != "" {
+		c.Permalink = fmt.Sprintf("%s#event-%d", prURL, c.ID)
+	}
+
+	var text string
+	switch kind {
+	case EventCommitted:
+		c.CommitID = ev.GetSHA()
+		if ev.Author != nil {
+			c.Author = canonicalAuthor(ev.Author.GetName())
+			c.CreatedAt = derefTimestamp(ev.Author.Date)
+		}
+		if ev.Committer != nil && ev.Committer.Date != nil {
+			c.CreatedAt = ev.Committer.Date.Time
+		}
+		if prURL != "" && c.CommitID != "" {
+			c.Permalink = prURL + "/commits/" + c.CommitID
+		}
+		subject, _, _ := strings.Cut(ev.GetMessage(), "\n")
+		text = fmt.Sprintf("Pushed commit %s: %s", ShortSHA(c.CommitID), strings.TrimSpace(subject))
+	case EventForcePushed:
+		text = "Force-pushed the branch"
+		if c.CommitID != "" {
+			text += " to " + ShortSHA(c.CommitID)
+		}
+	case EventReviewRequested, EventReviewRequestRemoved:
+		verb := "Requested review from "
+		if kind == EventReviewRequestRemoved {
+			verb = "Removed review request for "
+		}
+		text = verb + reviewRequestTarget(ev)
+	case EventLabeled:
+		text = fmt.Sprintf("Added label %q", ev.GetLabel().GetName())
+	case EventUnlabeled:
+		text = fmt.Sprintf("Removed label %q", ev.GetLabel().GetName())
+	case EventReadyForReview:
+		text = "Marked as ready for review"
+	case EventConvertedToDraft:
+		text = "Converted to draft"
+	case EventMerged:
+		text = "Merged"
+		if c.CommitID != "" {
+			text += " as " + ShortSHA(c.CommitID)
+		}
+	case EventClosed:
+		text = "Closed"
+	case EventReopened:
+		text = "Reopened"
+	}
+	c.BodyText = text
+	c.BodyMarkdown = text
+	return c, true
+}
+
+func reviewRequestTarget(ev *github.Timeline) string {
+	if ev.Reviewer != nil {
+		return "@" + ev.Reviewer.GetLogin()
+	}
+	if ev.RequestedTeam != nil {
+		return "team " + ev.RequestedTeam.GetName()
+	}
+	return "(unknown)"
+}
+
+// ShortSHA abbreviates a commit SHA to the seven characters GitHub shows.
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,81 @@
+package ghprcomments
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const timelineFixture = `[
+  {"event": "committed", "sha": "0123456789abcdef", "message": "Add parser\n\nLonger body", "author": {"name": "Dana", "date": "2024-05-01T09:00:00Z"}, "committer": {"name": "Dana", "date": "2024-05-01T09:05:00Z"}},
+  {"id": 11, "event": "review_requested", "actor": {"login": "dana"}, "requested_reviewer": {"login": "alice"}, "created_at": "2024-05-01T09:10:00Z"},
+  {"id": 12, "event": "commented", "actor": {"login": "alice"}, "created_at": "2024-05-01T10:00:00Z"},
+  {"id": 13, "event": "labeled", "actor": {"login": "renovate[bot]", "type": "Bot"}, "label": {"name": "deps"}, "created_at": "2024-05-01T10:30:00Z"},
+  {"id": 14, "event": "head_ref_force_pushed", "actor": {"login": "dana"}, "commit_id": "fedcba9876543210", "created_at": "2024-05-01T11:00:00Z"},
+  {"id": 15, "event": "ready_for_review", "actor": {"login": "dana"}, "created_at": "2024-05-01T11:30:00Z"},
+  {"id": 16, "event": "merged", "actor": {"login": "alice"}, "commit_id": "aaaaaaa1111111", "created_at": "2024-05-02T08:00:00Z"}
+]`
+
+func TestFetchCommentsWithTimeline(t *testing.T) {
+	var timelineRequests int
+	server, client := mockGitHubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/1/timeline":
+			timelineRequests++
+			w.Write([]byte(timelineFixture))
+		case "/repos/owner/repo/issues/1/comments", "/repos/owner/repo/pulls/1/comments", "/repos/owner/repo/pulls/1/reviews":
+			w.Write([]byte(`[]`))
+		case "/graphql":
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}}}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	ctx := context.Background()
+	if _, err := NewFetcher(client).FetchComments(ctx, "owner", "repo", 1); err != nil || timelineRequests != 0 {
+		t.Fatalf("plain fetch should skip the timeline (err=%v, requests=%d)", err, timelineRequests)
+	}
+
+	payload, err := NewFetcher(client).WithTimeline().FetchComments(ctx, "owner", "repo", 1)
+	if err != nil {
+		t.Fatalf("FetchComments: %v", err)
+	}
+	pr := &PullRequestSummary{Number: 1, URL: "https://github.com/owner/repo/pull/1"}
+	out := BuildOutput(pr, payload, NormalizationOptions{})
+	if out.CommentCount != 6 {
+		t.Fatalf("expected 6 events (commented is skipped), got %d", out.CommentCount)
+	}
+
+	byType := make(map[string]Comment)
+	for _, c := range outputComments(out) {
+		byType[c.Type] = c
+	}
+	commit := byType[EventCommitted]
+	if commit.BodyText != "Pushed commit 0123456: Add parser" || commit.Author != "Dana" || commit.Permalink != pr.URL+"/commits/0123456789abcdef" {
+		t.Fatalf("unexpected commit event %+v", commit)
+	}
+	if !commit.CreatedAt.Equal(time.Date(2024, 5, 1, 9, 5, 0, 0, time.UTC)) {
+		t.Fatalf("commit time = %v, want committer date", commit.CreatedAt)
+	}
+	if got := byType[EventReviewRequested].BodyText; got != "Requested review from @alice" {
+		t.Fatalf("review request text = %q", got)
+	}
+	if label := byType[EventLabeled]; label.BodyText != `Added label "deps"` || !label.IsBot || label.Permalink != pr.URL+"#event-13" {
+		t.Fatalf("unexpected label event %+v", label)
+	}
+	if got := byType[EventForcePushed].BodyText; got != "Force-pushed the branch to fedcba9" {
+		t.Fatalf("force-push text = %q", got)
+	}
+	if got := byType[EventMerged].BodyText; got != "Merged as aaaaaaa" {
+		t.Fatalf("merge text = %q", got)
+	}
+
+	markdown := RenderMarkdown(out)
+	if !strings.Contains(markdown, "### Marked as ready for review — 2024-05-01T11:30:00Z\n- By: dana\n- Link: "+pr.URL+"#event-15") {
+		t.Fatalf("markdown missing event entry:\n%s", markdown)
+	}
+}
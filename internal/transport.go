@@ -0,0 +1,212 @@
+package ghprcomments
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ConditionalTransport revalidates repeated GET requests with If-None-Match.
+// When GitHub answers 304 Not Modified the previously stored body is replayed
+// as a 200, so callers see an unchanged response while the request does not
+// count against the rate limit.
+type ConditionalTransport struct {
+	base  http.RoundTripper
+	store responseStore
+}
+
+// responseStore persists validated responses keyed by request.
+type responseStore interface {
+	load(key string) (cachedResponse, bool)
+	save(key string, entry cachedResponse)
+}
+
+type cachedResponse struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// NewConditionalTransport wraps base (or http.DefaultTransport when nil) with
+// ETag revalidation backed by an in-memory store.
+func NewConditionalTransport(base http.RoundTripper) *ConditionalTransport {
+	return newConditionalTransport(base, &memoryStore{entries: make(map[string]cachedResponse)})
+}
+
+// NewDiskCacheTransport is like NewConditionalTransport but keeps responses
+// under dir so they survive between invocations.
+func NewDiskCacheTransport(base http.RoundTripper, dir string) *ConditionalTransport {
+	return newConditionalTransport(base, &diskStore{dir: dir})
+}
+
+func newConditionalTransport(base http.RoundTripper, store responseStore) *ConditionalTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &ConditionalTransport{base: base, store: store}
+}
+
+// DefaultCacheDir returns the directory used for the on-disk HTTP cache.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gh-pr-comments", "http"), nil
+}
+
+// ClearCache removes every cached response stored under dir.
+func ClearCache(dir string) error {
+	if dir == "" {
+		return errors.New("cache directory is not set")
+	}
+	return os.RemoveAll(dir)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ConditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, cached := t.store.load(key)
+
+	if cached {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return replayResponse(req, entry, resp.Header), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.store.save(key, cachedResponse{ETag: etag, Header: resp.Header.Clone(), Body: body})
+	return resp, nil
+}
+
+// cacheKey distinguishes requests by URL and Accept header, since GitHub
+// serves different representations of the same URL per media type.
+func cacheKey(req *http.Request) string {
+	return req.URL.String() + "\n" + req.Header.Get("Accept")
+}
+
+// replayResponse rebuilds a 200 from a stored entry, keeping the fresh
+// rate-limit headers from the 304 so quota reporting stays accurate.
+func replayResponse(req *http.Request, entry cachedResponse, fresh http.Header) *http.Response {
+	header := entry.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	for _, name := range []string{"X-Ratelimit-Limit", "X-Ratelimit-Remaining", "X-Ratelimit-Reset", "X-Ratelimit-Used", "Date"} {
+		if value := fresh.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	header.Set("X-From-Cache", "1")
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+func (s *memoryStore) load(key string) (cachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	return entry, ok
+}
+
+func (s *memoryStore) save(key string, entry cachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry
+}
+
+// diskStore keeps one JSON file per request. Failures are treated as cache
+// misses so a broken cache never fails a request.
+type diskStore struct {
+	dir string
+}
+
+func (s *diskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, name[:2], name+".json")
+}
+
+func (s *diskStore) load(key string) (cachedResponse, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return cachedResponse{}, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ETag == "" {
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+func (s *diskStore) save(key string, entry cachedResponse) {
+	target := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	// Write to a temporary file first so concurrent readers never see a partial entry.
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".entry-*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,158 @@
+package ghprcomments
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConditionalTransport_ReplaysNotModified(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[1,2,3]`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewConditionalTransport(nil)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/items")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, resp.StatusCode)
+		}
+		if string(body) != `[1,2,3]` {
+			t.Fatalf("request %d: unexpected body %q", i, body)
+		}
+		wantCache := ""
+		if i == 1 {
+			wantCache = "1"
+		}
+		if got := resp.Header.Get("X-From-Cache"); got != wantCache {
+			t.Errorf("request %d: X-From-Cache = %q, want %q", i, got, wantCache)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 upstream requests, got %d", requests)
+	}
+}
+
+func TestConditionalTransport_SkipsNonGET(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("unexpected If-None-Match on %s", r.Method)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewConditionalTransport(nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(server.URL, "application/json", nil)
+		if err != nil {
+			t.Fatalf("post failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestDiskCacheTransport_PersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	conditional := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"accept":"` + r.Header.Get("Accept") + `"}`))
+	}))
+	defer server.Close()
+
+	get := func(accept string) string {
+		t.Helper()
+		// A fresh transport per request mimics separate invocations of the CLI.
+		client := &http.Client{Transport: NewDiskCacheTransport(nil, dir)}
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/repos/o/r/pulls", nil)
+		req.Header.Set("Accept", accept)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		return string(body)
+	}
+
+	if got := get("application/json"); got != `{"accept":"application/json"}` {
+		t.Fatalf("unexpected first body %q", got)
+	}
+	if got := get("application/json"); got != `{"accept":"application/json"}` {
+		t.Fatalf("unexpected cached body %q", got)
+	}
+	if conditional != 1 {
+		t.Fatalf("expected second invocation to revalidate from disk, got %d conditional requests", conditional)
+	}
+	if got := get("text/html"); got != `{"accept":"text/html"}` {
+		t.Fatalf("expected a different media type to miss the cache, got %q", got)
+	}
+
+	if err := ClearCache(dir); err != nil {
+		t.Fatalf("ClearCache failed: %v", err)
+	}
+	get("application/json")
+	if conditional != 1 {
+		t.Fatalf("expected cleared cache to issue an unconditional request, got %d conditional requests", conditional)
+	}
+}
+
+func TestDiskCacheTransport_IgnoresCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("unexpected conditional request with corrupt cache entry")
+		}
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	store := &diskStore{dir: dir}
+	path := store.path(cacheKey(req))
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&http.Client{Transport: NewDiskCacheTransport(nil, dir)}).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
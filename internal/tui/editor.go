@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Checkout locates the local clone of the explored pull request so review
+// comments can be opened in an editor.
+type Checkout struct {
+	Dir     string // repository root
+	HeadSHA string // pull request head, compared against the local HEAD
+}
+
+// editorCommand overrides $VISUAL and $EDITOR when set from config.
+var editorCommand string
+
+// SetEditorCommand sets the command used to open files. It may contain {file}
+// and {line} placeholders; without them the file and line are appended in the
+// form the editor understands. An empty command falls back to $VISUAL, then $EDITOR.
+func SetEditorCommand(command string) {
+	editorCommand = strings.TrimSpace(command)
+}
+
+// editorFinishedMsg reports the editor exiting and carries any warning to keep
+// on the status line.
+type editorFinishedMsg struct {
+	warning string
+	err     error
+}
+
+// commentLocation finds the path and line of the comment enclosing node.
+func commentLocation(node *JSONNode) (string, int, bool) {
+	for ; node != nil; node = node.Parent {
+		if node.Type != "object" {
+			continue
+		}
+		fields, ok := node.Value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, _ := fields["path"].(string)
+		if path == "" {
+			continue
+		}
+		for _, name := range []string{"line", "original_line"} {
+			if line, ok := fields[name].(float64); ok && line > 0 {
+				return path, int(line), true
+			}
+		}
+		return path, 0, true
+	}
+	return "", 0, false
+}
+
+// openInEditor starts the editor at path:line inside the checkout, suspending
+// the TUI until it exits.
+func openInEditor(checkout Checkout, path string, line int) (tea.Cmd, error) {
+	if checkout.Dir == "" {
+		return nil, errors.New("no local checkout for this pull request")
+	}
+	file := filepath.Join(checkout.Dir, filepath.FromSlash(path))
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("%s is not in the local checkout", path)
+	}
+
+	args, err := editorArgs(resolveEditor(), file, line)
+	if err != nil {
+		return nil, err
+	}
+	warning := headMismatch(checkout)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = checkout.Dir
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{warning: warning, err: err}
+	}), nil
+}
+
+func resolveEditor() string {
+	if editorCommand != "" {
+		return editorCommand
+	}
+	if visual := strings.TrimSpace(os.Getenv("VISUAL")); visual != "" {
+		return visual
+	}
+	return strings.TrimSpace(os.Getenv("EDITOR"))
+}
+
+// editorArgs builds the argv that opens file at line with command.
+func editorArgs(command, file string, line int) ([]string, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("no editor configured; set $EDITOR or editor in the config file")
+	}
+
+	lineText := strconv.Itoa(max(line, 1))
+	if strings.Contains(command, "{file}") || strings.Contains(command, "{line}") {
+		replacer := strings.NewReplacer("{file}", file, "{line}", lineText)
+		for i, field := range fields {
+			fields[i] = replacer.Replace(field)
+		}
+		return fields, nil
+	}
+
+	if line <= 0 {
+		return append(fields, file), nil
+	}
+	switch strings.TrimSuffix(filepath.Base(fields[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor":
+		return append(fields, "--goto", file+":"+lineText), nil
+	case "subl", "zed", "hx", "helix":
+		return append(fields, file+":"+lineText), nil
+	default:
+		// vi, vim, nvim, nano, emacs, micro and most terminal editors.
+		return append(fields, "+"+lineText, file), nil
+	}
+}
+
+// headMismatch warns when the checkout is not at the pull request head, since
+// comment line numbers may then point at different code.
+func headMismatch(checkout Checkout) string {
+	if checkout.HeadSHA == "" {
+		return ""
+	}
+	output, err := exec.Command("git", "-C", checkout.Dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "could not read local HEAD; lines may not match the PR"
+	}
+	local := strings.TrimSpace(string(output))
+	if local == checkout.HeadSHA {
+		return ""
+	}
+	return fmt.Sprintf("local HEAD %s differs from PR head %s; lines may not match", ghprcomments.ShortSHA(local), ghprcomments.ShortSHA(checkout.HeadSHA))
+}
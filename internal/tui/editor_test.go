@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditorArgs(t *testing.T) {
+	tests := []struct {
+		command string
+		line    int
+		want    []string
+	}{
+		{command: "vim", line: 12, want: []string{"vim", "+12", "/src/a.go"}},
+		{command: "nvim", line: 0, want: []string{"nvim", "/src/a.go"}},
+		{command: "code -w", line: 12, want: []string{"code", "-w", "--goto", "/src/a.go:12"}},
+		{command: "/usr/local/bin/subl", line: 3, want: []string{"/usr/local/bin/subl", "/src/a.go:3"}},
+		{command: "idea --line {line} {file}", line: 7, want: []string{"idea", "--line", "7", "/src/a.go"}},
+	}
+	for _, tc := range tests {
+		got, err := editorArgs(tc.command, "/src/a.go", tc.line)
+		if err != nil {
+			t.Fatalf("editorArgs(%q): %v", tc.command, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("editorArgs(%q) = %q, want %q", tc.command, got, tc.want)
+		}
+	}
+
+	if _, err := editorArgs("  ", "/src/a.go", 1); err == nil {
+		t.Fatal("expected an error without an editor")
+	}
+}
+
+func TestCommentLocation(t *testing.T) {
+	tree := buildTree("", map[string]interface{}{
+		"comments": []interface{}{
+			map[string]interface{}{"path": "pkg/a.go", "line": float64(12), "body_text": "nit"},
+			map[string]interface{}{"path": "pkg/b.go", "line": nil, "original_line": float64(4)},
+			map[string]interface{}{"body_text": "conversation comment"},
+		},
+	}, nil, 0)
+	comments := tree.Children[0]
+
+	body := comments.Children[0].Children[0]
+	for _, child := range comments.Children[0].Children {
+		if child.Key == "body_text" {
+			body = child
+		}
+	}
+	if path, line, ok := commentLocation(body); !ok || path != "pkg/a.go" || line != 12 {
+		t.Fatalf("commentLocation(body) = %q, %d, %v", path, line, ok)
+	}
+	if path, line, ok := commentLocation(comments.Children[1]); !ok || path != "pkg/b.go" || line != 4 {
+		t.Fatalf("expected original_line fallback, got %q, %d, %v", path, line, ok)
+	}
+	if _, _, ok := commentLocation(comments.Children[2]); ok {
+		t.Fatal("comments without a path have no location")
+	}
+}
+
+func TestOpenInEditorRequiresCheckout(t *testing.T) {
+	if _, err := openInEditor(Checkout{}, "a.go", 1); err == nil || !strings.Contains(err.Error(), "no local checkout") {
+		t.Fatalf("expected missing checkout error, got %v", err)
+	}
+	if _, err := openInEditor(Checkout{Dir: t.TempDir()}, "missing.go", 1); err == nil || !strings.Contains(err.Error(), "not in the local checkout") {
+		t.Fatalf("expected missing file error, got %v", err)
+	}
+}
+
+func TestHeadMismatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "a.go")
+	git("commit", "-q", "-m", "init")
+	head := git("rev-parse", "HEAD")
+
+	if warning := headMismatch(Checkout{Dir: dir, HeadSHA: head}); warning != "" {
+		t.Fatalf("unexpected warning at the PR head: %q", warning)
+	}
+	warning := headMismatch(Checkout{Dir: dir, HeadSHA: "0123456789abcdef"})
+	if !strings.Contains(warning, "differs from PR head 0123456") || !strings.Contains(warning, head[:7]) {
+		t.Fatalf("unexpected warning %q", warning)
+	}
+}
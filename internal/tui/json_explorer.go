@@ -10,6 +10,7 @@ import (
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -31,13 +32,22 @@ type JSONExplorerModel struct {
 	width        int
 	height       int
 	quitting     bool
+	statusMsg    string // Transient message appended to the footer status line
+	checkout     Checkout
+
+	// Replies and reactions (disabled while actions is nil)
+	actions      CommentActions
+	replyMode    bool
+	replyInput   textarea.Model
+	reactMode    bool
+	actionTarget string // nodeIdentity of the comment being replied or reacted to
 }
 
 // JSONNode represents a node in the JSON tree structure.
 type JSONNode struct {
 	Key            string
 	Value          interface{}
-	Type           string // "object", "array", "string", "number", "bool", "null"
+	Type           string // "object", "array", "string", "number", "bool", "null", "diff", "diff_line"
 	Children       []*JSONNode
 	Parent         *JSONNode
 	Expanded       bool
@@ -69,6 +79,10 @@ type KeyMap struct {
 	ClearSearch  key.Binding
 	Copy         key.Binding
 	OpenURL      key.Binding
+	OpenEditor   key.Binding
+	Reply        key.Binding
+	React        key.Binding
+	Resolve      key.Binding
 	Quit         key.Binding
 	Help         key.Binding
 }
@@ -148,6 +162,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("o"),
 			key.WithHelp("o", "open URL"),
 		),
+		OpenEditor: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "open in editor"),
+		),
+		Reply: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "reply"),
+		),
+		React: key.NewBinding(
+			key.WithKeys("+"),
+			key.WithHelp("+", "react"),
+		),
+		Resolve: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "resolve/unresolve thread"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
@@ -218,6 +248,20 @@ func buildTree(key string, value interface{}, parent *JSONNode, depth int) *JSON
 		}
 	case string:
 		node.Type = "string"
+		if key == "diff_hunk" && v != "" {
+			// Diff hunks are long and multi-line; show them as a collapsed
+			// child list of lines rather than one wrapped string.
+			node.Type = "diff"
+			node.Expanded = false
+			for _, line := range strings.Split(v, "\n") {
+				node.Children = append(node.Children, &JSONNode{
+					Value:  line,
+					Type:   "diff_line",
+					Parent: node,
+					Depth:  depth + 1,
+				})
+			}
+		}
 	case float64, int, int64:
 		node.Type = "number"
 	case bool:
@@ -278,10 +322,96 @@ func (m JSONExplorerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.viewport.Width = msg.Width
 		m.viewport.Height = msg.Height - headerHeight - footerHeight
+		if m.replyMode {
+			m.viewport.Height -= replyBoxHeight
+			m.replyInput.SetWidth(max(msg.Width-2, 20))
+		}
+		m.viewport.SetContent(m.renderTree())
+		return m, nil
+
+	case editorFinishedMsg:
+		switch {
+		case msg.err != nil:
+			m.statusMsg = "editor: " + msg.err.Error()
+		case msg.warning != "":
+			m.statusMsg = "warning: " + msg.warning
+		}
+		return m, nil
+
+	case replyPostedMsg:
+		if msg.err != nil {
+			m.statusMsg = "reply failed: " + msg.err.Error()
+		} else if err := m.addReply(msg.target, msg.reply); err != nil {
+			m.statusMsg = "reply posted; " + err.Error()
+		} else {
+			m.statusMsg = "reply posted"
+		}
+		m.viewport.SetContent(m.renderTree())
+		return m, nil
+
+	case reactionAddedMsg:
+		if msg.err != nil {
+			m.statusMsg = "reaction failed: " + msg.err.Error()
+		} else if err := m.addReaction(msg.target, msg.content); err != nil {
+			m.statusMsg = "reacted; " + err.Error()
+		} else {
+			m.statusMsg = "reacted with " + msg.content
+		}
+		m.viewport.SetContent(m.renderTree())
+		return m, nil
+
+	case threadResolvedMsg:
+		switch {
+		case msg.err != nil:
+			m.statusMsg = "resolve failed: " + msg.err.Error()
+		case msg.resolved:
+			m.statusMsg = "thread resolved"
+		default:
+			m.statusMsg = "thread unresolved"
+		}
+		if msg.err == nil {
+			if err := m.markThreadResolved(msg.threadID, msg.resolved); err != nil {
+				m.statusMsg += "; " + err.Error()
+			}
+		}
 		m.viewport.SetContent(m.renderTree())
 		return m, nil
 
 	case tea.KeyMsg:
+		// Reply box handling
+		if m.replyMode {
+			switch msg.String() {
+			case "esc":
+				m.stopReply()
+				m.statusMsg = "reply cancelled"
+				return m, nil
+			case "ctrl+s":
+				body := m.replyInput.Value()
+				if strings.TrimSpace(body) == "" {
+					m.statusMsg = "reply is empty"
+					return m, nil
+				}
+				m.stopReply()
+				m.statusMsg = "sending reply..."
+				return m, m.sendReply(body)
+			default:
+				var cmd tea.Cmd
+				m.replyInput, cmd = m.replyInput.Update(msg)
+				return m, cmd
+			}
+		}
+
+		// Reaction picker handling
+		if m.reactMode {
+			m.reactMode = false
+			if k := msg.String(); len(k) == 1 && k[0] >= '1' && int(k[0]-'1') < len(reactionLabels) {
+				m.statusMsg = "reacting..."
+				return m, m.sendReaction(int(k[0] - '1'))
+			}
+			m.statusMsg = "reaction cancelled"
+			return m, nil
+		}
+
 		// Search mode handling
 		if m.searchMode {
 			switch msg.String() {
@@ -421,6 +551,37 @@ func (m JSONExplorerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case key.Matches(msg, keyMap.OpenEditor):
+			if m.cursor < len(m.flatNodes) {
+				path, line, ok := commentLocation(m.flatNodes[m.cursor])
+				if !ok {
+					m.statusMsg = "no file location for this entry"
+					break
+				}
+				cmd, err := openInEditor(m.checkout, path, line)
+				if err != nil {
+					m.statusMsg = err.Error()
+					break
+				}
+				m.viewport.SetContent(m.renderTree())
+				return m, cmd
+			}
+
+		case key.Matches(msg, keyMap.Reply):
+			if cmd := m.startReply(); cmd != nil {
+				m.viewport.SetContent(m.renderTree())
+				return m, cmd
+			}
+
+		case key.Matches(msg, keyMap.React):
+			m.startReaction()
+
+		case key.Matches(msg, keyMap.Resolve):
+			if cmd := m.toggleResolved(); cmd != nil {
+				m.viewport.SetContent(m.renderTree())
+				return m, cmd
+			}
+
 		case key.Matches(msg, keyMap.ClearSearch):
 			m.searchQuery = ""
 			m.filterActive = false
@@ -461,10 +622,17 @@ func (m JSONExplorerModel) View() string {
 	b.WriteString("\n")
 
 	// Footer
-	if m.searchMode {
+	switch {
+	case m.searchMode:
 		b.WriteString("\n")
 		b.WriteString(m.searchInput.View())
-	} else {
+	case m.replyMode:
+		b.WriteString(m.replyInput.View())
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("ctrl+s send · esc cancel"))
+	case m.reactMode:
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Render(reactionPrompt()))
+	default:
 		statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
 
 		status := fmt.Sprintf("%d/%d", m.cursor+1, len(m.flatNodes))
@@ -477,6 +645,9 @@ func (m JSONExplorerModel) View() string {
 			}
 			status += fmt.Sprintf(" | %d matches for '%s'", matches, m.searchQuery)
 		}
+		if m.statusMsg != "" {
+			status += " | " + m.statusMsg
+		}
 
 		b.WriteString(statusStyle.Render(status))
 	}
@@ -583,10 +754,16 @@ func (m JSONExplorerModel) renderValue(node *JSONNode, selected bool, prefixWidt
 	case "object":
 		count := len(node.Children)
 		style := valueStyle.Foreground(lipgloss.Color("241"))
+		preview := fmt.Sprintf("{...} %d keys", count)
 		if node.Expanded {
-			return []string{style.Render(fmt.Sprintf("{} %d keys", count))}
+			preview = fmt.Sprintf("{} %d keys", count)
+		}
+		if isDraft(node) {
+			// Drafts live only on disk until `review submit`; make that obvious.
+			badge := valueStyle.Bold(true).Foreground(lipgloss.Color("214"))
+			return []string{style.Render(preview) + valueStyle.Render(" ") + badge.Render("DRAFT")}
 		}
-		return []string{style.Render(fmt.Sprintf("{...} %d keys", count))}
+		return []string{style.Render(preview)}
 
 	case "array":
 		count := len(node.Children)
@@ -622,6 +799,29 @@ func (m JSONExplorerModel) renderValue(node *JSONNode, selected bool, prefixWidt
 
 		return styledLines
 
+	case "diff":
+		style := valueStyle.Foreground(lipgloss.Color("241"))
+		count := len(node.Children)
+		if node.Expanded {
+			return []string{style.Render(fmt.Sprintf("diff %d lines", count))}
+		}
+		return []string{style.Render(fmt.Sprintf("diff... %d lines", count))}
+
+	case "diff_line":
+		line := fmt.Sprintf("%v", node.Value)
+		style := valueStyle
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			style = style.Foreground(lipgloss.Color("39"))
+		case strings.HasPrefix(line, "+"):
+			style = style.Foreground(lipgloss.Color("10"))
+		case strings.HasPrefix(line, "-"):
+			style = style.Foreground(lipgloss.Color("9"))
+		default:
+			style = style.Foreground(lipgloss.Color("245"))
+		}
+		return []string{style.Render(line)}
+
 	case "number":
 		style := valueStyle.Foreground(lipgloss.Color("170"))
 		return []string{style.Render(fmt.Sprintf("%v", node.Value))}
@@ -736,6 +936,145 @@ func (m *JSONExplorerModel) ensureCursorVisible() {
 	}
 }
 
+// Reload replaces the explored document while keeping expanded nodes and the
+// cursor position, so live updates do not disturb the reader. Nodes are
+// matched by nodeKey, so comments that move when others arrive keep their state.
+func (m *JSONExplorerModel) Reload(jsonData []byte) error {
+	var data interface{}
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	expanded := make(map[string]bool)
+	var record func(*JSONNode)
+	record = func(node *JSONNode) {
+		expanded[nodeKey(node)] = node.Expanded
+		for _, child := range node.Children {
+			record(child)
+		}
+	}
+	if m.tree != nil {
+		record(m.tree)
+	}
+
+	var cursorKey string
+	if m.cursor < len(m.flatNodes) {
+		cursorKey = nodeKey(m.flatNodes[m.cursor])
+	}
+
+	tree := buildTree("", data, nil, 0)
+	var restore func(*JSONNode)
+	restore = func(node *JSONNode) {
+		if state, ok := expanded[nodeKey(node)]; ok {
+			node.Expanded = state
+		}
+		for _, child := range node.Children {
+			restore(child)
+		}
+	}
+	restore(tree)
+
+	m.content = jsonData
+	m.tree = tree
+	m.flatNodes = flattenTree(tree)
+	m.cursor = min(m.cursor, len(m.flatNodes)-1)
+	for i, node := range m.flatNodes {
+		if nodeKey(node) == cursorKey {
+			m.cursor = i
+			break
+		}
+	}
+	if m.filterActive {
+		m.applySearch()
+	}
+	m.viewport.SetContent(m.renderTree())
+	return nil
+}
+
+// SetStatus shows a message in the footer status line.
+func (m *JSONExplorerModel) SetStatus(msg string) {
+	m.statusMsg = msg
+}
+
+// SetCommentActions enables replying to and reacting on comments.
+func (m *JSONExplorerModel) SetCommentActions(actions CommentActions) {
+	m.actions = actions
+}
+
+// capturingInput reports whether keys are going to a text field or prompt
+// rather than to navigation.
+func (m JSONExplorerModel) capturingInput() bool {
+	return m.searchMode || m.replyMode || m.reactMode
+}
+
+// SetCheckout records where the explored pull request is checked out, enabling
+// the open-in-editor action.
+func (m *JSONExplorerModel) SetCheckout(checkout Checkout) {
+	m.checkout = checkout
+}
+
+// nodeKey identifies a node across reloads. Comments, threads and comment
+// groups are keyed by what they are rather than where they sit, and other
+// nodes by the keys leading to them from the nearest such ancestor.
+func nodeKey(node *JSONNode) string {
+	if node == nil {
+		return ""
+	}
+	if id := nodeIdentity(node); id != "" {
+		return id
+	}
+	if node.Parent == nil {
+		return node.Key
+	}
+	if node.Type == "diff_line" {
+		return nodeKey(node.Parent) + "/#" + fmt.Sprint(indexOf(node.Parent.Children, node))
+	}
+	return nodeKey(node.Parent) + "/" + node.Key
+}
+
+// nodeIdentity returns the stable identity of a comment (type and ID, or draft
+// number), a thread (its thread ID) or a comment group (its author, path or
+// type), or "" for anything else.
+func nodeIdentity(node *JSONNode) string {
+	fields, ok := node.Value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if root, ok := fields["root"].(map[string]interface{}); ok {
+		if threadID, _ := root["thread_id"].(string); threadID != "" {
+			return "thread:" + threadID
+		}
+		return fmt.Sprintf("thread:%v", fields["id"])
+	}
+	if _, isGroup := fields["comments"].([]interface{}); isGroup && node.Parent != nil {
+		for _, name := range []string{"author", "path", "type"} {
+			if value, ok := fields[name].(string); ok {
+				return fmt.Sprintf("%s:%s=%s", node.Parent.Key, name, value)
+			}
+		}
+	}
+	kind, _ := fields["type"].(string)
+	if kind == "" {
+		return ""
+	}
+	if draftID, ok := fields["draft_id"]; ok {
+		return fmt.Sprintf("draft:%v", draftID)
+	}
+	if id, ok := fields["id"]; ok {
+		return fmt.Sprintf("%s:%v", kind, id)
+	}
+	return ""
+}
+
+func indexOf(nodes []*JSONNode, target *JSONNode) int {
+	for i, node := range nodes {
+		if node == target {
+			return i
+		}
+	}
+	return -1
+}
+
 // expandAll recursively expands all nodes.
 func expandAll(node *JSONNode) {
 	node.Expanded = true
@@ -573,3 +573,108 @@ func TestExpandCollapseAll(t *testing.T) {
 		t.Error("child2 should be collapsed")
 	}
 }
+
+func TestBuildTreeDiffHunk(t *testing.T) {
+	data := map[string]interface{}{
+		"diff_hunk": "@@ -1,2 +1,2 @@\n-old\n+new",
+		"body_text": "line one\nline two",
+	}
+
+	tree := buildTree("", data, nil, 0)
+	var hunk, body *JSONNode
+	for _, child := range tree.Children {
+		switch child.Key {
+		case "diff_hunk":
+			hunk = child
+		case "body_text":
+			body = child
+		}
+	}
+
+	if hunk == nil || hunk.Type != "diff" {
+		t.Fatalf("expected diff_hunk to become a diff node, got %+v", hunk)
+	}
+	if hunk.Expanded {
+		t.Error("expected diff node to start collapsed")
+	}
+	if len(hunk.Children) != 3 || hunk.Children[2].Value != "+new" || hunk.Children[2].Type != "diff_line" {
+		t.Fatalf("expected one child per diff line, got %+v", hunk.Children)
+	}
+	if body == nil || body.Type != "string" || len(body.Children) != 0 {
+		t.Fatalf("expected other multi-line strings to stay leaves, got %+v", body)
+	}
+
+	for _, node := range flattenTree(tree) {
+		if node.Type == "diff_line" {
+			t.Fatal("collapsed diff lines should not be flattened")
+		}
+	}
+}
+
+func TestReloadPreservesExpansionAndCursor(t *testing.T) {
+	model, err := NewJSONExplorerModel([]byte(`{"a":{"x":1},"b":{"y":2}}`))
+	if err != nil {
+		t.Fatalf("NewJSONExplorerModel failed: %v", err)
+	}
+
+	for _, child := range model.tree.Children {
+		if child.Key == "b" {
+			child.Expanded = true
+		}
+	}
+	model.flatNodes = flattenTree(model.tree)
+	for i, node := range model.flatNodes {
+		if node.Key == "y" {
+			model.cursor = i
+		}
+	}
+
+	if err := model.Reload([]byte(`{"a":{"x":1},"b":{"y":2,"z":3}}`)); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if got := model.flatNodes[model.cursor].Key; got != "y" {
+		t.Errorf("expected cursor to stay on y, got %q", got)
+	}
+	var sawZ bool
+	for _, node := range model.flatNodes {
+		if node.Key == "z" {
+			sawZ = true
+		}
+	}
+	if !sawZ {
+		t.Error("expected expanded object to show the new key after reload")
+	}
+
+	if err := model.Reload([]byte(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestReloadFollowsCommentsWhenTheyMove(t *testing.T) {
+	model, err := NewJSONExplorerModel([]byte(`{"comments":[{"author":"bob","comments":[{"type":"issue","id":2,"body_text":"two"}]}]}`))
+	if err != nil {
+		t.Fatalf("NewJSONExplorerModel failed: %v", err)
+	}
+	expandAll(model.tree)
+	model.flatNodes = flattenTree(model.tree)
+	for i, node := range model.flatNodes {
+		if node.Key == "body_text" {
+			model.cursor = i
+		}
+	}
+
+	// A new author sorts before bob, shifting every index path.
+	if err := model.Reload([]byte(`{"comments":[{"author":"alice","comments":[{"type":"issue","id":1,"body_text":"one"}]},{"author":"bob","comments":[{"type":"issue","id":2,"body_text":"two"}]}]}`)); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if got := model.flatNodes[model.cursor]; got.Value != "two" {
+		t.Errorf("expected cursor to stay on bob's comment, got %v", got.Value)
+	}
+	for _, node := range model.flatNodes {
+		if node.Value == "one" {
+			t.Error("expected alice's new group to stay collapsed")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyActions maps the action names accepted in config to explorer bindings.
+func keyActions(km *KeyMap) map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":             &km.Up,
+		"down":           &km.Down,
+		"page_up":        &km.PageUp,
+		"page_down":      &km.PageDown,
+		"half_page_up":   &km.HalfPageUp,
+		"half_page_down": &km.HalfPageDown,
+		"goto_top":       &km.GotoTop,
+		"goto_bottom":    &km.GotoBottom,
+		"expand":         &km.Expand,
+		"collapse":       &km.Collapse,
+		"expand_all":     &km.ExpandAll,
+		"collapse_all":   &km.CollapseAll,
+		"search":         &km.Search,
+		"next_match":     &km.NextMatch,
+		"prev_match":     &km.PrevMatch,
+		"clear_search":   &km.ClearSearch,
+		"copy":           &km.Copy,
+		"open_url":       &km.OpenURL,
+		"open_editor":    &km.OpenEditor,
+		"reply":          &km.Reply,
+		"react":          &km.React,
+		"resolve":        &km.Resolve,
+		"quit":           &km.Quit,
+		"help":           &km.Help,
+	}
+}
+
+// KeyActions lists the action names accepted by ApplyKeyBindings.
+func KeyActions() []string {
+	actions := keyActions(&KeyMap{})
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyKeyBindings rebinds explorer actions. Each value is a comma-separated
+// list of keys, e.g. {"open_url": "o,x"}; unmentioned actions keep their defaults.
+func ApplyKeyBindings(bindings map[string]string) error {
+	km := DefaultKeyMap()
+	actions := keyActions(&km)
+	for action, value := range bindings {
+		binding, ok := actions[action]
+		if !ok {
+			return fmt.Errorf("unknown key action %q (want %s)", action, strings.Join(KeyActions(), ", "))
+		}
+		var keys []string
+		for _, k := range strings.Split(value, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("key action %q has no keys", action)
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+	}
+	keyMap = km
+	return nil
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestApplyKeyBindings(t *testing.T) {
+	t.Cleanup(func() { keyMap = DefaultKeyMap() })
+
+	if err := ApplyKeyBindings(map[string]string{"open_url": "x, ctrl+o"}); err != nil {
+		t.Fatalf("ApplyKeyBindings: %v", err)
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, keyMap.OpenURL) {
+		t.Fatal("expected x to open URLs")
+	}
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")}, keyMap.OpenURL) {
+		t.Fatal("expected the default o binding to be replaced")
+	}
+	if got := keyMap.OpenURL.Help().Key; got != "x/ctrl+o" {
+		t.Fatalf("help key = %q", got)
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, keyMap.Down) {
+		t.Fatal("unmentioned actions should keep their defaults")
+	}
+
+	if err := ApplyKeyBindings(map[string]string{"launch": "l"}); err == nil {
+		t.Fatal("expected unknown action to fail")
+	}
+	if err := ApplyKeyBindings(map[string]string{"quit": " , "}); err == nil {
+		t.Fatal("expected empty key list to fail")
+	}
+}
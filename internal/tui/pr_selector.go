@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -20,10 +21,12 @@ type PullRequestSummary struct {
 	Created      time.Time
 	Updated      time.Time
 	HeadRef      string
+	HeadSHA      string
 	BaseRef      string
 	RepoName     string
 	RepoOwner    string
 	URL          string
+	Labels       []string
 	LocalPath    string
 	CommentsJSON []byte // Prefetched JSON comments data
 }
@@ -41,7 +44,13 @@ type prItem struct {
 }
 
 func (i prItem) FilterValue() string {
-	return fmt.Sprintf("%s #%d %s", i.pr.RepoName, i.pr.Number, i.pr.Title)
+	parts := []string{
+		fmt.Sprintf("%s #%d %s", i.pr.RepoName, i.pr.Number, i.pr.Title),
+		"@" + i.pr.Author,
+		i.pr.State,
+	}
+	parts = append(parts, i.pr.Labels...)
+	return strings.Join(parts, " ")
 }
 
 func (i prItem) Title() string {
@@ -51,13 +60,18 @@ func (i prItem) Title() string {
 func (i prItem) Description() string {
 	arrow := "\u2192"
 	updated := formatTimestamp(i.pr.Updated)
-	return fmt.Sprintf("[%s%s%s] %s by @%s",
-		i.pr.HeadRef,
-		arrow,
-		i.pr.BaseRef,
-		updated,
-		i.pr.Author,
-	)
+	desc := fmt.Sprintf("%s by @%s", updated, i.pr.Author)
+	// Search results carry no branch names.
+	if i.pr.HeadRef != "" || i.pr.BaseRef != "" {
+		desc = fmt.Sprintf("[%s%s%s] %s", i.pr.HeadRef, arrow, i.pr.BaseRef, desc)
+	}
+	if state := strings.ToLower(i.pr.State); state != "" && state != "open" {
+		desc += " (" + state + ")"
+	}
+	if len(i.pr.Labels) > 0 {
+		desc += " [" + strings.Join(i.pr.Labels, ", ") + "]"
+	}
+	return desc
 }
 
 func formatTimestamp(t time.Time) string {
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPRItemIncludesFilterMetadata(t *testing.T) {
+	item := prItem{pr: PullRequestSummary{
+		Number:   12,
+		Title:    "Tidy docs",
+		Author:   "alice",
+		State:    "merged",
+		RepoName: "repo",
+		Labels:   []string{"docs", "chore"},
+	}}
+
+	filterValue := item.FilterValue()
+	for _, want := range []string{"#12", "Tidy docs", "@alice", "merged", "docs", "chore"} {
+		if !strings.Contains(filterValue, want) {
+			t.Errorf("filter value %q missing %q", filterValue, want)
+		}
+	}
+
+	desc := item.Description()
+	if strings.Contains(desc, "[→]") {
+		t.Errorf("expected empty branches to be omitted, got %q", desc)
+	}
+	if !strings.Contains(desc, "(merged)") || !strings.Contains(desc, "[docs, chore]") {
+		t.Errorf("expected state and labels in description, got %q", desc)
+	}
+
+	item.pr.HeadRef, item.pr.BaseRef, item.pr.State = "feature", "main", "open"
+	if desc := item.Description(); !strings.HasPrefix(desc, "[feature→main] ") || strings.Contains(desc, "(open)") {
+		t.Errorf("unexpected open PR description %q", desc)
+	}
+}
@@ -0,0 +1,354 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CommentActions posts replies and reactions and resolves threads for the
+// explorer. *ghprcomments.Responder implements it.
+type CommentActions interface {
+	Reply(ctx context.Context, to ghprcomments.Comment, body string) (ghprcomments.Comment, error)
+	React(ctx context.Context, to ghprcomments.Comment, content string) error
+	SetResolved(ctx context.Context, to ghprcomments.Comment, resolved bool) error
+}
+
+// replyBoxHeight is the number of lines in the reply text area; its hint line
+// takes the place of the status line.
+const replyBoxHeight = 3
+
+// reactionLabels shows each reaction in ghprcomments.ReactionContents order.
+var reactionLabels = []string{"👍", "👎", "😄", "😕", "❤️", "🎉", "🚀", "👀"}
+
+// replyPostedMsg reports the outcome of posting a reply to the comment
+// identified by target (see nodeIdentity).
+type replyPostedMsg struct {
+	target string
+	reply  ghprcomments.Comment
+	err    error
+}
+
+// reactionAddedMsg reports the outcome of reacting to the comment identified
+// by target.
+type reactionAddedMsg struct {
+	target  string
+	content string
+	err     error
+}
+
+// threadResolvedMsg reports the outcome of resolving or unresolving a review thread.
+type threadResolvedMsg struct {
+	threadID string
+	resolved bool
+	err      error
+}
+
+// commentNode finds the comment object enclosing node.
+func commentNode(node *JSONNode) (*JSONNode, ghprcomments.Comment, bool) {
+	for ; node != nil; node = node.Parent {
+		fields, ok := node.Value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _ := fields["type"].(string)
+		if _, hasBody := fields["body_text"]; kind == "" || !hasBody {
+			continue
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, ghprcomments.Comment{}, false
+		}
+		var comment ghprcomments.Comment
+		if err := json.Unmarshal(data, &comment); err != nil {
+			return nil, ghprcomments.Comment{}, false
+		}
+		return node, comment, true
+	}
+	return nil, ghprcomments.Comment{}, false
+}
+
+func newReplyInput(author string) textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Reply"
+	if author != "" {
+		ta.Placeholder = "Reply to @" + author
+	}
+	ta.ShowLineNumbers = false
+	ta.SetHeight(replyBoxHeight)
+	ta.CharLimit = 0
+	return ta
+}
+
+// startReply opens the reply box for the comment under the cursor.
+func (m *JSONExplorerModel) startReply() tea.Cmd {
+	node, comment, ok := m.selectedComment()
+	if !ok {
+		return nil
+	}
+	m.replyMode = true
+	m.actionTarget = nodeIdentity(node)
+	m.replyInput = newReplyInput(comment.Author)
+	m.replyInput.SetWidth(max(m.width-2, 20))
+	m.viewport.Height = max(m.viewport.Height-replyBoxHeight, 1)
+	return m.replyInput.Focus()
+}
+
+func (m *JSONExplorerModel) stopReply() {
+	m.replyMode = false
+	m.replyInput.Blur()
+	m.viewport.Height += replyBoxHeight
+}
+
+// startReaction waits for a digit choosing the reaction for the comment under the cursor.
+func (m *JSONExplorerModel) startReaction() {
+	node, _, ok := m.selectedComment()
+	if !ok {
+		return
+	}
+	m.reactMode = true
+	m.actionTarget = nodeIdentity(node)
+}
+
+func (m *JSONExplorerModel) selectedComment() (*JSONNode, ghprcomments.Comment, bool) {
+	if m.actions == nil {
+		m.statusMsg = "replies and reactions need a single pull request"
+		return nil, ghprcomments.Comment{}, false
+	}
+	if m.cursor >= len(m.flatNodes) {
+		return nil, ghprcomments.Comment{}, false
+	}
+	node, comment, ok := commentNode(m.flatNodes[m.cursor])
+	if !ok || ghprcomments.IsTimelineEvent(comment.Type) {
+		m.statusMsg = "select a comment first"
+		return nil, ghprcomments.Comment{}, false
+	}
+	if comment.Draft {
+		m.statusMsg = "drafts are posted with `review submit`"
+		return nil, ghprcomments.Comment{}, false
+	}
+	return node, comment, true
+}
+
+// isDraft reports whether node is a comment drafted locally and not yet submitted.
+func isDraft(node *JSONNode) bool {
+	fields, ok := node.Value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	draft, _ := fields["draft"].(bool)
+	return draft
+}
+
+func reactionPrompt() string {
+	parts := make([]string, len(reactionLabels))
+	for i, label := range reactionLabels {
+		parts[i] = fmt.Sprintf("%d %s", i+1, label)
+	}
+	return "react: " + strings.Join(parts, "  ") + "  (esc cancels)"
+}
+
+// sendReply posts body in the background.
+func (m JSONExplorerModel) sendReply(body string) tea.Cmd {
+	node := findComment(m.tree, m.actionTarget)
+	_, comment, ok := commentNode(node)
+	if !ok {
+		return nil
+	}
+	actions, target := m.actions, m.actionTarget
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		reply, err := actions.Reply(ctx, comment, body)
+		return replyPostedMsg{target: target, reply: reply, err: err}
+	}
+}
+
+// sendReaction adds the reaction at index choice (0-based) in the background.
+func (m JSONExplorerModel) sendReaction(choice int) tea.Cmd {
+	node := findComment(m.tree, m.actionTarget)
+	_, comment, ok := commentNode(node)
+	if !ok || choice < 0 || choice >= len(ghprcomments.ReactionContents) {
+		return nil
+	}
+	actions, target, content := m.actions, m.actionTarget, ghprcomments.ReactionContents[choice]
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return reactionAddedMsg{target: target, content: content, err: actions.React(ctx, comment, content)}
+	}
+}
+
+// toggleResolved resolves the review thread under the cursor, or reopens it
+// when it is already resolved.
+func (m *JSONExplorerModel) toggleResolved() tea.Cmd {
+	_, comment, ok := m.selectedComment()
+	if !ok {
+		return nil
+	}
+	if comment.Type != "review_comment" || comment.ThreadID == "" {
+		m.statusMsg = "only review threads can be resolved"
+		return nil
+	}
+	resolved := comment.Resolved == nil || !*comment.Resolved
+	if resolved {
+		m.statusMsg = "resolving thread..."
+	} else {
+		m.statusMsg = "unresolving thread..."
+	}
+	actions := m.actions
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		err := actions.SetResolved(ctx, comment, resolved)
+		return threadResolvedMsg{threadID: comment.ThreadID, resolved: resolved, err: err}
+	}
+}
+
+// markThreadResolved updates the resolved flag on every comment and thread
+// belonging to threadID.
+func (m *JSONExplorerModel) markThreadResolved(threadID string, resolved bool) error {
+	var mark func(value interface{})
+	mark = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if v["thread_id"] == threadID {
+				v["resolved"] = resolved
+			}
+			if root, ok := v["root"].(map[string]interface{}); ok && root["thread_id"] == threadID {
+				v["resolved"] = resolved
+			}
+			for _, child := range v {
+				mark(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				mark(child)
+			}
+		}
+	}
+	mark(m.tree.Value)
+
+	data, err := json.Marshal(m.tree.Value)
+	if err != nil {
+		return err
+	}
+	return m.Reload(data)
+}
+
+// addReply shows a posted reply next to the comment identified by target.
+// Thread roots and replies get it appended to the thread's replies; other
+// comments gain a replies list of their own.
+func (m *JSONExplorerModel) addReply(target string, reply ghprcomments.Comment) error {
+	node := findComment(m.tree, target)
+	if node == nil {
+		return errors.New("comment is no longer shown")
+	}
+	value, err := toJSONValue(reply)
+	if err != nil {
+		return err
+	}
+
+	holder := node
+	switch parent := node.Parent; {
+	case parent != nil && node.Key == "root" && hasField(parent, "root"):
+		holder = parent
+	case parent != nil && parent.Key == "replies" && parent.Parent != nil && hasField(parent.Parent, "root"):
+		holder = parent.Parent
+	}
+	fields := holder.Value.(map[string]interface{})
+	replies, _ := fields["replies"].([]interface{})
+	fields["replies"] = append(replies, value)
+	m.rebuildNode(holder)
+	return nil
+}
+
+// addReaction bumps the reaction count on the comment identified by target.
+func (m *JSONExplorerModel) addReaction(target, content string) error {
+	node := findComment(m.tree, target)
+	if node == nil {
+		return errors.New("comment is no longer shown")
+	}
+	fields := node.Value.(map[string]interface{})
+	reactions, _ := fields["reactions"].(map[string]interface{})
+	if reactions == nil {
+		reactions = make(map[string]interface{})
+		fields["reactions"] = reactions
+	}
+	count, _ := reactions[content].(float64)
+	reactions[content] = count + 1
+	m.rebuildNode(node)
+	return nil
+}
+
+// rebuildNode regenerates node's subtree from its (mutated) value, keeping the
+// cursor on the same node.
+func (m *JSONExplorerModel) rebuildNode(node *JSONNode) {
+	var cursorKey string
+	if m.cursor < len(m.flatNodes) {
+		cursorKey = nodeKey(m.flatNodes[m.cursor])
+	}
+
+	fresh := buildTree(node.Key, node.Value, node.Parent, node.Depth)
+	fresh.Expanded = true
+	if node.Parent == nil {
+		m.tree = fresh
+	} else {
+		node.Parent.Children[indexOf(node.Parent.Children, node)] = fresh
+	}
+
+	m.flatNodes = flattenTree(m.tree)
+	for i, n := range m.flatNodes {
+		if nodeKey(n) == cursorKey {
+			m.cursor = i
+			break
+		}
+	}
+	m.cursor = min(m.cursor, len(m.flatNodes)-1)
+	if m.filterActive {
+		m.applySearch()
+	}
+}
+
+// findComment finds the comment whose nodeIdentity is target, wherever the
+// latest reload has put it.
+func findComment(node *JSONNode, target string) *JSONNode {
+	if node == nil || target == "" {
+		return nil
+	}
+	if nodeIdentity(node) == target {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findComment(child, target); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func hasField(node *JSONNode, name string) bool {
+	fields, ok := node.Value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = fields[name]
+	return ok
+}
+
+func toJSONValue(v any) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
@@ -0,0 +1,236 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type fakeActions struct {
+	replies   []string
+	reactions []string
+	resolved  []string
+}
+
+func (f *fakeActions) Reply(_ context.Context, to ghprcomments.Comment, body string) (ghprcomments.Comment, error) {
+	f.replies = append(f.replies, to.Type+" "+body)
+	return ghprcomments.Comment{Type: to.Type, ID: 99, Author: "me", BodyText: body}, nil
+}
+
+func (f *fakeActions) React(_ context.Context, to ghprcomments.Comment, content string) error {
+	f.reactions = append(f.reactions, content)
+	return nil
+}
+
+func (f *fakeActions) SetResolved(_ context.Context, to ghprcomments.Comment, resolved bool) error {
+	f.resolved = append(f.resolved, fmt.Sprintf("%s=%t", to.ThreadID, resolved))
+	return nil
+}
+
+const threadsJSON = `{"threads": [{"id": 10, "comment_count": 1, "root": {"type": "review_comment", "id": 10, "author": "alice", "body_text": "rename this", "body_markdown": "rename this"}}]}`
+
+// nodePath identifies a node by the keys leading to it from the root.
+func nodePath(node *JSONNode) string {
+	if node == nil {
+		return ""
+	}
+	if node.Parent == nil {
+		return node.Key
+	}
+	if node.Type == "diff_line" {
+		return nodePath(node.Parent) + "/#" + fmt.Sprint(indexOf(node.Parent.Children, node))
+	}
+	return nodePath(node.Parent) + "/" + node.Key
+}
+
+func findNode(node *JSONNode, path string) *JSONNode {
+	if node == nil {
+		return nil
+	}
+	if nodePath(node) == path {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findNode(child, path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func explorerAt(t *testing.T, jsonData, path string) JSONExplorerModel {
+	t.Helper()
+	m, err := NewJSONExplorerModel([]byte(jsonData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expandAll(m.tree)
+	m.flatNodes = flattenTree(m.tree)
+	for i, node := range m.flatNodes {
+		if nodePath(node) == path {
+			m.cursor = i
+			return m
+		}
+	}
+	t.Fatalf("no node at %q", path)
+	return m
+}
+
+func press(t *testing.T, m JSONExplorerModel, keys ...string) (JSONExplorerModel, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "ctrl+s":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlS}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		updated, c := m.Update(msg)
+		m, cmd = updated.(JSONExplorerModel), c
+	}
+	return m, cmd
+}
+
+func TestExplorerReplyAppendsToThread(t *testing.T) {
+	actions := &fakeActions{}
+	m := explorerAt(t, threadsJSON, "/threads/[0]/root/body_text")
+	m.SetCommentActions(actions)
+
+	m, _ = press(t, m, "r")
+	if !m.replyMode || !m.capturingInput() {
+		t.Fatal("expected the reply box to open")
+	}
+	m, _ = press(t, m, "q", "u", "i", "t", "e")
+	m, cmd := press(t, m, "ctrl+s")
+	if m.replyMode || cmd == nil {
+		t.Fatal("expected ctrl+s to close the box and send the reply")
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(JSONExplorerModel)
+
+	if len(actions.replies) != 1 || actions.replies[0] != "review_comment quite" {
+		t.Fatalf("unexpected replies %q", actions.replies)
+	}
+	reply := findNode(m.tree, "/threads/[0]/replies/[0]/body_text")
+	if reply == nil || reply.Value != "quite" {
+		t.Fatalf("expected the reply under the thread, got %+v", reply)
+	}
+	if m.statusMsg != "reply posted" {
+		t.Fatalf("status = %q", m.statusMsg)
+	}
+}
+
+func TestExplorerReplyFollowsCommentAcrossReload(t *testing.T) {
+	const before = `{"comments": [{"author": "bob", "comments": [{"type": "issue", "id": 2, "author": "bob", "body_text": "two", "body_markdown": "two"}]}]}`
+	const after = `{"comments": [{"author": "alice", "comments": [{"type": "issue", "id": 1, "author": "alice", "body_text": "one", "body_markdown": "one"}]}, {"author": "bob", "comments": [{"type": "issue", "id": 2, "author": "bob", "body_text": "two", "body_markdown": "two"}]}]}`
+	actions := &fakeActions{}
+	m := explorerAt(t, before, "/comments/[0]/comments/[0]/body_text")
+	m.SetCommentActions(actions)
+
+	m, _ = press(t, m, "r", "o", "k")
+	m, cmd := press(t, m, "ctrl+s")
+	// A watch poll lands while the reply is in flight and moves bob's comment.
+	if err := m.Reload([]byte(after)); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(JSONExplorerModel)
+
+	if m.statusMsg != "reply posted" {
+		t.Fatalf("status = %q", m.statusMsg)
+	}
+	if reply := findNode(m.tree, "/comments/[1]/comments/[0]/replies/[0]/body_text"); reply == nil || reply.Value != "ok" {
+		t.Fatalf("expected the reply under bob's comment, got %+v", reply)
+	}
+	if stray := findNode(m.tree, "/comments/[0]/comments/[0]/replies"); stray != nil {
+		t.Fatal("reply was attached to alice's comment")
+	}
+}
+
+func TestExplorerReaction(t *testing.T) {
+	actions := &fakeActions{}
+	m := explorerAt(t, threadsJSON, "/threads/[0]/root")
+	m.SetCommentActions(actions)
+
+	m, _ = press(t, m, "+")
+	if !m.reactMode || !strings.Contains(m.View(), "1 👍") {
+		t.Fatal("expected the reaction picker")
+	}
+	m, cmd := press(t, m, "7")
+	updated, _ := m.Update(cmd())
+	m = updated.(JSONExplorerModel)
+
+	if len(actions.reactions) != 1 || actions.reactions[0] != "rocket" {
+		t.Fatalf("unexpected reactions %q", actions.reactions)
+	}
+	count := findNode(m.tree, "/threads/[0]/root/reactions/rocket")
+	if count == nil || count.Value != float64(1) {
+		t.Fatalf("expected rocket count 1, got %+v", count)
+	}
+}
+
+func TestExplorerRepliesNeedActions(t *testing.T) {
+	m := explorerAt(t, threadsJSON, "/threads/[0]/root")
+	m, _ = press(t, m, "r")
+	if m.replyMode || !strings.Contains(m.statusMsg, "single pull request") {
+		t.Fatalf("expected replies to be unavailable, status %q", m.statusMsg)
+	}
+
+	m = explorerAt(t, threadsJSON, "/threads/[0]/id")
+	m.SetCommentActions(&fakeActions{})
+	m, _ = press(t, m, "r")
+	if m.replyMode {
+		t.Fatal("thread metadata is not a comment")
+	}
+}
+
+func TestExplorerResolveThread(t *testing.T) {
+	const data = `{"threads": [{"id": 10, "resolved": false, "root": {"type": "review_comment", "id": 10, "thread_id": "T1", "resolved": false, "body_text": "a"}, "replies": [{"type": "review_comment", "id": 11, "thread_id": "T1", "resolved": false, "body_text": "b"}]}]}`
+	actions := &fakeActions{}
+	m := explorerAt(t, data, "/threads/[0]/replies/[0]/body_text")
+	m.SetCommentActions(actions)
+
+	m, cmd := press(t, m, "R")
+	if cmd == nil {
+		t.Fatalf("expected a resolve command, status %q", m.statusMsg)
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(JSONExplorerModel)
+
+	if len(actions.resolved) != 1 || actions.resolved[0] != "T1=true" {
+		t.Fatalf("unexpected calls %q", actions.resolved)
+	}
+	for _, path := range []string{"/threads/[0]/resolved", "/threads/[0]/root/resolved", "/threads/[0]/replies/[0]/resolved"} {
+		if node := findNode(m.tree, path); node == nil || node.Value != true {
+			t.Fatalf("%s not marked resolved: %+v", path, node)
+		}
+	}
+	if nodePath(m.flatNodes[m.cursor]) != "/threads/[0]/replies/[0]/body_text" {
+		t.Fatal("cursor should stay on the selected comment")
+	}
+
+	m = explorerAt(t, threadsJSON, "/threads/[0]/root")
+	m.SetCommentActions(actions)
+	if m, cmd = press(t, m, "R"); cmd != nil || m.statusMsg != "only review threads can be resolved" {
+		t.Fatalf("comments without a thread ID cannot be resolved, status %q", m.statusMsg)
+	}
+}
+
+func TestExplorerMarksDrafts(t *testing.T) {
+	const data = `{"comments": [{"type": "draft", "author": "(draft)", "draft": true, "draft_id": 1, "path": "a.go", "body_text": "tidy"}]}`
+	m := explorerAt(t, data, "/comments/[0]")
+	m.SetCommentActions(&fakeActions{})
+
+	if !strings.Contains(m.renderTree(), "DRAFT") {
+		t.Fatal("expected drafts to carry a DRAFT badge")
+	}
+	if m, _ = press(t, m, "r"); m.replyMode || !strings.Contains(m.statusMsg, "review submit") {
+		t.Fatalf("drafts cannot be replied to yet, status %q", m.statusMsg)
+	}
+}
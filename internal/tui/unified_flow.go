@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -45,6 +47,49 @@ type UnifiedFlowModel struct {
 	prefetchCtx    context.Context
 	prefetchCancel context.CancelFunc
 	prefetchConfig *PrefetchConfig // Stored config for starting prefetch in Init()
+
+	// Posts replies and reactions on the selected PR (nil when not prefetching)
+	fetcher *ghprcomments.Fetcher
+
+	// Live updates for the JSON explorer (nil when not watching)
+	watch *WatchConfig
+	// Consecutive failed polls, stretching the wait before the next one
+	watchFailures int
+
+	// Quota reported in the explorer status line (nil when not tracked)
+	rateLimits *ghprcomments.RateLimitTracker
+}
+
+// WatchConfig drives live updates of the JSON explorer while a PR is watched.
+type WatchConfig struct {
+	Ctx      context.Context
+	Interval time.Duration
+	// Poll returns the latest JSON document and a short summary of what changed.
+	// An empty summary means nothing changed and the view is left untouched.
+	Poll func(ctx context.Context) (jsonData []byte, summary string, err error)
+	// RateLimits, when set, appends the remaining quota to each status update.
+	RateLimits *ghprcomments.RateLimitTracker
+	// Checkout, when set, lets comments be opened in the local clone.
+	Checkout Checkout
+	// Actions, when set, enables replies and reactions.
+	Actions CommentActions
+}
+
+// watchTickMsg triggers the next poll.
+type watchTickMsg struct{}
+
+// watchResultMsg carries the outcome of a poll.
+type watchResultMsg struct {
+	jsonData []byte
+	summary  string
+	err      error
+}
+
+// branchesFilledMsg carries the head and base loaded for pr.
+type branchesFilledMsg struct {
+	pr       *PullRequestSummary
+	branches ghprcomments.PullRequestSummary
+	err      error
 }
 
 // prefetchCompleteMsg is sent when all PRs have been prefetched.
@@ -80,8 +125,16 @@ type PrefetchConfig struct {
 	Fetcher            *ghprcomments.Fetcher
 	Repositories       []ghprcomments.Repository
 	RepositoriesLoader func(context.Context) ([]ghprcomments.Repository, error)
-	StripHTML          bool
-	Flat               bool
+	Filter             ghprcomments.PullRequestFilter
+	// Normalization is applied to every prefetched pull request.
+	Normalization ghprcomments.NormalizationOptions
+	Flat          bool
+	// RateLimits, when set, throttles prefetch concurrency as the quota runs low
+	// and feeds the explorer status line.
+	RateLimits *ghprcomments.RateLimitTracker
+	// Drafts, when set, returns the locally drafted comments to show with each
+	// pull request.
+	Drafts func(*ghprcomments.PullRequestSummary) []ghprcomments.DraftComment
 }
 
 // NewUnifiedFlowWithPrefetch creates a new unified flow that prefetches PR comments.
@@ -115,6 +168,8 @@ func NewUnifiedFlowWithPrefetch(config PrefetchConfig) UnifiedFlowModel {
 		prefetchCtx:    prefetchCtx,
 		prefetchCancel: prefetchCancel,
 		prefetchConfig: &configCopy,
+		fetcher:        config.Fetcher,
+		rateLimits:     config.RateLimits,
 	}
 
 	return m
@@ -142,7 +197,7 @@ func startPrefetchCmd(config PrefetchConfig) tea.Cmd {
 			all := make([]*ghprcomments.PullRequestSummary, 0)
 			var fatalErr error
 			for _, repo := range repos {
-				repoPRs, err := config.Fetcher.ListPullRequestSummaries(config.Ctx, repo.Owner, repo.Name)
+				repoPRs, err := config.Fetcher.ListPullRequestSummariesWithFilter(config.Ctx, repo.Owner, repo.Name, config.Filter)
 				if err != nil {
 					if errors.Is(err, ghprcomments.ErrNoPullRequests) {
 						// Ignore repos with no PRs
@@ -203,18 +258,18 @@ func startPrefetchCmd(config PrefetchConfig) tea.Cmd {
 			workerLimit = 1
 		}
 
-		sem := make(chan struct{}, workerLimit)
+		limiter := newAdaptiveLimiter(func() int {
+			return config.RateLimits.Concurrency(workerLimit)
+		})
 		prefetchGroup, groupCtx := errgroup.WithContext(config.Ctx)
 
 		for i, pr := range prs {
 			i, pr := i, pr
 			prefetchGroup.Go(func() error {
-				select {
-				case sem <- struct{}{}:
-				case <-groupCtx.Done():
-					return groupCtx.Err()
+				if err := limiter.acquire(groupCtx); err != nil {
+					return err
 				}
-				defer func() { <-sem }()
+				defer limiter.release()
 
 				owner := strings.TrimSpace(pr.RepoOwner)
 				repo := strings.TrimSpace(pr.RepoName)
@@ -225,11 +280,10 @@ func startPrefetchCmd(config PrefetchConfig) tea.Cmd {
 					return nil
 				}
 
-				normOpts := ghprcomments.NormalizationOptions{
-					StripHTML: config.StripHTML,
+				output := ghprcomments.BuildOutput(pr, payloads, config.Normalization)
+				if config.Drafts != nil {
+					output = ghprcomments.WithDrafts(output, config.Drafts(pr))
 				}
-
-				output := ghprcomments.BuildOutput(pr, payloads, normOpts)
 				jsonData, err := ghprcomments.MarshalJSON(output, config.Flat)
 				if err != nil {
 					results[i].warn = fmt.Errorf("failed to marshal JSON for %s/%s#%d: %w", owner, repo, pr.Number, err)
@@ -244,10 +298,12 @@ func startPrefetchCmd(config PrefetchConfig) tea.Cmd {
 					Created:      pr.Created,
 					Updated:      pr.Updated,
 					HeadRef:      pr.HeadRef,
+					HeadSHA:      pr.HeadSHA,
 					BaseRef:      pr.BaseRef,
 					RepoName:     pr.RepoName,
 					RepoOwner:    pr.RepoOwner,
 					URL:          pr.URL,
+					Labels:       pr.Labels,
 					LocalPath:    pr.LocalPath,
 					CommentsJSON: jsonData,
 				}
@@ -278,6 +334,60 @@ func startPrefetchCmd(config PrefetchConfig) tea.Cmd {
 	}
 }
 
+// adaptiveLimiter bounds in-flight work, re-reading the allowed concurrency
+// each time a slot is requested so the pool shrinks as the quota drains.
+type adaptiveLimiter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	active int
+	limit  func() int
+}
+
+func newAdaptiveLimiter(limit func() int) *adaptiveLimiter {
+	l := &adaptiveLimiter{limit: limit}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	})
+	defer stop()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.active >= max(l.limit(), 1) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		l.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.active++
+	return nil
+}
+
+func (l *adaptiveLimiter) release() {
+	l.mu.Lock()
+	l.active--
+	l.cond.Broadcast()
+	l.mu.Unlock()
+}
+
+// quotaStatus describes the remaining API quota, or "" when it is not tracked.
+func quotaStatus(tracker *ghprcomments.RateLimitTracker) string {
+	status := tracker.Status()
+	if !status.Known {
+		return ""
+	}
+	return status.String()
+}
+
 func (m UnifiedFlowModel) quitCmd() tea.Cmd {
 	if m.altScreenActive {
 		return tea.Batch(tea.ExitAltScreen, tea.Quit)
@@ -306,6 +416,57 @@ func NewUnifiedFlowWithJSON(jsonData []byte) (UnifiedFlowModel, error) {
 	}, nil
 }
 
+// NewUnifiedFlowWithWatch creates a JSON-only flow that refreshes itself by polling.
+func NewUnifiedFlowWithWatch(jsonData []byte, watch WatchConfig) (UnifiedFlowModel, error) {
+	m, err := NewUnifiedFlowWithJSON(jsonData)
+	if err != nil {
+		return m, err
+	}
+	m.watch = &watch
+	m.jsonExplorer.SetCheckout(watch.Checkout)
+	m.jsonExplorer.SetCommentActions(watch.Actions)
+	return m, nil
+}
+
+// watchTickCmd schedules the next poll, backing off while polls keep failing.
+func (m UnifiedFlowModel) watchTickCmd() tea.Cmd {
+	wait := m.watch.Interval
+	if m.watchFailures > 0 {
+		wait = ghprcomments.WatchBackoff(wait, m.watchFailures)
+	}
+	return tea.Tick(wait, func(time.Time) tea.Msg { return watchTickMsg{} })
+}
+
+func (m UnifiedFlowModel) watchPollCmd() tea.Cmd {
+	watch := m.watch
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(watch.Ctx, 60*time.Second)
+		defer cancel()
+		data, summary, err := watch.Poll(ctx)
+		return watchResultMsg{jsonData: data, summary: summary, err: err}
+	}
+}
+
+// fillBranchesCmd loads the head commit of pr in the background when the
+// listing did not include it, as search results do not, so opening comments
+// in the editor can check the local checkout against it.
+func (m UnifiedFlowModel) fillBranchesCmd(pr *PullRequestSummary) tea.Cmd {
+	if m.fetcher == nil || pr.HeadSHA != "" {
+		return nil
+	}
+	fetcher, parent := m.fetcher, m.prefetchCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+		defer cancel()
+		summary := &ghprcomments.PullRequestSummary{RepoOwner: pr.RepoOwner, RepoName: pr.RepoName, Number: pr.Number}
+		err := fetcher.FillBranches(ctx, summary)
+		return branchesFilledMsg{pr: pr, branches: *summary, err: err}
+	}
+}
+
 // Init implements tea.Model.
 func (m UnifiedFlowModel) Init() tea.Cmd {
 	switch m.state {
@@ -318,6 +479,9 @@ func (m UnifiedFlowModel) Init() tea.Cmd {
 		}
 		return m.spinner.Tick
 	case StateExploringJSON:
+		if m.watch != nil {
+			return tea.Batch(m.jsonExplorer.Init(), m.watchTickCmd())
+		}
 		return m.jsonExplorer.Init()
 	default:
 		return nil
@@ -368,11 +532,16 @@ func (m UnifiedFlowModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				m.jsonExplorer = explorer
+				m.jsonExplorer.SetStatus(quotaStatus(m.rateLimits))
+				m.jsonExplorer.SetCheckout(Checkout{Dir: m.selectedPR.LocalPath, HeadSHA: m.selectedPR.HeadSHA})
+				if m.fetcher != nil {
+					m.jsonExplorer.SetCommentActions(ghprcomments.NewResponder(m.fetcher, m.selectedPR.RepoOwner, m.selectedPR.RepoName, m.selectedPR.Number))
+				}
 				m.jsonData = m.selectedPR.CommentsJSON
 				m.state = StateExploringJSON
 
 				cmd := m.syncJSONExplorerSize()
-				return m, cmd
+				return m, tea.Batch(cmd, m.fillBranchesCmd(m.selectedPR))
 			}
 			// Cancelled - quit
 			m.state = StateQuitting
@@ -423,10 +592,57 @@ func (m UnifiedFlowModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case StateExploringJSON:
+		// Handle live updates when watching
+		if m.watch != nil {
+			switch msg := msg.(type) {
+			case watchTickMsg:
+				return m, m.watchPollCmd()
+			case watchResultMsg:
+				stamp := time.Now().Format("15:04:05")
+				status := ""
+				if msg.err == nil {
+					m.watchFailures = 0
+				}
+				switch {
+				case msg.err != nil:
+					m.watchFailures++
+					status = fmt.Sprintf("watch error at %s: %v (retrying in %s)", stamp, msg.err,
+						ghprcomments.WatchBackoff(m.watch.Interval, m.watchFailures))
+				case msg.summary != "":
+					if err := m.jsonExplorer.Reload(msg.jsonData); err != nil {
+						status = fmt.Sprintf("watch error at %s: %v", stamp, err)
+					} else {
+						status = fmt.Sprintf("%s at %s", msg.summary, stamp)
+					}
+				}
+				if status != "" {
+					if quota := quotaStatus(m.watch.RateLimits); quota != "" {
+						status += " · " + quota
+					}
+					m.jsonExplorer.SetStatus(status)
+				}
+				return m, m.watchTickCmd()
+			}
+		}
+
+		if msg, ok := msg.(branchesFilledMsg); ok {
+			switch {
+			case msg.pr != m.selectedPR:
+				// The reader has moved on to another pull request.
+			case msg.err != nil:
+				m.jsonExplorer.SetStatus("open in editor unavailable: " + msg.err.Error())
+			default:
+				pr := m.selectedPR
+				pr.HeadRef, pr.HeadSHA, pr.BaseRef = msg.branches.HeadRef, msg.branches.HeadSHA, msg.branches.BaseRef
+				m.jsonExplorer.SetCheckout(Checkout{Dir: pr.LocalPath, HeadSHA: pr.HeadSHA})
+			}
+			return m, nil
+		}
+
 		// Handle back navigation before passing to JSON explorer
 		if msg, ok := msg.(tea.KeyMsg); ok {
 			key := msg.String()
-			if m.allowBack && key == "q" {
+			if m.allowBack && key == "q" && !m.jsonExplorer.capturingInput() {
 				// Go back to PR selector instead of quitting
 				m.state = StateSelectingPR
 				m.jsonExplorer = JSONExplorerModel{} // Reset explorer
@@ -536,6 +752,46 @@ func RunUnifiedFlow(prs []*PullRequestSummary, jsonData []byte) (*PullRequestSum
 	return nil, nil
 }
 
+// RunUnifiedFlowForPR explores jsonData for a single pull request. checkout
+// lets review comments be opened in an editor and actions enables replies and
+// reactions; either may be left zero.
+func RunUnifiedFlowForPR(jsonData []byte, checkout Checkout, actions CommentActions) error {
+	model, err := NewUnifiedFlowWithJSON(jsonData)
+	if err != nil {
+		return err
+	}
+	model.jsonExplorer.SetCheckout(checkout)
+	model.jsonExplorer.SetCommentActions(actions)
+
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+	if m, ok := finalModel.(UnifiedFlowModel); ok && m.err != nil {
+		return m.err
+	}
+	return nil
+}
+
+// RunUnifiedFlowWithWatch explores jsonData and refreshes it in place as the watch reports changes.
+func RunUnifiedFlowWithWatch(jsonData []byte, watch WatchConfig) error {
+	model, err := NewUnifiedFlowWithWatch(jsonData, watch)
+	if err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+	if m, ok := finalModel.(UnifiedFlowModel); ok && m.err != nil {
+		return m.err
+	}
+	return nil
+}
+
 // RunUnifiedFlowWithPrefetch executes the interactive flow with loading spinner
 // while prefetching PR comments in the background.
 func RunUnifiedFlowWithPrefetch(config PrefetchConfig) (*PullRequestSummary, error) {
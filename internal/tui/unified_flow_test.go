@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ghprcomments "github.com/Quisharoo/gh-pr-comments/internal"
+	"github.com/google/go-github/v61/github"
+)
+
+func TestAdaptiveLimiterFollowsLimit(t *testing.T) {
+	var limit atomic.Int32
+	limit.Store(2)
+	limiter := newAdaptiveLimiter(func() int { return int(limit.Load()) })
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.acquire(ctx); err != nil {
+			t.Fatalf("acquire %d failed: %v", i, err)
+		}
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if err := limiter.acquire(ctx); err == nil {
+			close(acquired)
+		}
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("third acquire should block while two slots are in use")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Shrinking the limit keeps the waiter blocked after one release.
+	limit.Store(1)
+	limiter.release()
+	select {
+	case <-acquired:
+		t.Fatal("acquire should stay blocked once the limit drops to 1")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	limiter.release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire should proceed once a slot frees up")
+	}
+}
+
+func TestAdaptiveLimiterHonoursCancellation(t *testing.T) {
+	limiter := newAdaptiveLimiter(func() int { return 1 })
+	if err := limiter.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- limiter.acquire(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected cancelled acquire to fail")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled acquire did not return")
+	}
+}
+
+func TestWatchBacksOffAfterFailedPolls(t *testing.T) {
+	m, err := NewUnifiedFlowWithWatch([]byte(`{"a":1}`), WatchConfig{Ctx: context.Background(), Interval: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for want := 1; want <= 2; want++ {
+		updated, _ := m.Update(watchResultMsg{err: errors.New("boom")})
+		m = updated.(UnifiedFlowModel)
+		if m.watchFailures != want {
+			t.Fatalf("watchFailures = %d, want %d", m.watchFailures, want)
+		}
+	}
+	if !strings.Contains(m.jsonExplorer.statusMsg, "retrying in 2s") {
+		t.Errorf("expected the status to show the backoff, got %q", m.jsonExplorer.statusMsg)
+	}
+
+	updated, _ := m.Update(watchResultMsg{})
+	if m = updated.(UnifiedFlowModel); m.watchFailures != 0 {
+		t.Fatalf("expected a successful poll to reset the failures, got %d", m.watchFailures)
+	}
+}
+
+func TestSelectedPRGetsItsHeadCommit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/octo/widgets/pulls/7" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"number": 7, "head": {"ref": "feature", "sha": "abc123"}, "base": {"ref": "main"}}`))
+	}))
+	defer server.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	pr := &PullRequestSummary{Number: 7, RepoOwner: "octo", RepoName: "widgets", LocalPath: "/src/widgets", CommentsJSON: []byte(`{}`)}
+	m := NewUnifiedFlowModel([]*PullRequestSummary{pr})
+	m.fetcher = ghprcomments.NewFetcher(client)
+	m.selectedPR = pr
+	m.state = StateExploringJSON
+
+	msg := m.fillBranchesCmd(pr)()
+	updated, _ := m.Update(msg)
+	m = updated.(UnifiedFlowModel)
+
+	if pr.HeadSHA != "abc123" || pr.HeadRef != "feature" || pr.BaseRef != "main" {
+		t.Fatalf("branches not filled: %+v", pr)
+	}
+	if m.jsonExplorer.checkout != (Checkout{Dir: "/src/widgets", HeadSHA: "abc123"}) {
+		t.Fatalf("unexpected checkout %+v", m.jsonExplorer.checkout)
+	}
+	if m.fillBranchesCmd(pr) != nil {
+		t.Fatal("expected no second lookup once the head is known")
+	}
+}
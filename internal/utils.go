@@ -15,6 +15,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -24,10 +25,18 @@ import (
 )
 
 var (
-	botRegex     = regexp.MustCompile(`(?i)(copilot|compliance|security|dependabot|.*\[bot\])`)
+	botRegex     = regexp.MustCompile(`(?i)(\[bot\]$|^(copilot|dependabot|renovate|github-actions)$)`)
 	htmlStripper = bluemonday.StrictPolicy() // Strips all HTML tags
+
+	// botOverrides holds the configured extra and excluded bot patterns.
+	botOverrides atomic.Pointer[botPatterns]
 )
 
+type botPatterns struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
 // Lipgloss styles for PR summary display
 var (
 	prDimStyle        = lipgloss.NewStyle().Faint(true)
@@ -85,6 +94,12 @@ func DetectRepository(ctx context.Context) (string, string, error) {
 
 // DetectRepositories returns all repositories discoverable from the current directory.
 func DetectRepositories(ctx context.Context) ([]Repository, error) {
+	return DetectRepositoriesWithDepth(ctx, DefaultDiscoveryDepth)
+}
+
+// DetectRepositoriesWithDepth is DetectRepositories with a custom limit on how
+// many directory levels are searched for nested repositories.
+func DetectRepositoriesWithDepth(ctx context.Context, maxDepth int) ([]Repository, error) {
 	if repo := os.Getenv("GH_REPO"); repo != "" {
 		owner, name, err := splitRepo(repo)
 		if err != nil {
@@ -109,7 +124,7 @@ func DetectRepositories(ctx context.Context) ([]Repository, error) {
 		return []Repository{{Owner: owner, Name: repo, Path: root}}, nil
 	}
 
-	repos, err := discoverNestedRepositories(ctx, ".")
+	repos, err := discoverNestedRepositories(ctx, ".", maxDepth)
 	if err != nil {
 		return nil, err
 	}
@@ -160,13 +175,12 @@ func detectRepoViaGitAt(ctx context.Context, path string) (string, string, error
 	return splitRepo(repo)
 }
 
-func discoverNestedRepositories(ctx context.Context, root string) ([]Repository, error) {
+func discoverNestedRepositories(ctx context.Context, root string, maxDepth int) ([]Repository, error) {
 	rootAbs, err := filepath.Abs(root)
 	if err != nil {
 		return nil, err
 	}
 
-	const maxDepth = 2
 	skipNames := map[string]struct{}{
 		".git":         {},
 		".hg":          {},
@@ -485,17 +499,67 @@ func StripHTML(body string) string {
 	return htmlStripper.Sanitize(body)
 }
 
-// IsBotAuthor returns true if the author matches the bot regex.
+// IsBotAuthor reports whether GitHub marks the user as a bot or their login
+// matches the built-in or configured patterns. Configured exclusions win.
 func IsBotAuthor(user *github.User) bool {
 	if user == nil {
 		return false
 	}
 	login := strings.ToLower(strings.TrimSpace(user.GetLogin()))
+	overrides := botOverrides.Load()
+	if overrides != nil && matchesAny(overrides.exclude, login) {
+		return false
+	}
+	if strings.EqualFold(user.GetType(), "Bot") {
+		return true
+	}
 	if login != "" && botRegex.MatchString(login) {
 		return true
 	}
-	name := strings.ToLower(strings.TrimSpace(user.GetName()))
-	return name != "" && botRegex.MatchString(name)
+	return overrides != nil && matchesAny(overrides.include, login)
+}
+
+// SetBotPatterns extends the built-in bot detection with extra regexes and
+// exempts authors matching any exclude regex. Patterns match case-insensitively.
+func SetBotPatterns(include, exclude []string) error {
+	inc, err := compileBotPatterns(include)
+	if err != nil {
+		return err
+	}
+	exc, err := compileBotPatterns(exclude)
+	if err != nil {
+		return err
+	}
+	if len(inc) == 0 && len(exc) == 0 {
+		botOverrides.Store(nil)
+		return nil
+	}
+	botOverrides.Store(&botPatterns{include: inc, exclude: exc})
+	return nil
+}
+
+func compileBotPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bot pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
 }
 
 // HasCommand reports whether a CLI is available on PATH.
@@ -509,6 +573,18 @@ func FindRepoRoot(ctx context.Context) (string, error) {
 	return findRepoRootAt(ctx, ".")
 }
 
+// CheckoutHead returns the commit checked out in the repository at root.
+func CheckoutHead(ctx context.Context, root string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", root, "rev-parse", "HEAD")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.Discard
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("read HEAD of %s: %w", root, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
 func findRepoRootAt(ctx context.Context, path string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", "-C", path, "rev-parse", "--show-toplevel")
 	var stdout bytes.Buffer
@@ -611,12 +687,8 @@ func repoSaveDirectory(repoRoot, baseDir, owner, repo string) string {
 	return baseDir
 }
 
-// SaveOutput persists the rendered payload to the configured save directory as Markdown.
-func SaveOutput(repoRoot string, pr *PullRequestSummary, payload []byte, saveDir string) (string, error) {
-	if pr == nil || pr.Number <= 0 {
-		return "", errors.New("save requires a pull request with a number")
-	}
-
+// prepareSaveTarget creates the save directory for pr and returns the snapshot path within it.
+func prepareSaveTarget(repoRoot string, pr *PullRequestSummary, saveDir string) (string, error) {
 	baseDir := resolveSaveDir(repoRoot, saveDir)
 	targetDir := repoSaveDirectory(repoRoot, baseDir, pr.RepoOwner, pr.RepoName)
 	if err := os.MkdirAll(targetDir, 0o755); err != nil {
@@ -624,16 +696,10 @@ func SaveOutput(repoRoot string, pr *PullRequestSummary, payload []byte, saveDir
 	}
 
 	filename := fmt.Sprintf("pr-%d-%s.md", pr.Number, slugify(pr.Title, pr.HeadRef))
-	target := filepath.Join(targetDir, filename)
-
-	content := buildFeedbackMarkdown(pr, payload)
-	if err := os.WriteFile(target, content, 0o644); err != nil {
-		return "", err
-	}
-	return target, nil
+	return filepath.Join(targetDir, filename), nil
 }
 
-func buildFeedbackMarkdown(pr *PullRequestSummary, payload []byte) []byte {
+func buildFeedbackMarkdown(pr *PullRequestSummary, payload []byte, sync *SyncSummary) []byte {
 	var builder strings.Builder
 	builder.Grow(len(payload) + 512)
 
@@ -662,7 +728,18 @@ func buildFeedbackMarkdown(pr *PullRequestSummary, payload []byte) []byte {
 	builder.WriteByte('\n')
 	builder.WriteString("saved_at: ")
 	builder.WriteString(quoteYAMLString(time.Now().UTC().Format(time.RFC3339)))
-	builder.WriteString("\n---\n\n```json\n")
+	builder.WriteByte('\n')
+	if sync != nil {
+		if !sync.PreviousSavedAt.IsZero() {
+			builder.WriteString("previous_saved_at: ")
+			builder.WriteString(quoteYAMLString(sync.PreviousSavedAt.UTC().Format(time.RFC3339)))
+			builder.WriteByte('\n')
+		}
+		builder.WriteString(fmt.Sprintf("new_since_last_save: %d\n", sync.New))
+		builder.WriteString(fmt.Sprintf("edited_since_last_save: %d\n", sync.Edited))
+		builder.WriteString(fmt.Sprintf("deleted_since_last_save: %d\n", sync.Deleted))
+	}
+	builder.WriteString("---\n\n```json\n")
 	builder.Write(payload)
 	if len(payload) == 0 || payload[len(payload)-1] != '\n' {
 		builder.WriteByte('\n')
@@ -797,6 +874,7 @@ func PruneStaleSavedComments(ctx context.Context, getter PullRequestSummaryGette
 					errs = append(errs, fmt.Errorf("remove %s: %w", filePath, remErr))
 				} else if remErr == nil {
 					removed = append(removed, filePath)
+					os.Remove(syncStatePath(dir, num))
 				}
 				continue
 			}
@@ -812,6 +890,7 @@ func PruneStaleSavedComments(ctx context.Context, getter PullRequestSummaryGette
 			errs = append(errs, fmt.Errorf("remove %s: %w", filePath, remErr))
 		} else if remErr == nil {
 			removed = append(removed, filePath)
+			os.Remove(syncStatePath(dir, num))
 		}
 	}
 
@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -60,19 +59,25 @@ func BenchmarkStripHTML(b *testing.B) {
 
 func TestIsBotAuthor(t *testing.T) {
 	tests := []struct {
-		name  string
-		login string
-		want  bool
+		name     string
+		login    string
+		userType string
+		want     bool
 	}{
-		{"regular_user", "human", false},
-		{"dependabot", "dependabot", true},
-		{"suffix_bot", "build[bot]", true},
-		{"copilot_case", "CoPiLoT", true},
+		{"regular_user", "human", "User", false},
+		{"dependabot", "dependabot", "", true},
+		{"suffix_bot", "build[bot]", "", true},
+		{"copilot_case", "CoPiLoT", "", true},
+		{"human_security", "security-lead", "User", false},
+		{"human_compliance", "jane-compliance", "User", false},
+		{"human_prefix", "copilot-fan", "User", false},
+		{"human_renovate", "Renovate-Lab", "User", false},
+		{"github_app_type", "acme-ci", "Bot", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user := &github.User{Login: github.String(tt.login)}
+			user := &github.User{Login: github.String(tt.login), Type: github.String(tt.userType)}
 			if got := IsBotAuthor(user); got != tt.want {
 				t.Fatalf("IsBotAuthor(%q) = %v, want %v", tt.login, got, tt.want)
 			}
@@ -266,162 +271,6 @@ func TestSelectWithPromptColourizedOutput(t *testing.T) {
 	}
 }
 
-func TestSaveOutputCreatesDirectoryAndFile(t *testing.T) {
-	repoRoot := t.TempDir()
-	pr := &PullRequestSummary{
-		Number:    123,
-		Title:     "Add Feature 🚀",
-		HeadRef:   "feature/add-feature",
-		BaseRef:   "main",
-		RepoOwner: "octo",
-		RepoName:  "repo",
-		Author:    "tester",
-		URL:       "https://example.com",
-	}
-	payload := []byte(`{"ok":true}`)
-
-	path, err := SaveOutput(repoRoot, pr, payload, "")
-	if err != nil {
-		t.Fatalf("SaveOutput returned error: %v", err)
-	}
-
-	dir := filepath.Join(repoRoot, ".pr-comments")
-	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
-		t.Fatalf("expected directory %s to exist", dir)
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatalf("failed to read saved payload: %v", err)
-	}
-	content := string(data)
-
-	if !strings.HasPrefix(content, "---\n") {
-		t.Fatalf("expected YAML front matter, got %q", content)
-	}
-	if !strings.Contains(content, "pr_number: 123") {
-		t.Fatalf("front matter missing pr_number: %q", content)
-	}
-	if !strings.Contains(content, `pr_title: "Add Feature 🚀"`) {
-		t.Fatalf("front matter missing pr_title: %q", content)
-	}
-	if !strings.Contains(content, `repo_owner: "octo"`) {
-		t.Fatalf("front matter missing repo_owner: %q", content)
-	}
-	if !strings.Contains(content, `repo_name: "repo"`) {
-		t.Fatalf("front matter missing repo_name: %q", content)
-	}
-	if !strings.Contains(content, `head_ref: "feature/add-feature"`) {
-		t.Fatalf("front matter missing head_ref: %q", content)
-	}
-	if !strings.Contains(content, `base_ref: "main"`) {
-		t.Fatalf("front matter missing base_ref: %q", content)
-	}
-	if !strings.Contains(content, `author: "tester"`) {
-		t.Fatalf("front matter missing author: %q", content)
-	}
-	if !strings.Contains(content, `url: "https://example.com"`) {
-		t.Fatalf("front matter missing url: %q", content)
-	}
-
-	savedAtRe := regexp.MustCompile(`saved_at: "[^"]+"`)
-	if !savedAtRe.MatchString(content) {
-		t.Fatalf("front matter missing saved_at timestamp: %q", content)
-	}
-
-	jsonBlock := "```json\n" + string(payload) + "\n```"
-	if !strings.Contains(content, jsonBlock) {
-		t.Fatalf("expected JSON block to contain payload; want %q in %q", jsonBlock, content)
-	}
-
-	base := filepath.Base(path)
-	if base != "pr-123-add-feature.md" {
-		t.Fatalf("unexpected filename %q, expected pr-123-add-feature.md", base)
-	}
-}
-
-func TestSaveOutputOverwritesExistingFile(t *testing.T) {
-	repoRoot := t.TempDir()
-	pr := &PullRequestSummary{Number: 5, Title: "Improve API", HeadRef: "feature"}
-	payload1 := []byte("first payload")
-	payload2 := []byte("second payload")
-
-	first, err := SaveOutput(repoRoot, pr, payload1, "")
-	if err != nil {
-		t.Fatalf("first SaveOutput returned error: %v", err)
-	}
-	if filepath.Base(first) != "pr-5-improve-api.md" {
-		t.Fatalf("unexpected filename %q", first)
-	}
-
-	second, err := SaveOutput(repoRoot, pr, payload2, "")
-	if err != nil {
-		t.Fatalf("second SaveOutput returned error: %v", err)
-	}
-
-	if first != second {
-		t.Fatalf("expected same filename, got %q and %q", first, second)
-	}
-
-	data, err := os.ReadFile(second)
-	if err != nil {
-		t.Fatalf("failed to read file: %v", err)
-	}
-	content := string(data)
-	if strings.Contains(content, string(payload1)) {
-		t.Fatalf("expected old payload to be overwritten, found in content: %q", content)
-	}
-	if !strings.Contains(content, string(payload2)) {
-		t.Fatalf("file missing new payload: %q", content)
-	}
-}
-
-func TestSaveOutputRespectsCustomDirectory(t *testing.T) {
-	repoRoot := t.TempDir()
-	customDir := "codex-artifacts"
-	pr := &PullRequestSummary{Number: 42, Title: "Custom Save", HeadRef: "feature"}
-	payload := []byte("{}")
-
-	path, err := SaveOutput(repoRoot, pr, payload, customDir)
-	if err != nil {
-		t.Fatalf("SaveOutput returned error: %v", err)
-	}
-
-	expectedDir := filepath.Join(repoRoot, customDir)
-	if dirInfo, err := os.Stat(expectedDir); err != nil || !dirInfo.IsDir() {
-		t.Fatalf("expected custom directory %s to exist", expectedDir)
-	}
-	if !strings.HasPrefix(path, expectedDir+string(os.PathSeparator)) {
-		t.Fatalf("expected path %q to reside within %s", path, expectedDir)
-	}
-}
-
-func TestSaveOutputSupportsAbsoluteDirectory(t *testing.T) {
-	repoRoot := t.TempDir()
-	absoluteDir := filepath.Join(t.TempDir(), "gh-pr-comments-artifacts")
-	pr := &PullRequestSummary{
-		Number:    7,
-		Title:     "Absolute",
-		HeadRef:   "feature",
-		RepoOwner: "octo",
-		RepoName:  "repo",
-	}
-	payload := []byte("{}")
-
-	path, err := SaveOutput(repoRoot, pr, payload, absoluteDir)
-	if err != nil {
-		t.Fatalf("SaveOutput returned error: %v", err)
-	}
-
-	expectedDir := filepath.Join(absoluteDir, "octo", "repo")
-	if info, err := os.Stat(expectedDir); err != nil || !info.IsDir() {
-		t.Fatalf("expected directory %s to exist", expectedDir)
-	}
-	if !strings.HasPrefix(path, expectedDir+string(os.PathSeparator)) {
-		t.Fatalf("expected path %q to reside within %s", path, expectedDir)
-	}
-}
-
 func TestRepoSaveDirectoryAvoidsNamespaceCollisions(t *testing.T) {
 	repoRoot := t.TempDir()
 	sharedDir := t.TempDir()
@@ -445,19 +294,6 @@ func TestRepoSaveDirectoryAvoidsNamespaceCollisions(t *testing.T) {
 	})
 }
 
-func TestSaveOutputRequiresPullRequestNumber(t *testing.T) {
-	repoRoot := t.TempDir()
-	payload := []byte("{}")
-
-	if _, err := SaveOutput(repoRoot, nil, payload, ""); err == nil {
-		t.Fatal("expected error when PR is nil")
-	}
-
-	if _, err := SaveOutput(repoRoot, &PullRequestSummary{Number: 0}, payload, ""); err == nil {
-		t.Fatal("expected error when PR number is zero")
-	}
-}
-
 func TestPruneStaleSavedCommentsRemovesClosedFiles(t *testing.T) {
 	repoRoot := t.TempDir()
 	dir := filepath.Join(repoRoot, ".pr-comments")
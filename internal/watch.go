@@ -0,0 +1,178 @@
+package ghprcomments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+// watchMaxBackoff caps the wait between polls after repeated failures.
+const watchMaxBackoff = 5 * time.Minute
+
+// WatchEvent reports a comment that appeared or changed since the previous poll.
+type WatchEvent struct {
+	Event   string  `json:"event"` // "new" or "edited"
+	ID      int64   `json:"id"`
+	Comment Comment `json:"comment"`
+}
+
+// Watcher polls a pull request and diffs comments by ID between polls.
+type Watcher struct {
+	fetcher *Fetcher
+	pr      *PullRequestSummary
+	opts    NormalizationOptions
+	seen    map[string]string
+	primed  bool
+	// Notify, when set, is told about each failed poll and how long Run
+	// waits before the next one.
+	Notify func(err error, wait time.Duration)
+}
+
+// NewWatcher prepares a watcher for pr. The first Poll establishes the
+// baseline and reports no events.
+func NewWatcher(fetcher *Fetcher, pr *PullRequestSummary, opts NormalizationOptions) *Watcher {
+	return &Watcher{fetcher: fetcher, pr: pr, opts: opts, seen: make(map[string]string)}
+}
+
+// Poll fetches the current comments and returns events for anything new or edited.
+func (w *Watcher) Poll(ctx context.Context) ([]WatchEvent, Output, error) {
+	payload, err := w.fetcher.FetchComments(ctx, w.pr.RepoOwner, w.pr.RepoName, w.pr.Number)
+	if err != nil {
+		return nil, Output{}, err
+	}
+	out := BuildOutput(w.pr, payload, w.opts)
+	return w.diff(out), out, nil
+}
+
+func (w *Watcher) diff(out Output) []WatchEvent {
+	comments := outputComments(out)
+
+	var events []WatchEvent
+	for _, c := range comments {
+		key := commentKey(c)
+		fingerprint := commentFingerprint(c)
+		previous, known := w.seen[key]
+		w.seen[key] = fingerprint
+		if !w.primed {
+			continue
+		}
+		switch {
+		case !known:
+			events = append(events, WatchEvent{Event: "new", ID: c.ID, Comment: c})
+		case previous != fingerprint:
+			events = append(events, WatchEvent{Event: "edited", ID: c.ID, Comment: c})
+		}
+	}
+	w.primed = true
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Comment.CreatedAt.Before(events[j].Comment.CreatedAt)
+	})
+	return events
+}
+
+// Run polls every interval until ctx is cancelled, handing each non-empty
+// batch of events to emit. Each poll is bounded by its own timeout. A failed
+// poll is reported to Notify and retried with exponential backoff; only
+// authentication failures and errors from emit end the watch.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, emit func([]WatchEvent, Output) error) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", interval)
+	}
+
+	failures := 0
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		events, out, err := w.Poll(pollCtx)
+		cancel()
+
+		wait := interval
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && isAuthError(err):
+			return err
+		case err != nil:
+			failures++
+			wait = WatchBackoff(interval, failures)
+			if w.Notify != nil {
+				w.Notify(err, wait)
+			}
+		default:
+			failures = 0
+			if len(events) > 0 {
+				if err := emit(events, out); err != nil {
+					return err
+				}
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// WatchBackoff doubles the interval for each consecutive failure, up to
+// watchMaxBackoff or the interval itself if that is longer.
+func WatchBackoff(interval time.Duration, failures int) time.Duration {
+	wait := interval
+	for i := 1; i < failures && wait < watchMaxBackoff; i++ {
+		wait *= 2
+	}
+	return max(min(wait, watchMaxBackoff), interval)
+}
+
+// isAuthError reports whether err is GitHub rejecting the credentials, which
+// polling again will not fix. Rate limits also answer 403 and are retried.
+func isAuthError(err error) bool {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateErr) || errors.As(err, &abuseErr) {
+		return false
+	}
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil {
+		return false
+	}
+	return ghErr.Response.StatusCode == http.StatusUnauthorized || ghErr.Response.StatusCode == http.StatusForbidden
+}
+
+// SummarizeWatchEvents renders a short "2 new, 1 edited" description.
+func SummarizeWatchEvents(events []WatchEvent) string {
+	newCount, edited := 0, 0
+	for _, e := range events {
+		if e.Event == "new" {
+			newCount++
+		} else {
+			edited++
+		}
+	}
+	return fmt.Sprintf("%d new, %d edited", newCount, edited)
+}
+
+func commentKey(c Comment) string {
+	if c.ID == 0 {
+		// Commit events carry no ID; the SHA identifies them.
+		return c.Type + "|" + c.CommitID + "|" + c.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c.Type + ":" + strconv.FormatInt(c.ID, 10)
+}
+
+func commentFingerprint(c Comment) string {
+	resolved := ""
+	if c.Resolved != nil {
+		resolved = strconv.FormatBool(*c.Resolved)
+	}
+	return c.BodyMarkdown + "\x00" + c.BodyText + "\x00" + c.State + "\x00" + resolved
+}
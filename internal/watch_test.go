@@ -0,0 +1,189 @@
+package ghprcomments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v61/github"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	var mu sync.Mutex
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	issueComments := []*github.IssueComment{
+		{ID: github.Int64(1), Body: github.String("first"), User: &github.User{Login: github.String("alice")}, CreatedAt: &github.Timestamp{Time: base}},
+	}
+	notModified := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var body []byte
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/1/comments":
+			body, _ = json.Marshal(issueComments)
+		case "/repos/owner/repo/pulls/1/comments", "/repos/owner/repo/pulls/1/reviews":
+			body = []byte(`[]`)
+		case "/graphql":
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}}}`))
+			return
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		etag := fmt.Sprintf(`"%x"`, body)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+
+	server, _ := mockGitHubServer(t, handler)
+	defer server.Close()
+	client := github.NewClient(&http.Client{Transport: NewConditionalTransport(nil)})
+	client.BaseURL.Scheme = "http"
+	client.BaseURL.Host = server.URL[7:]
+
+	pr := &PullRequestSummary{Number: 1, RepoOwner: "owner", RepoName: "repo"}
+	watcher := NewWatcher(NewFetcher(client), pr, NormalizationOptions{StripHTML: true})
+	ctx := context.Background()
+
+	events, out, err := watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("baseline poll failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected baseline poll to emit nothing, got %+v", events)
+	}
+	if out.CommentCount != 1 {
+		t.Fatalf("expected 1 comment in baseline, got %d", out.CommentCount)
+	}
+
+	events, _, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("unchanged poll failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events when nothing changed, got %+v", events)
+	}
+	if notModified == 0 {
+		t.Error("expected conditional requests to be answered with 304")
+	}
+
+	mu.Lock()
+	issueComments[0].Body = github.String("first (edited)")
+	issueComments = append(issueComments, &github.IssueComment{
+		ID: github.Int64(2), Body: github.String("second"), User: &github.User{Login: github.String("bob")}, CreatedAt: &github.Timestamp{Time: base.Add(time.Minute)},
+	})
+	mu.Unlock()
+
+	events, _, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("changed poll failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Event != "edited" || events[0].ID != 1 || events[0].Comment.BodyText != "first (edited)" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Event != "new" || events[1].ID != 2 {
+		t.Errorf("unexpected second event: %+v", events[1])
+	}
+	if got := SummarizeWatchEvents(events); got != "1 new, 1 edited" {
+		t.Errorf("unexpected summary %q", got)
+	}
+}
+
+func TestWatcherRunSurvivesFailedPolls(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/1/comments":
+			polls++
+			switch {
+			case polls == 2 || polls == 3:
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			case polls >= 4:
+				w.Write([]byte(`[{"id":1,"body":"first","user":{"login":"alice"}}]`))
+			default:
+				w.Write([]byte(`[]`))
+			}
+		case "/graphql":
+			w.Write([]byte(`{"data":{"repository":{"pullRequest":{"reviewThreads":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}}}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	watcher := NewWatcher(NewFetcher(client), &PullRequestSummary{Number: 1, RepoOwner: "owner", RepoName: "repo"}, NormalizationOptions{})
+	var waits []time.Duration
+	watcher.Notify = func(err error, wait time.Duration) {
+		waits = append(waits, wait)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []WatchEvent
+	err := watcher.Run(ctx, time.Millisecond, func(events []WatchEvent, _ Output) error {
+		got = events
+		cancel()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected failed polls to be retried, got %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("expected the comment posted during the outage, got %+v", got)
+	}
+	if len(waits) != 2 || waits[1] != 2*waits[0] {
+		t.Fatalf("expected two backoffs doubling the wait, got %v", waits)
+	}
+}
+
+func TestWatcherRunStopsOnAuthErrors(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}
+	server, client := mockGitHubServer(t, handler)
+	defer server.Close()
+
+	watcher := NewWatcher(NewFetcher(client), &PullRequestSummary{Number: 1, RepoOwner: "owner", RepoName: "repo"}, NormalizationOptions{})
+	err := watcher.Run(context.Background(), time.Millisecond, func([]WatchEvent, Output) error { return nil })
+	if err == nil {
+		t.Fatal("expected bad credentials to end the watch")
+	}
+}
+
+func TestWatchBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		interval time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{30 * time.Second, 1, 30 * time.Second},
+		{30 * time.Second, 3, 2 * time.Minute},
+		{30 * time.Second, 10, watchMaxBackoff},
+		{10 * time.Minute, 4, 10 * time.Minute},
+	} {
+		if got := WatchBackoff(tc.interval, tc.failures); got != tc.want {
+			t.Errorf("WatchBackoff(%s, %d) = %s, want %s", tc.interval, tc.failures, got, tc.want)
+		}
+	}
+}